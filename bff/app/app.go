@@ -0,0 +1,131 @@
+// Package app 组装 BFF 的路由，供 cmd 及端到端测试使用
+package app
+
+import (
+	"net/http"
+
+	"bluebell_microservices/bff/internal/dao/redis"
+	"bluebell_microservices/bff/internal/grpc_client"
+	"bluebell_microservices/bff/internal/handler"
+	"bluebell_microservices/bff/internal/middleware"
+	"bluebell_microservices/bff/internal/response"
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/rbac"
+	"bluebell_microservices/common/pkg/registry"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// NewRouter 注册中间件及全部路由
+func NewRouter(clients *grpc_client.Clients) *gin.Engine {
+	r := gin.Default()
+	// 只采信可信代理转发的 X-Forwarded-For，否则客户端每次伪造不同的来源 IP 即可绕过按 IP 的限流
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		logger.Error("Invalid trusted proxies, using the connection address", zap.Error(err))
+		r.SetTrustedProxies(nil)
+	}
+	r.Use(otelgin.Middleware("bff", otelgin.WithFilter(func(req *http.Request) bool {
+		return req.URL.Path != "/healthz" && req.URL.Path != "/readyz" // 探针请求不产生 span
+	}))) // 链路追踪，需在日志中间件之前
+	r.Use(middleware.LoggerMiddleware())  // 使用日志中间件
+	r.Use(middleware.MetricsMiddleware()) // 请求数、错误数及耗时指标
+
+	// 健康检查，不参与限流
+	r.GET("/healthz", handler.HealthzHandler())
+	r.GET("/readyz", handler.ReadyzHandler(clients.Health, clients.Optional))
+
+	r.Use(middleware.RateLimitMiddleware("global")) // 单 IP 全局限流
+
+	// 设置路由
+	v1 := r.Group("/api/v1")
+	//
+	v1.POST("/signup", middleware.RateLimitMiddleware("signup"), handler.SignUpHandler(clients.User))
+	v1.POST("/login", middleware.RateLimitMiddleware("login"), handler.LoginHandler(clients.User))
+	v1.GET("/refresh_token", handler.RefreshTokenHandler(clients.User))
+
+	// 公开的读接口：ETag + 短时间缓存，内容未变时返回 304
+	publicCache := middleware.CacheControl("public, max-age=5")
+	v1.GET("/posts2", publicCache, handler.GetPostListHandler(clients.Post))
+	v1.GET("/post/:id", publicCache, handler.PostDetailHandler(clients.Post, clients.Comment)) // 查询帖子详情
+	v1.GET("/post/:id/stream", handler.PostStreamHandler(clients.Post))                        // 订阅帖子实时事件（SSE），流式响应不能缓冲
+	v1.GET("/search", publicCache, handler.PostSearchHandler(clients.Post))                    // 搜索业务-搜索帖子
+
+	// 中间件
+	v1.Use(middleware.JWTAuthMiddleware(clients.User)) // 应用JWT认证中间件
+	{
+		v1.POST("/post", middleware.RequirePermission(rbac.PermPostCreate), middleware.RateLimitMiddleware("post"), handler.CreatePostHandler(clients.Post)) // 创建帖子
+		v1.PUT("/post/:id/draft", middleware.RequirePermission(rbac.PermPostCreate), handler.UpdateDraftHandler(clients.Post))                               // 修改草稿
+		v1.POST("/post/:id/publish", middleware.RequirePermission(rbac.PermPostCreate), handler.PublishDraftHandler(clients.Post))                           // 发布草稿
+		v1.POST("/vote", middleware.RequirePermission(rbac.PermPostVote), middleware.RateLimitMiddleware("vote"), handler.VoteHandler(clients.Post))         // 投票
+
+		v1.POST("/comment", middleware.RequirePermission(rbac.PermCommentCreate), middleware.RateLimitMiddleware("comment"), handler.CommentHandler(clients.Comment, clients.Post)) // 评论
+		v1.GET("/comment", middleware.CacheControl("private, no-cache"), handler.CommentListHandler(clients.Comment, clients.User))                                                 // 评论列表
+
+		report := v1.Group("/report", middleware.RequirePermission(rbac.PermReportCreate))
+		report.POST("/post", handler.ReportPostHandler(clients.Post))                        // 举报帖子
+		report.POST("/comment", handler.ReportCommentHandler(clients.Post, clients.Comment)) // 举报评论
+
+		// 版主路由，需要是对应社区的版主
+		moderation := v1.Group("/community/:id", middleware.CommunityModeratorMiddleware(clients.Post))
+		moderation.GET("/reports", handler.ReportListHandler(clients.Post))                  // 举报队列
+		moderation.POST("/moderate", handler.ModerateHandler(clients.Post, clients.Comment)) // 版主操作
+
+		// 管理员路由
+		admin := v1.Group("/admin", middleware.RequireRole(rbac.RoleAdmin))
+		admin.PUT("/user/role", handler.SetUserRoleHandler(clients.User)) // 修改用户角色
+
+		v1.GET("/ping", func(c *gin.Context) {
+			userID, exists := c.Get(middleware.ContextUserIDKey)
+			if !exists {
+				response.Unauthorized(c, "未登录")
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"code": 200,
+				"msg":  "pong",
+				"data": gin.H{
+					"user_id": userID,
+				},
+			})
+		})
+	}
+
+	return r
+}
+
+// trustedProxies 返回配置的可信代理，未配置时为 nil
+func trustedProxies() []string {
+	if config.Conf == nil || config.Conf.Server == nil {
+		return nil
+	}
+	return config.Conf.Server.TrustedProxies
+}
+
+// InProcess 进程内运行的 BFF，通过内存注册表发现下游服务，用于端到端测试
+type InProcess struct {
+	Handler http.Handler
+	clients *grpc_client.Clients
+}
+
+// NewInProcess 使用 config.Conf 中的 Redis 配置创建 BFF，opts 追加到下游连接上（如 bufconn 的 dialer）
+func NewInProcess(reg *registry.Memory, opts ...grpc.DialOption) (*InProcess, error) {
+	if err := redis.Init(config.Conf.Redis); err != nil {
+		return nil, err
+	}
+	clients, err := grpc_client.NewClientsWith(registry.NewMemoryResolverBuilder(reg), opts...)
+	if err != nil {
+		redis.Close()
+		return nil, err
+	}
+	return &InProcess{Handler: NewRouter(clients), clients: clients}, nil
+}
+
+// Close 关闭下游连接及 Redis
+func (b *InProcess) Close() {
+	b.clients.Close()
+	redis.Close()
+}
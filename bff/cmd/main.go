@@ -1,82 +1,93 @@
 package main
 
 import (
+	"bluebell_microservices/bff/app"
+	"bluebell_microservices/bff/internal/dao/redis"
 	"bluebell_microservices/bff/internal/grpc_client"
-	"bluebell_microservices/bff/internal/handler"
-	"bluebell_microservices/bff/internal/middleware"
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/jwt"
 	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/metrics"
+	"bluebell_microservices/common/pkg/tracing"
+	"context"
 	"flag"
+	"fmt"
 	"log"
-	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// shutdownTracing 退出时刷新尚未导出的 span
+var shutdownTracing = func(context.Context) error { return nil }
+
 func SetupRouter() *gin.Engine {
 	flag.Parse()
 
+	// 初始化配置
+	if err := config.InitConfig("bff"); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
 	// 初始化日志
-	if err := logger.Init("info", "bff.log"); err != nil {
+	if err := logger.Init("bff", config.Conf.Log); err != nil {
 		log.Fatalf("Failed to init logger: %v", err)
 	}
 	defer logger.Logger.Sync()
+	jwt.Init(config.Conf.Server.JwtSecret)
 
-	// 初始化 gRPC 客户端
-	clients, err := grpc_client.NewClients()
-	if err != nil {
-		logger.Error("Failed to connect to micro-service", zap.Error(err))
-		log.Fatalf("Failed to initialize gRPC clients: %v", err)
+	// 配置文件变更时热更新日志级别和限流策略
+	if _, err := config.Watch(func(conf *config.Config, restart []string) {
+		logger.SetLevel(conf.Log.Level)
+		logger.Info("Config reloaded", zap.String("log_level", conf.Log.Level))
+		if len(restart) > 0 {
+			logger.Warn("Config changes require restart", zap.Strings("sections", restart))
+		}
+	}, func(err error) {
+		logger.Error("Failed to reload config, keeping current config", zap.Error(err))
+	}); err != nil {
+		log.Fatalf("Failed to watch config: %v", err)
 	}
 
-	// 设置 Gin
-	r := gin.Default()
-	r.Use(middleware.LoggerMiddleware()) // 使用日志中间件
-
-	// 设置路由
-	v1 := r.Group("/api/v1")
-	//
-	v1.POST("/signup", handler.SignUpHandler(clients.User))
-	v1.POST("/login", handler.LoginHandler(clients.User))
-	v1.GET("/refresh_token", handler.RefreshTokenHandler(clients.User))
-
-	v1.GET("/posts2", handler.GetPostListHandler(clients.Post))
-	v1.GET("/post/:id", handler.PostDetailHandler(clients.Post)) // 查询帖子详情
-	v1.GET("/search", handler.PostSearchHandler(clients.Post))   // 搜索业务-搜索帖子
+	// 初始化链路追踪
+	shutdown, err := tracing.Init("bff", config.Conf.Tracing)
+	if err != nil {
+		log.Fatalf("init tracing failed, err:%v\n", err)
+	}
+	shutdownTracing = shutdown
 
-	// 中间件
-	v1.Use(middleware.JWTAuthMiddleware()) // 应用JWT认证中间件
-	{
-		v1.POST("/post", handler.CreatePostHandler(clients.Post)) // 创建帖子
-		v1.POST("/vote", handler.VoteHandler(clients.Post))       // 投票
+	// 初始化 Redis（限流）
+	if err := redis.Init(config.Conf.Redis); err != nil {
+		log.Fatalf("init redis failed, err:%v\n", err)
+	}
+	if err := metrics.RegisterRedisPoolStats("bff", redis.Client()); err != nil {
+		logger.Error("Failed to register redis pool metrics", zap.Error(err))
+	}
 
-		v1.POST("/comment", handler.CommentHandler(clients.Comment))    // 评论
-		v1.GET("/comment", handler.CommentListHandler(clients.Comment)) // 评论列表
+	// 指标监听与业务端口分开
+	if conf := config.Conf.Metrics; conf != nil && conf.BFFPort > 0 {
+		metrics.Serve(fmt.Sprintf(":%d", conf.BFFPort))
+	}
 
-		v1.GET("/ping", func(c *gin.Context) {
-			userID, exists := c.Get(middleware.ContextUserIDKey)
-			if !exists {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"code": 401,
-					"msg":  "未登录",
-				})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{
-				"code": 200,
-				"msg":  "pong",
-				"data": gin.H{
-					"user_id": userID,
-				},
-			})
-		})
+	// 初始化 gRPC 客户端
+	clients, err := grpc_client.NewClients()
+	if err != nil {
+		logger.Error("Failed to connect to micro-service", zap.Error(err))
+		log.Fatalf("Failed to initialize gRPC clients: %v", err)
 	}
 
-	return r
+	return app.NewRouter(clients)
 }
 
 func main() {
 	r := SetupRouter()
+	defer redis.Close()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownTracing(ctx)
+	}()
 	if err := r.Run(":8080"); err != nil {
 		logger.Error("Failed to run BFF", zap.Error(err))
 		log.Fatalf("Failed to run server: %v", err)
@@ -0,0 +1,8 @@
+package redis
+
+// redis key 注意使用命名空间的方式，方便查询和拆分
+const (
+	KeyRateLimitPrefix    = "bluebell-plus:ratelimit:"     // zset;滑动窗口内的请求时间戳;参数是策略名:维度:标识
+	KeyLoginFailurePrefix = "bluebell-plus:login:failure:" // string;窗口内登录失败次数;参数是用户名
+	KeyLoginLockPrefix    = "bluebell-plus:login:lock:"    // string;登录锁定标记;参数是用户名
+)
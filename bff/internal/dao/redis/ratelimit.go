@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// slidingWindowScript 滑动窗口限流，返回 {是否放行, 剩余次数, 需等待的毫秒数}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, 0}
+end
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local retry = window
+if oldest[2] then
+	retry = tonumber(oldest[2]) + window - now
+end
+return {0, 0, retry}
+`)
+
+// loginFailureScript 失败次数加一，第一次失败时设置过期时间，窗口从第一次失败开始计算；返回失败次数
+var loginFailureScript = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+if failures == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return failures
+`)
+
+// RateLimitResult 限流判定结果
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int64
+	RetryAfter time.Duration
+}
+
+// AllowRequest 基于滑动窗口判断 key 在 window 内是否还能再请求一次
+func AllowRequest(key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	now := time.Now()
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), rand.Int63())
+	res, err := slidingWindowScript.Run(client, []string{KeyRateLimitPrefix + key},
+		now.UnixMilli(), window.Milliseconds(), limit, member).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 3 {
+		return nil, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+	allowed, _ := vals[0].(int64)
+	remaining, _ := vals[1].(int64)
+	retryMs, _ := vals[2].(int64)
+	return &RateLimitResult{
+		Allowed:    allowed == 1,
+		Remaining:  remaining,
+		RetryAfter: time.Duration(retryMs) * time.Millisecond,
+	}, nil
+}
+
+// LoginLockTTL 返回用户名剩余的锁定时长，未锁定时返回 0
+func LoginLockTTL(username string) (time.Duration, error) {
+	ttl, err := client.PTTL(KeyLoginLockPrefix + normalizeUsername(username)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// RecordLoginFailure 记录一次登录失败，窗口内失败次数达到上限时锁定该用户名并返回锁定时长
+func RecordLoginFailure(username string, maxFailures int, window, lockDuration time.Duration) (time.Duration, error) {
+	name := normalizeUsername(username)
+	failureKey := KeyLoginFailurePrefix + name
+
+	// 计数与设置过期时间在同一脚本中完成，避免计数永不过期
+	failures, err := loginFailureScript.Run(client, []string{failureKey}, window.Milliseconds()).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if failures < int64(maxFailures) {
+		return 0, nil
+	}
+
+	pipeline := client.TxPipeline()
+	pipeline.Set(KeyLoginLockPrefix+name, "1", lockDuration)
+	pipeline.Del(failureKey)
+	if _, err := pipeline.Exec(); err != nil {
+		return 0, err
+	}
+	return lockDuration, nil
+}
+
+// ClearLoginFailures 登录成功后清除失败计数
+func ClearLoginFailures(username string) error {
+	return client.Del(KeyLoginFailurePrefix + normalizeUsername(username)).Err()
+}
+
+// normalizeUsername 用户名大小写不敏感，避免通过变换大小写绕过锁定
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,87 @@
+package grpc_client
+
+import (
+	"encoding/json"
+	"sync"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/registry"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/balancer/roundrobin"
+	_ "google.golang.org/grpc/health" // 启用客户端健康检查
+)
+
+// 负载均衡策略名称
+const (
+	BalancerRoundRobin = roundrobin.Name
+	BalancerWeighted   = "bluebell_weighted_round_robin"
+)
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(BalancerWeighted, &weightedPickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+// serviceConfig 返回指定负载均衡策略的 gRPC service config，开启客户端健康检查（NOT_SERVING 的连接不参与负载均衡）
+// 并为 service 的幂等接口配置重试
+func serviceConfig(policy, service string, retry *config.Retry) string {
+	if policy != BalancerWeighted {
+		policy = BalancerRoundRobin
+	}
+	sc := struct {
+		LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+		HealthCheckConfig   map[string]string     `json:"healthCheckConfig"`
+		MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+	}{
+		LoadBalancingConfig: []map[string]struct{}{{policy: {}}},
+		HealthCheckConfig:   map[string]string{"serviceName": ""},
+		MethodConfig:        retryMethodConfig(service, retry),
+	}
+	data, _ := json.Marshal(sc) // 结构固定，不会序列化失败
+	return string(data)
+}
+
+// weightedPickerBuilder 基于实例静态权重的平滑加权轮询
+type weightedPickerBuilder struct{}
+
+func (*weightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &weightedPicker{}
+	for sc, scInfo := range info.ReadySCs {
+		w := registry.AddrWeight(scInfo.Address)
+		p.items = append(p.items, &weightedItem{subConn: sc, weight: w})
+		p.total += w
+	}
+	return p
+}
+
+type weightedItem struct {
+	subConn balancer.SubConn
+	weight  int
+	current int
+}
+
+type weightedPicker struct {
+	mu    sync.Mutex
+	items []*weightedItem
+	total int
+}
+
+// Pick 平滑加权轮询：每次所有实例累加自身权重，选出当前值最大者并减去总权重
+func (p *weightedPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *weightedItem
+	for _, item := range p.items {
+		item.current += item.weight
+		if best == nil || item.current > best.current {
+			best = item
+		}
+	}
+	best.current -= p.total
+	return balancer.PickResult{SubConn: best.subConn}, nil
+}
@@ -0,0 +1,151 @@
+package grpc_client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 熔断器状态，数值用于指标
+const (
+	breakerClosed   = 0
+	breakerHalfOpen = 1
+	breakerOpen     = 2
+)
+
+// ErrCircuitOpen 下游服务熔断中，直接返回而不发起调用
+var ErrCircuitOpen = errcode.New(codes.Unavailable, "CIRCUIT_OPEN", "服务暂不可用，请稍后再试")
+
+// breaker 按下游服务划分的熔断器：连续失败达到阈值后熔断，熔断期过后只放行一个探测请求，成功则恢复
+type breaker struct {
+	service   string
+	threshold int
+	timeout   time.Duration
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool // 半开状态下是否已有探测请求
+}
+
+// newBreaker 未配置或阈值为 0 时返回 nil，nil 熔断器放行所有请求
+func newBreaker(service string, conf *config.Breaker) *breaker {
+	if conf == nil || conf.FailureThreshold <= 0 {
+		return nil
+	}
+	b := &breaker{service: service, threshold: conf.FailureThreshold, timeout: conf.OpenTimeout}
+	breakerState.WithLabelValues(service).Set(breakerClosed)
+	return b
+}
+
+// allow 判断是否放行请求
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.timeout {
+			return false
+		}
+		b.setState(breakerHalfOpen)
+		b.probing = true
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record 记录调用结果，只有下游故障类错误计入失败
+func (b *breaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// 调用方取消或被熔断拒绝的请求不能说明下游是否恢复，不改变状态，只允许发起新的探测
+	if ignored(err) {
+		b.probing = false
+		return
+	}
+	if !isFailure(err) {
+		b.failures = 0
+		b.probing = false
+		if b.state != breakerClosed {
+			logger.Info("Circuit breaker closed", zap.String("service", b.service))
+			b.setState(breakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		if b.state != breakerOpen {
+			logger.Warn("Circuit breaker opened", zap.String("service", b.service), zap.Int("failures", b.failures), zap.Error(err))
+		}
+		b.probing = false
+		b.openedAt = time.Now()
+		b.setState(breakerOpen)
+	}
+}
+
+func (b *breaker) setState(state int) {
+	b.state = state
+	breakerState.WithLabelValues(b.service).Set(float64(state))
+}
+
+// ignored 调用方自己取消（如客户端断开连接）或熔断器拒绝的请求，与下游状态无关
+func ignored(err error) bool {
+	return status.Code(err) == codes.Canceled || errors.Is(err, ErrCircuitOpen)
+}
+
+// isFailure 下游不可用、超时或内部错误视为失败；参数错误、资源不存在等业务错误说明下游正常
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// unaryInterceptor 为每次调用设置超时并经过熔断器，重试由 service config 在超时范围内完成
+func unaryInterceptor(service string, conf *config.Downstream, b *breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !b.allow() {
+			breakerRejected.WithLabelValues(service).Inc()
+			return ErrCircuitOpen.WithMetadata("service", service)
+		}
+
+		if timeout := methodTimeout(conf, method); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.record(err)
+		return err
+	}
+}
@@ -0,0 +1,87 @@
+package grpc_client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bluebell_microservices/common/config"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreaker(t *testing.T) {
+	var (
+		unavailable = status.Error(codes.Unavailable, "down")
+		notFound    = status.Error(codes.NotFound, "no such post")
+		canceled    = status.Error(codes.Canceled, context.Canceled.Error())
+	)
+	type step struct {
+		wait      bool  // 先等待熔断期结束
+		wantAllow bool  // allow() 的期望结果
+		err       error // 放行时记录的调用结果
+		wantState int   // 记录后的状态
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "business errors do not open",
+			steps: []step{
+				{wantAllow: true, err: unavailable, wantState: breakerClosed},
+				{wantAllow: true, err: notFound, wantState: breakerClosed},
+				{wantAllow: true, err: unavailable, wantState: breakerClosed},
+			},
+		},
+		{
+			name: "open then recover",
+			steps: []step{
+				{wantAllow: true, err: unavailable, wantState: breakerClosed},
+				{wantAllow: true, err: unavailable, wantState: breakerOpen},
+				{wantAllow: false, wantState: breakerOpen},
+				{wait: true, wantAllow: true, err: nil, wantState: breakerClosed},
+				{wantAllow: true, err: unavailable, wantState: breakerClosed},
+			},
+		},
+		{
+			name: "failed probe reopens",
+			steps: []step{
+				{wantAllow: true, err: unavailable, wantState: breakerClosed},
+				{wantAllow: true, err: unavailable, wantState: breakerOpen},
+				{wait: true, wantAllow: true, err: unavailable, wantState: breakerOpen},
+				{wantAllow: false, wantState: breakerOpen},
+			},
+		},
+		{
+			name: "canceled probe stays half-open",
+			steps: []step{
+				{wantAllow: true, err: unavailable, wantState: breakerClosed},
+				{wantAllow: true, err: unavailable, wantState: breakerOpen},
+				{wait: true, wantAllow: true, err: canceled, wantState: breakerHalfOpen},
+				{wantAllow: true, err: ErrCircuitOpen, wantState: breakerHalfOpen},
+				{wantAllow: true, err: nil, wantState: breakerClosed},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBreaker("test", &config.Breaker{FailureThreshold: 2, OpenTimeout: 10 * time.Millisecond})
+			for i, s := range tt.steps {
+				if s.wait {
+					time.Sleep(15 * time.Millisecond)
+				}
+				if allowed := b.allow(); allowed != s.wantAllow {
+					t.Fatalf("step %d: allow() = %v, want %v", i, allowed, s.wantAllow)
+				}
+				if s.wantAllow {
+					b.record(s.err)
+				}
+				if b.state != s.wantState {
+					t.Fatalf("step %d: state = %d, want %d", i, b.state, s.wantState)
+				}
+			}
+		})
+	}
+}
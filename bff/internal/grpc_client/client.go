@@ -2,6 +2,10 @@ package grpc_client
 
 import (
 	// 根据你的 proto 文件调整包路径
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/registry"
+	"bluebell_microservices/common/pkg/tracing"
 
 	"bluebell_microservices/proto/comment"
 	"bluebell_microservices/proto/post"
@@ -10,8 +14,11 @@ import (
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
 )
 
 // Clients 结构体可以扩展以支持其他服务（例如 Post、Vote 等）
@@ -20,73 +27,82 @@ type Clients struct {
 	User                            user.UserServiceClient
 	Post                            post.PostServiceClient
 	Comment                         comment.CommentServiceClient
-	userConn, postConn, commentConn *grpc.ClientConn // 保存连接以便关闭
+	Health                          map[string]healthpb.HealthClient // 各下游服务的健康检查客户端，key 为服务名
+	Optional                        map[string]bool                  // 不可用时可以降级的下游服务，不影响 BFF 就绪状态
+	userConn, postConn, commentConn *grpc.ClientConn                 // 保存连接以便关闭
 }
 
-// NewClients 初始化 gRPC 客户端
+// optionalServices 不可用时可以降级的下游服务：评论服务故障时帖子详情仍可展示
+var optionalServices = map[string]bool{"comment": true}
+
+// NewClients 初始化 gRPC 客户端，连接在首次调用时建立；下游暂不可用时由重试、熔断处理，配置错误时返回错误
 func NewClients() (*Clients, error) {
 	// 获取etcd地址
-	etcdAddr := "etcd-container:2379" // 硬编码etcd地址
-	fmt.Printf("正在连接etcd服务: %s\n", etcdAddr)
+	etcdEndpoints := config.Conf.Etcd.Endpoints()
+	logger.Info("Connecting to etcd", zap.Strings("endpoints", etcdEndpoints))
 
 	// 初始化 etcd 客户端
 	etcdClient, err := clientv3.New(clientv3.Config{
-		Endpoints:   []string{etcdAddr},
+		Endpoints:   etcdEndpoints,
 		DialTimeout: 5 * time.Second,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("初始化 etcd 客户端失败: %v", err)
 	}
 
-	// 注册 etcd resolver
-	rb := NewEtcdResolverBuilder(etcdClient)
-	RegisterEtcdResolver(rb)
+	return NewClientsWith(registry.NewEtcdResolverBuilder(etcdClient))
+}
 
-	// 服务名称 - 确保没有尾部斜杠
-	userServiceName := "etcd://user"
-	postServiceName := "etcd://post"
-	commentServiceName := "etcd://comment"
+// NewClientsWith 使用指定的服务发现创建 gRPC 客户端，opts 追加到每个连接上（如测试中的 bufconn dialer）
+func NewClientsWith(rb resolver.Builder, opts ...grpc.DialOption) (*Clients, error) {
+	var err error
+	opts = append([]grpc.DialOption{grpc.WithResolvers(rb)}, opts...)
+	clients := &Clients{Optional: optionalServices}
+	if clients.userConn, err = dial("user", opts...); err != nil {
+		clients.Close()
+		return nil, err
+	}
+	if clients.postConn, err = dial("post", opts...); err != nil {
+		clients.Close()
+		return nil, err
+	}
+	if clients.commentConn, err = dial("comment", opts...); err != nil {
+		clients.Close()
+		return nil, err
+	}
 
-	// 连接用户服务（非阻塞）
-	fmt.Println("正在初始化微服务连接...")
-	userConn, err := grpc.Dial(
-		userServiceName,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	if err != nil {
-		fmt.Printf("连接用户服务失败: %v\n", err)
+	clients.User = user.NewUserServiceClient(clients.userConn)
+	clients.Post = post.NewPostServiceClient(clients.postConn)
+	clients.Comment = comment.NewCommentServiceClient(clients.commentConn)
+	clients.Health = map[string]healthpb.HealthClient{
+		"user":    healthpb.NewHealthClient(clients.userConn),
+		"post":    healthpb.NewHealthClient(clients.postConn),
+		"comment": healthpb.NewHealthClient(clients.commentConn),
 	}
+	logger.Info("gRPC clients initialized")
+	return clients, nil
+}
 
-	// 连接帖子服务（非阻塞）
-	postConn, err := grpc.Dial(
-		postServiceName,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	if err != nil {
-		fmt.Printf("连接帖子服务失败: %v\n", err)
+// dial 创建到下游服务的连接，按 downstreams.<service> 配置超时、重试及熔断
+func dial(service string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	conf := config.Conf.Downstreams[service]
+	var retry *config.Retry
+	var breakerConf *config.Breaker
+	if conf != nil {
+		retry, breakerConf = conf.Retry, conf.Breaker
 	}
 
-	// 连接评论服务（非阻塞）
-	commentConn, err := grpc.Dial(
-		commentServiceName,
+	opts = append([]grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+		grpc.WithDefaultServiceConfig(serviceConfig(config.Conf.Etcd.Balancer, service, retry)),
+		grpc.WithChainUnaryInterceptor(unaryInterceptor(service, conf, newBreaker(service, breakerConf))),
+		tracing.DialOption(),
+	}, opts...)
+	conn, err := grpc.NewClient("etcd://"+service, opts...) // 服务名称 - 确保没有尾部斜杠
 	if err != nil {
-		fmt.Printf("连接评论服务失败: %v\n", err)
+		return nil, fmt.Errorf("连接 %s 服务失败: %v", service, err)
 	}
-
-	fmt.Println("微服务客户端初始化完成")
-
-	// 返回客户端，即使某些服务未连接
-	clients := &Clients{
-		User:        user.NewUserServiceClient(userConn),
-		Post:        post.NewPostServiceClient(postConn),
-		Comment:     comment.NewCommentServiceClient(commentConn),
-		userConn:    userConn,
-		postConn:    postConn,
-		commentConn: commentConn,
-	}
-	return clients, nil
+	return conn, nil
 }
 
 // Close 关闭所有连接
@@ -0,0 +1,24 @@
+package grpc_client
+
+import (
+	"bluebell_microservices/common/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	breakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "state",
+		Help:      "下游服务熔断器状态：0 关闭，1 半开，2 熔断",
+	}, []string{"service"})
+
+	breakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "rejected_total",
+		Help:      "熔断期间被直接拒绝的调用数",
+	}, []string{"service"})
+)
@@ -0,0 +1,89 @@
+package grpc_client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/proto/comment"
+	"bluebell_microservices/proto/post"
+	"bluebell_microservices/proto/user"
+)
+
+// idempotentMethods 可以安全重试的只读接口，写接口重试可能导致重复发帖、重复评论
+var idempotentMethods = map[string][]string{
+	"user": {
+		user.UserService_GetUsersByIDs_FullMethodName,
+	},
+	"post": {
+		post.PostService_GetPostList_FullMethodName,
+		post.PostService_GetPostById_FullMethodName,
+		post.PostService_GetPostsByIDs_FullMethodName,
+	},
+	"comment": {
+		comment.CommentService_GetCommentList_FullMethodName,
+		comment.CommentService_GetCommentCounts_FullMethodName,
+	},
+}
+
+// methodTimeout 返回方法的超时时间，methods 中的配置优先，方法名忽略大小写（viper 会将 key 转为小写）
+func methodTimeout(conf *config.Downstream, fullMethod string) time.Duration {
+	if conf == nil {
+		return 0
+	}
+	name := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	for m, timeout := range conf.Methods {
+		if strings.EqualFold(m, name) {
+			return timeout
+		}
+	}
+	return conf.Timeout
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	RetryPolicy *retryPolicy `json:"retryPolicy"`
+}
+
+// retryMethodConfig 为服务的幂等接口生成 service config 中的 methodConfig，未配置重试时返回空
+// 只重试 UNAVAILABLE（连接失败、实例下线），超时不重试，避免放大下游压力
+func retryMethodConfig(service string, retry *config.Retry) []methodConfig {
+	methods := idempotentMethods[service]
+	if retry == nil || retry.MaxAttempts < 2 || len(methods) == 0 {
+		return nil
+	}
+
+	mc := methodConfig{
+		RetryPolicy: &retryPolicy{
+			MaxAttempts:          retry.MaxAttempts,
+			InitialBackoff:       durationString(retry.InitialBackoff),
+			MaxBackoff:           durationString(retry.MaxBackoff),
+			BackoffMultiplier:    retry.BackoffMultiplier,
+			RetryableStatusCodes: []string{"UNAVAILABLE"},
+		},
+	}
+	for _, full := range methods {
+		svc, method, _ := strings.Cut(strings.TrimPrefix(full, "/"), "/")
+		mc.Name = append(mc.Name, methodName{Service: svc, Method: method})
+	}
+	return []methodConfig{mc}
+}
+
+// durationString service config 要求的时长格式，如 0.1s
+func durationString(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
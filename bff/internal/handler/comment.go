@@ -2,8 +2,11 @@ package handler
 
 import (
 	"bluebell_microservices/bff/internal/middleware"
+	"bluebell_microservices/bff/internal/response"
 	"bluebell_microservices/common/pkg/logger"
 	"bluebell_microservices/proto/comment"
+	"bluebell_microservices/proto/post"
+	"bluebell_microservices/proto/user"
 	"net/http"
 	"strconv"
 
@@ -11,7 +14,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func CommentHandler(client comment.CommentServiceClient) gin.HandlerFunc {
+func CommentHandler(client comment.CommentServiceClient, postClient post.PostServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
 
@@ -19,10 +22,7 @@ func CommentHandler(client comment.CommentServiceClient) gin.HandlerFunc {
 		userIDInterface, exists := c.Get(middleware.ContextUserIDKey)
 		if !exists {
 			logger.Error("User not logged in", zap.String("trace_id", traceID))
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "请先登录",
-			})
+			response.Unauthorized(c, "请先登录")
 			return
 		}
 
@@ -30,10 +30,7 @@ func CommentHandler(client comment.CommentServiceClient) gin.HandlerFunc {
 		AuthorID, ok := userIDInterface.(uint64)
 		if !ok {
 			logger.Error("Invalid author_id type", zap.String("trace_id", traceID))
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "author_id 类型错误",
-			})
+			response.Internal(c)
 			return
 		}
 
@@ -49,30 +46,27 @@ func CommentHandler(client comment.CommentServiceClient) gin.HandlerFunc {
 			logger.Error("Invalid request parameters",
 				zap.String("trace_id", traceID),
 				zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code": 400,
-				"msg":  "请求参数错误",
-				"data": err.Error(),
-			})
+			response.BadRequest(c, err.Error())
 			return
 		}
 
-		// 参数验证
-		if req.PostID == 0 {
-			logger.Error("PostID is required", zap.String("trace_id", traceID))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code": 400,
-				"msg":  "帖子ID不能为空",
-			})
+		// 内容长度、屏蔽词及父评论等规则由 comment-service 统一校验
+		// 被封禁的用户不能在帖子所属社区评论
+		roleResp, err := postClient.GetCommunityRole(c.Request.Context(), &post.GetCommunityRoleRequest{
+			UserId: int64(AuthorID),
+			PostId: int64(req.PostID),
+		})
+		if err != nil {
+			logger.Error("Failed to get community role", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
 			return
 		}
-
-		if req.Content == "" {
-			logger.Error("Content is required", zap.String("trace_id", traceID))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code": 400,
-				"msg":  "评论内容不能为空",
-			})
+		if roleResp.IsBanned {
+			logger.Warn("Banned user tried to comment",
+				zap.String("trace_id", traceID),
+				zap.Uint64("author_id", AuthorID),
+				zap.Int64("community_id", roleResp.CommunityId))
+			response.Error(c, http.StatusForbidden, "USER_BANNED_IN_COMMUNITY", "已被禁止在该社区发言")
 			return
 		}
 
@@ -90,11 +84,7 @@ func CommentHandler(client comment.CommentServiceClient) gin.HandlerFunc {
 			logger.Error("Failed to create comment",
 				zap.String("trace_id", traceID),
 				zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "创建评论失败",
-				"data": err.Error(),
-			})
+			response.GRPCError(c, err)
 			return
 		}
 
@@ -107,7 +97,8 @@ func CommentHandler(client comment.CommentServiceClient) gin.HandlerFunc {
 	}
 }
 
-func CommentListHandler(client comment.CommentServiceClient) gin.HandlerFunc {
+// CommentListHandler 评论列表，附加评论作者名；用户服务不可用时降级，degraded 中列出缺失的部分
+func CommentListHandler(client comment.CommentServiceClient, userClient user.UserServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
 
@@ -115,10 +106,7 @@ func CommentListHandler(client comment.CommentServiceClient) gin.HandlerFunc {
 		postIDStr := c.Query("post_id")
 		if postIDStr == "" {
 			logger.Error("PostID is required", zap.String("trace_id", traceID))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code": 400,
-				"msg":  "帖子ID不能为空",
-			})
+			response.BadRequest(c, "帖子ID不能为空")
 			return
 		}
 
@@ -126,10 +114,7 @@ func CommentListHandler(client comment.CommentServiceClient) gin.HandlerFunc {
 		postID, err := strconv.ParseUint(postIDStr, 10, 64)
 		if err != nil {
 			logger.Error("Invalid post_id", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code": 400,
-				"msg":  "帖子ID格式错误",
-			})
+			response.BadRequest(c, "帖子ID格式错误")
 			return
 		}
 
@@ -139,19 +124,23 @@ func CommentListHandler(client comment.CommentServiceClient) gin.HandlerFunc {
 		})
 		if err != nil {
 			logger.Error("Failed to get comment list", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "获取评论列表失败",
-				"data": err.Error(),
-			})
+			response.GRPCError(c, err)
 			return
 		}
 
-		// 返回成功响应
-		c.JSON(http.StatusOK, gin.H{
+		// 附加作者名后返回
+		items, degraded := withAuthorNames(c, userClient, resp.Comments)
+		body := gin.H{
 			"code": 0,
 			"msg":  "获取评论列表成功",
-			"data": resp,
-		})
+			"data": gin.H{
+				"message":  resp.Message,
+				"comments": items,
+			},
+		}
+		if degraded != nil {
+			body["degraded"] = degraded
+		}
+		c.JSON(http.StatusOK, body)
 	}
 }
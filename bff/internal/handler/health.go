@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"bluebell_microservices/bff/internal/dao/redis"
+	"bluebell_microservices/common/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// readinessTimeout 单个下游健康检查的超时时间
+const readinessTimeout = time.Second
+
+// HealthzHandler 存活探针，进程能处理请求即返回 200
+func HealthzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// ReadyzHandler 就绪探针，汇总 BFF 自身依赖及各下游服务的 grpc.health.v1 状态，任一异常返回 503
+// optional 中的下游服务可以降级，异常时状态为 degraded，不影响就绪
+func ReadyzHandler(services map[string]healthpb.HealthClient, optional map[string]bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id")
+
+		var (
+			mu       sync.Mutex
+			wg       sync.WaitGroup
+			ready    = true
+			degraded = false
+			checks   = make(map[string]string, len(services)+1)
+		)
+		record := func(name string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if optional[name] {
+					degraded = true
+				} else {
+					ready = false
+				}
+				checks[name] = err.Error()
+				logger.Warn("Readiness check failed", zap.String("trace_id", traceID), zap.String("dependency", name), zap.Error(err))
+				return
+			}
+			checks[name] = "ok"
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record("redis", redis.Ping(ctx))
+		}()
+
+		for name, client := range services {
+			wg.Add(1)
+			go func(name string, client healthpb.HealthClient) {
+				defer wg.Done()
+				resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+				if err == nil && resp.Status != healthpb.HealthCheckResponse_SERVING {
+					err = &notServingError{status: resp.Status}
+				}
+				record(name, err)
+			}(name, client)
+		}
+		wg.Wait()
+
+		status, text := http.StatusOK, "ok"
+		switch {
+		case !ready:
+			status, text = http.StatusServiceUnavailable, "unavailable"
+		case degraded:
+			text = "degraded"
+		}
+		c.JSON(status, gin.H{
+			"status": text,
+			"checks": checks,
+		})
+	}
+}
+
+// notServingError 下游服务返回了非 SERVING 状态
+type notServingError struct {
+	status healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (e *notServingError) Error() string {
+	return e.status.String()
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/proto/comment"
+	"bluebell_microservices/proto/user"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 列表组装：一页数据通过下游批量接口一次查询（超过 batchSize 时分批），失败时降级，响应的 degraded 中列出缺失的部分
+
+// batchSize 与下游批量接口单次查询的上限一致
+const batchSize = 100
+
+// commentItem 评论列表项，在评论上附加作者名
+type commentItem struct {
+	*comment.Comment
+	AuthorName string `json:"author_name"`
+}
+
+// withAuthorNames 通过 UserService.GetUsersByIDs 为评论附加作者名，用户服务不可用时作者名为空
+func withAuthorNames(c *gin.Context, userClient user.UserServiceClient, comments []*comment.Comment) ([]commentItem, []string) {
+	items := make([]commentItem, 0, len(comments))
+	ids := make([]uint64, 0, len(comments))
+	for _, cm := range comments {
+		items = append(items, commentItem{Comment: cm})
+		ids = append(ids, cm.AuthorId)
+	}
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	names := make(map[uint64]string, len(ids))
+	for _, batch := range batches(ids) {
+		resp, err := userClient.GetUsersByIDs(c.Request.Context(), &user.GetUsersByIDsRequest{UserIds: batch})
+		if err != nil {
+			logger.Warn("Failed to get comment authors, degrading comment list",
+				zap.String("trace_id", c.GetString("trace_id")), zap.Int("count", len(ids)), zap.Error(err))
+			return items, []string{"author_names"}
+		}
+		for _, u := range resp.Users {
+			names[u.UserId] = u.Username
+		}
+	}
+	for i := range items {
+		items[i].AuthorName = names[items[i].AuthorId]
+	}
+	return items, nil
+}
+
+// batches 去重后按 batchSize 分批
+func batches(ids []uint64) [][]uint64 {
+	seen := make(map[uint64]bool, len(ids))
+	unique := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	var out [][]uint64
+	for start := 0; start < len(unique); start += batchSize {
+		out = append(out, unique[start:min(start+batchSize, len(unique))])
+	}
+	return out
+}
@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"bluebell_microservices/bff/internal/middleware"
+	"bluebell_microservices/bff/internal/response"
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/proto/comment"
+	pb "bluebell_microservices/proto/post"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ReportPostHandler 举报帖子
+func ReportPostHandler(client pb.PostServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
+		userID := c.GetUint64(middleware.ContextUserIDKey)
+
+		var req struct {
+			PostID int64  `json:"post_id" binding:"required"`
+			Reason string `json:"reason" binding:"required,max=512"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error("Invalid request parameters", zap.String("trace_id", traceID), zap.Error(err))
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		resp, err := client.ReportPost(c.Request.Context(), &pb.ReportPostRequest{
+			PostId:     req.PostID,
+			ReporterId: int64(userID),
+			Reason:     req.Reason,
+		})
+		if err != nil {
+			logger.Error("Failed to call post-service ReportPost", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+
+		logger.Info("ReportPost successful",
+			zap.String("trace_id", traceID),
+			zap.Int64("post_id", req.PostID),
+			zap.Int64("report_id", resp.ReportId))
+		c.JSON(http.StatusOK, gin.H{
+			"code":    resp.Code,
+			"message": resp.Msg,
+			"data":    gin.H{"report_id": resp.ReportId},
+		})
+	}
+}
+
+// ReportCommentHandler 举报评论，评论所属帖子和作者从评论服务获取
+func ReportCommentHandler(client pb.PostServiceClient, commentClient comment.CommentServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
+		userID := c.GetUint64(middleware.ContextUserIDKey)
+
+		var req struct {
+			CommentID uint64 `json:"comment_id" binding:"required"`
+			Reason    string `json:"reason" binding:"required,max=512"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error("Invalid request parameters", zap.String("trace_id", traceID), zap.Error(err))
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		// 1、查询评论，获取所属帖子和作者
+		commentResp, err := commentClient.GetComment(c.Request.Context(), &comment.GetCommentRequest{CommentId: req.CommentID})
+		if err != nil {
+			logger.Error("Failed to call comment-service GetComment", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+
+		// 2、提交举报
+		resp, err := client.ReportComment(c.Request.Context(), &pb.ReportCommentRequest{
+			CommentId:  int64(req.CommentID),
+			PostId:     int64(commentResp.Comment.PostId),
+			AuthorId:   int64(commentResp.Comment.AuthorId),
+			ReporterId: int64(userID),
+			Reason:     req.Reason,
+		})
+		if err != nil {
+			logger.Error("Failed to call post-service ReportComment", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+
+		logger.Info("ReportComment successful",
+			zap.String("trace_id", traceID),
+			zap.Uint64("comment_id", req.CommentID),
+			zap.Int64("report_id", resp.ReportId))
+		c.JSON(http.StatusOK, gin.H{
+			"code":    resp.Code,
+			"message": resp.Msg,
+			"data":    gin.H{"report_id": resp.ReportId},
+		})
+	}
+}
+
+// ReportListHandler 版主查看社区举报队列
+func ReportListHandler(client pb.PostServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
+		communityID := c.GetInt64(middleware.ContextCommunityIDKey)
+
+		var req struct {
+			Status string `form:"status" binding:"omitempty,oneof=open actioned dismissed"`
+			Page   int64  `form:"page"`
+			Size   int64  `form:"size"`
+		}
+		if err := c.ShouldBindQuery(&req); err != nil {
+			logger.Warn("Invalid request", zap.String("trace_id", traceID), zap.Error(err))
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		// 设置默认值
+		if req.Page == 0 {
+			req.Page = 1
+		}
+		if req.Size == 0 {
+			req.Size = 20
+		}
+		if req.Status == "" {
+			req.Status = "open"
+		}
+
+		resp, err := client.ListReports(c.Request.Context(), &pb.ListReportsRequest{
+			CommunityId: communityID,
+			Status:      req.Status,
+			Page:        req.Page,
+			Size:        req.Size,
+		})
+		if err != nil {
+			logger.Error("Failed to call post-service ListReports", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"code":    resp.Code,
+			"message": resp.Msg,
+			"data": gin.H{
+				"page": resp.Page,
+				"list": resp.Reports,
+			},
+		})
+	}
+}
+
+// errTargetMismatch 与 post-service 的同名错误一致
+var errTargetMismatch = errcode.InvalidArgument("TARGET_MISMATCH", "操作对象不属于该社区或与举报不符")
+
+// ModerateHandler 版主操作：隐藏帖子、删除评论、封禁用户、驳回举报
+func ModerateHandler(client pb.PostServiceClient, commentClient comment.CommentServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
+		userID := c.GetUint64(middleware.ContextUserIDKey)
+		communityID := c.GetInt64(middleware.ContextCommunityIDKey)
+
+		var req struct {
+			Action    string `json:"action" binding:"required,oneof=hide_post remove_comment ban_user dismiss"`
+			PostID    int64  `json:"post_id"`
+			CommentID int64  `json:"comment_id"`
+			UserID    int64  `json:"user_id"`
+			ReportID  int64  `json:"report_id"`
+			Reason    string `json:"reason" binding:"max=512"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error("Invalid request parameters", zap.String("trace_id", traceID), zap.Error(err))
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		// 1、删除评论时，评论及所属帖子以评论服务为准；关联举报时只能删除被举报的评论
+		if req.Action == "remove_comment" {
+			if req.ReportID != 0 {
+				reportResp, err := client.GetReport(c.Request.Context(), &pb.GetReportRequest{ReportId: req.ReportID})
+				if err != nil {
+					logger.Error("Failed to call post-service GetReport", zap.String("trace_id", traceID), zap.Error(err))
+					response.GRPCError(c, err)
+					return
+				}
+				report := reportResp.Report
+				if report.TargetType != "comment" || (req.CommentID != 0 && req.CommentID != report.TargetId) {
+					logger.Warn("Comment does not match report", zap.String("trace_id", traceID),
+						zap.Int64("report_id", req.ReportID), zap.Int64("comment_id", req.CommentID))
+					response.GRPCError(c, errTargetMismatch)
+					return
+				}
+				req.CommentID = report.TargetId
+			}
+			commentResp, err := commentClient.GetComment(c.Request.Context(), &comment.GetCommentRequest{CommentId: uint64(req.CommentID)})
+			if err != nil {
+				logger.Error("Failed to call comment-service GetComment", zap.String("trace_id", traceID), zap.Error(err))
+				response.GRPCError(c, err)
+				return
+			}
+			req.PostID = int64(commentResp.Comment.PostId)
+		}
+
+		moderateReq := &pb.ModerateRequest{
+			ModeratorId: int64(userID),
+			CommunityId: communityID,
+			Action:      req.Action,
+			PostId:      req.PostID,
+			CommentId:   req.CommentID,
+			UserId:      req.UserID,
+			ReportId:    req.ReportID,
+			Reason:      req.Reason,
+		}
+
+		// 2、删除评论时先由 post-service 校验，评论删除成功后再处理举报、写审计日志；
+		// 删除评论可重复执行，后续步骤失败时版主重试即可
+		if req.Action == "remove_comment" {
+			moderateReq.ValidateOnly = true
+			if _, err := client.Moderate(c.Request.Context(), moderateReq); err != nil {
+				logger.Error("Failed to call post-service Moderate", zap.String("trace_id", traceID), zap.Error(err))
+				response.GRPCError(c, err)
+				return
+			}
+			_, err := commentClient.RemoveComment(c.Request.Context(), &comment.RemoveCommentRequest{
+				CommentId:  uint64(req.CommentID),
+				OperatorId: userID,
+			})
+			if err != nil {
+				logger.Error("Failed to call comment-service RemoveComment", zap.String("trace_id", traceID), zap.Error(err))
+				response.GRPCError(c, err)
+				return
+			}
+			moderateReq.ValidateOnly = false
+		}
+
+		// 3、post-service 校验操作对象属于该社区，执行操作并写入审计日志
+		if _, err := client.Moderate(c.Request.Context(), moderateReq); err != nil {
+			logger.Error("Failed to call post-service Moderate", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+
+		logger.Info("Moderate successful",
+			zap.String("trace_id", traceID),
+			zap.Uint64("moderator_id", userID),
+			zap.Int64("community_id", communityID),
+			zap.String("action", req.Action))
+		c.JSON(http.StatusOK, gin.H{
+			"code":    0,
+			"message": "success",
+		})
+	}
+}
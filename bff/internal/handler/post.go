@@ -2,15 +2,19 @@ package handler
 
 import (
 	"bluebell_microservices/bff/internal/middleware"
+	"bluebell_microservices/bff/internal/response"
 	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/proto/comment"
 	pb "bluebell_microservices/proto/post"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// CreatePostHandler 发帖；draft 为 true 或设置了 publish_time 时保存为草稿，草稿到定时发布时间后自动发布
 func CreatePostHandler(client pb.PostServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
@@ -19,10 +23,7 @@ func CreatePostHandler(client pb.PostServiceClient) gin.HandlerFunc {
 		userIDInterface, exists := c.Get(middleware.ContextUserIDKey)
 		if !exists {
 			logger.Error("User not logged in", zap.String("trace_id", traceID))
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "请先登录",
-			})
+			response.Unauthorized(c, "请先登录")
 			return
 		}
 
@@ -32,24 +33,23 @@ func CreatePostHandler(client pb.PostServiceClient) gin.HandlerFunc {
 			logger.Error("Invalid user_id type",
 				zap.String("trace_id", traceID),
 				zap.Any("user_id", userIDInterface))
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "用户ID类型错误",
-			})
+			response.Internal(c)
 			return
 		}
 
 		// 1、获取参数及校验参数
 		var req struct {
-			CommunityID int64  `json:"community_id" binding:"required"`
-			Title       string `json:"title" binding:"required"`
-			Content     string `json:"content" binding:"required"`
+			CommunityID int64      `json:"community_id" binding:"required"`
+			Title       string     `json:"title" binding:"required"`
+			Content     string     `json:"content" binding:"required"`
+			Draft       bool       `json:"draft"`
+			PublishTime *time.Time `json:"publish_time"` // RFC 3339
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			logger.Error("Invalid request parameters",
 				zap.String("trace_id", traceID),
 				zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			response.BadRequest(c, err.Error())
 			return
 		}
 
@@ -59,6 +59,8 @@ func CreatePostHandler(client pb.PostServiceClient) gin.HandlerFunc {
 			Title:       req.Title,
 			Content:     req.Content,
 			AuthorId:    int64(userID), // 将 uint64 转换为 int64
+			Draft:       req.Draft,
+			PublishTime: unixTime(req.PublishTime),
 		}
 
 		logger.Info("Calling post-service CreatePost",
@@ -73,30 +75,110 @@ func CreatePostHandler(client pb.PostServiceClient) gin.HandlerFunc {
 			logger.Error("Failed to call post-service",
 				zap.String("trace_id", traceID),
 				zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			response.GRPCError(c, err)
 			return
 		}
 
 		// 4、处理响应
-		switch resp.Code {
-		case 0:
-			logger.Info("CreatePost successful",
-				zap.String("trace_id", traceID),
-				zap.Uint64("user_id", userID))
-			c.JSON(http.StatusOK, gin.H{
-				"code":    resp.Code,
-				"message": resp.Msg,
-			})
-		default:
-			logger.Warn("CreatePost failed",
-				zap.String("trace_id", traceID),
-				zap.String("msg", resp.Msg))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": resp.Msg})
+		logger.Info("CreatePost successful",
+			zap.String("trace_id", traceID),
+			zap.Uint64("user_id", userID),
+			zap.Int64("post_id", resp.PostId))
+		c.JSON(http.StatusOK, gin.H{
+			"code":    resp.Code,
+			"message": resp.Msg,
+			"data":    gin.H{"post_id": strconv.FormatInt(resp.PostId, 10)},
+		})
+	}
+}
+
+// UpdateDraftHandler 修改草稿，未设置 publish_time 时取消定时发布
+func UpdateDraftHandler(client pb.PostServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
+		userID := c.GetUint64(middleware.ContextUserIDKey)
+
+		postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			logger.Error("Invalid post ID", zap.String("trace_id", traceID), zap.Error(err))
+			response.BadRequest(c, "帖子ID格式错误")
+			return
 		}
+		var req struct {
+			CommunityID int64      `json:"community_id" binding:"required"`
+			Title       string     `json:"title" binding:"required"`
+			Content     string     `json:"content" binding:"required"`
+			PublishTime *time.Time `json:"publish_time"` // RFC 3339
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error("Invalid request parameters", zap.String("trace_id", traceID), zap.Error(err))
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		resp, err := client.UpdateDraft(c.Request.Context(), &pb.UpdateDraftRequest{
+			PostId:      postID,
+			AuthorId:    int64(userID),
+			CommunityId: req.CommunityID,
+			Title:       req.Title,
+			Content:     req.Content,
+			PublishTime: unixTime(req.PublishTime),
+		})
+		if err != nil {
+			logger.Error("Failed to call post-service UpdateDraft", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+
+		logger.Info("UpdateDraft successful", zap.String("trace_id", traceID), zap.Int64("post_id", postID))
+		c.JSON(http.StatusOK, gin.H{
+			"code":    resp.Code,
+			"message": resp.Msg,
+		})
 	}
 }
 
-func PostDetailHandler(client pb.PostServiceClient) gin.HandlerFunc {
+// PublishDraftHandler 立即发布草稿
+func PublishDraftHandler(client pb.PostServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
+		userID := c.GetUint64(middleware.ContextUserIDKey)
+
+		postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			logger.Error("Invalid post ID", zap.String("trace_id", traceID), zap.Error(err))
+			response.BadRequest(c, "帖子ID格式错误")
+			return
+		}
+
+		resp, err := client.PublishDraft(c.Request.Context(), &pb.PublishDraftRequest{
+			PostId:   postID,
+			AuthorId: int64(userID),
+		})
+		if err != nil {
+			logger.Error("Failed to call post-service PublishDraft", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+
+		logger.Info("PublishDraft successful", zap.String("trace_id", traceID), zap.Int64("post_id", postID))
+		c.JSON(http.StatusOK, gin.H{
+			"code":    resp.Code,
+			"message": resp.Msg,
+		})
+	}
+}
+
+// unixTime 定时发布时间转换为 Unix 秒，未设置时为 0
+func unixTime(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// PostDetailHandler 帖子详情及评论；评论服务不可用时降级为只返回帖子，degraded 中列出缺失的部分
+func PostDetailHandler(client pb.PostServiceClient, commentClient comment.CommentServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
 
@@ -105,7 +187,7 @@ func PostDetailHandler(client pb.PostServiceClient) gin.HandlerFunc {
 		postId, err := strconv.ParseInt(postIdStr, 10, 64)
 		if err != nil {
 			logger.Error("Invalid post ID", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": "帖子ID格式错误"})
+			response.BadRequest(c, "帖子ID格式错误")
 			return
 		}
 
@@ -118,30 +200,44 @@ func PostDetailHandler(client pb.PostServiceClient) gin.HandlerFunc {
 			zap.String("trace_id", traceID),
 			zap.Int64("post_id", postId))
 
+		// 评论与帖子并发查询
+		commentsCh := make(chan *comment.GetCommentListResponse, 1)
+		go func() {
+			commentResp, err := commentClient.GetCommentList(c.Request.Context(), &comment.GetCommentListRequest{PostId: uint64(postId)})
+			if err != nil {
+				logger.Warn("Failed to get comments, degrading post detail",
+					zap.String("trace_id", traceID), zap.Int64("post_id", postId), zap.Error(err))
+				commentResp = nil
+			}
+			commentsCh <- commentResp
+		}()
+
 		// 3、调用gRPC服务
 		resp, err := client.GetPostById(c.Request.Context(), grpcReq)
 		if err != nil {
 			logger.Error("Failed to call post-service", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			response.GRPCError(c, err)
 			return
 		}
 
-		// 根据业务码处理响应
-		switch resp.Code {
-		case 0:
-			logger.Info("GetPostById successful", zap.String("trace_id", traceID))
-			c.JSON(http.StatusOK, gin.H{
-				"code":    resp.Code,
-				"message": resp.Msg,
-				"data":    resp.Post,
-			})
-		default:
-			logger.Warn("GetPostById failed", zap.String("trace_id", traceID), zap.String("msg", resp.Msg))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": resp.Msg})
+		// 处理响应
+		logger.Info("GetPostById successful", zap.String("trace_id", traceID))
+		body := gin.H{
+			"code":    resp.Code,
+			"message": resp.Msg,
+			"data":    resp.Post,
+		}
+		if commentResp := <-commentsCh; commentResp != nil {
+			body["comments"] = commentResp.Comments
+		} else {
+			body["comments"] = []*comment.Comment{}
+			body["degraded"] = []string{"comments"}
 		}
+		c.JSON(http.StatusOK, body)
 	}
 }
 
+// GetPostListHandler 帖子列表，评论数由 post-service 随帖子返回
 func GetPostListHandler(client pb.PostServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
@@ -159,7 +255,7 @@ func GetPostListHandler(client pb.PostServiceClient) gin.HandlerFunc {
 		// 改用 ShouldBindQuery 来绑定 URL 查询参数
 		if err := c.ShouldBindQuery(&req); err != nil {
 			logger.Warn("Invalid request", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			response.BadRequest(c, err.Error())
 			return
 		}
 
@@ -191,34 +287,29 @@ func GetPostListHandler(client pb.PostServiceClient) gin.HandlerFunc {
 		resp, err := client.GetPostList(c.Request.Context(), grpcReq)
 		if err != nil {
 			logger.Error("Failed to call post-service", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			response.GRPCError(c, err)
 			return
 		}
 
-		// 根据业务码处理响应
-		switch resp.Code {
-		case 0:
-			logger.Info("GetPostList successful", zap.String("trace_id", traceID))
-			c.JSON(http.StatusOK, gin.H{
-				"code":    resp.Code, // 映射为目标 JSON 的成功码
-				"message": resp.Msg,  // 直接使用 gRPC 的消息
-				"data": gin.H{ // 构造 data 字段
-					"page": gin.H{
-						"total": resp.Page.Total,
-						"page":  resp.Page.Page,
-						"size":  resp.Page.Size,
-					},
-					"list": resp.Posts, // 直接使用 posts，Gin 会自动序列化为 JSON，字段名由 proto 标签决定
+		// 处理响应
+		logger.Info("GetPostList successful", zap.String("trace_id", traceID))
+		c.JSON(http.StatusOK, gin.H{
+			"code":    resp.Code, // 映射为目标 JSON 的成功码
+			"message": resp.Msg,  // 直接使用 gRPC 的消息
+			"data": gin.H{ // 构造 data 字段
+				"page": gin.H{
+					"total": resp.Page.Total,
+					"page":  resp.Page.Page,
+					"size":  resp.Page.Size,
 				},
-			})
-		default:
-			logger.Warn("GetPostList failed", zap.String("trace_id", traceID), zap.String("msg", resp.Msg))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": resp.Msg})
-		}
+				"list": resp.Posts, // 直接使用 posts，Gin 会自动序列化为 JSON，字段名由 proto 标签决定
+			},
+		})
 
 	}
 }
 
+// PostSearchHandler 搜索帖子，评论数由 post-service 随帖子返回
 func PostSearchHandler(client pb.PostServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
@@ -236,7 +327,7 @@ func PostSearchHandler(client pb.PostServiceClient) gin.HandlerFunc {
 		// 改用 ShouldBindQuery 来绑定 URL 查询参数
 		if err := c.ShouldBindQuery(&req); err != nil {
 			logger.Warn("Invalid request", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			response.BadRequest(c, err.Error())
 			return
 		}
 
@@ -268,30 +359,24 @@ func PostSearchHandler(client pb.PostServiceClient) gin.HandlerFunc {
 		resp, err := client.SearchPosts(c.Request.Context(), grpcReq)
 		if err != nil {
 			logger.Error("Failed to call post-service", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			response.GRPCError(c, err)
 			return
 		}
 
-		// 根据业务码处理响应
-		switch resp.Code {
-		case 0:
-			logger.Info("SearchPosts successful", zap.String("trace_id", traceID))
-			c.JSON(http.StatusOK, gin.H{
-				"code":    resp.Code, // 映射为目标 JSON 的成功码
-				"message": resp.Msg,  // 直接使用 gRPC 的消息
-				"data": gin.H{ // 构造 data 字段
-					"page": gin.H{
-						"total": resp.Page.Total,
-						"page":  resp.Page.Page,
-						"size":  resp.Page.Size,
-					},
-					"list": resp.Posts, // 直接使用 posts，Gin 会自动序列化为 JSON，字段名由 proto 标签决定
+		// 处理响应
+		logger.Info("SearchPosts successful", zap.String("trace_id", traceID))
+		c.JSON(http.StatusOK, gin.H{
+			"code":    resp.Code, // 映射为目标 JSON 的成功码
+			"message": resp.Msg,  // 直接使用 gRPC 的消息
+			"data": gin.H{ // 构造 data 字段
+				"page": gin.H{
+					"total": resp.Page.Total,
+					"page":  resp.Page.Page,
+					"size":  resp.Page.Size,
 				},
-			})
-		default:
-			logger.Warn("SearchPosts failed", zap.String("trace_id", traceID), zap.String("msg", resp.Msg))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": resp.Msg})
-		}
+				"list": resp.Posts, // 直接使用 posts，Gin 会自动序列化为 JSON，字段名由 proto 标签决定
+			},
+		})
 	}
 }
 
@@ -303,10 +388,7 @@ func VoteHandler(client pb.PostServiceClient) gin.HandlerFunc {
 		userIDInterface, exists := c.Get(middleware.ContextUserIDKey)
 		if !exists {
 			logger.Error("User not logged in", zap.String("trace_id", traceID))
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "请先登录",
-			})
+			response.Unauthorized(c, "请先登录")
 			return
 		}
 
@@ -316,10 +398,7 @@ func VoteHandler(client pb.PostServiceClient) gin.HandlerFunc {
 			logger.Error("Invalid user_id type",
 				zap.String("trace_id", traceID),
 				zap.Any("user_id", userIDInterface))
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "用户ID类型错误",
-			})
+			response.Internal(c)
 			return
 		}
 
@@ -330,7 +409,7 @@ func VoteHandler(client pb.PostServiceClient) gin.HandlerFunc {
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			logger.Error("Invalid request parameters", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			response.BadRequest(c, err.Error())
 			return
 		}
 
@@ -347,26 +426,18 @@ func VoteHandler(client pb.PostServiceClient) gin.HandlerFunc {
 			logger.Error("Failed to call post-service Vote",
 				zap.String("trace_id", traceID),
 				zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "投票请求处理失败，请稍后重试"})
+			response.GRPCError(c, err)
 			return
 		}
 
 		// 4、处理响应
-		switch resp.Code {
-		case 0:
-			logger.Info("Vote successful",
-				zap.String("trace_id", traceID),
-				zap.Int64("post_id", req.PostID),
-				zap.Int64("direction", req.Direction))
-			c.JSON(http.StatusOK, gin.H{
-				"code":    resp.Code,
-				"message": resp.Msg,
-			})
-		default:
-			logger.Warn("Vote failed",
-				zap.String("trace_id", traceID),
-				zap.String("msg", resp.Msg))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": resp.Msg})
-		}
+		logger.Info("Vote successful",
+			zap.String("trace_id", traceID),
+			zap.Int64("post_id", req.PostID),
+			zap.Int64("direction", req.Direction))
+		c.JSON(http.StatusOK, gin.H{
+			"code":    resp.Code,
+			"message": resp.Msg,
+		})
 	}
 }
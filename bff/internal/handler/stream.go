@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bluebell_microservices/bff/internal/response"
+	"bluebell_microservices/common/pkg/logger"
+	pb "bluebell_microservices/proto/post"
+	"context"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	streamBufferSize        = 32               // 每个连接最多缓存的事件数
+	streamHeartbeatInterval = 15 * time.Second // 心跳间隔，防止代理断开空闲连接
+)
+
+// PostStreamHandler 以 SSE 方式推送帖子的新评论和实时投票数
+func PostStreamHandler(client pb.PostServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
+
+		// 1、获取参数(从URL中获取帖子的id)
+		postId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			logger.Error("Invalid post ID", zap.String("trace_id", traceID), zap.Error(err))
+			response.BadRequest(c, "帖子ID格式错误")
+			return
+		}
+
+		// 2、建立 gRPC 流，客户端断开时取消
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+
+		stream, err := client.SubscribePost(ctx, &pb.SubscribePostRequest{PostId: postId})
+		if err != nil {
+			logger.Error("Failed to call post-service SubscribePost", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+
+		// 3、后台接收事件，写入有界缓冲区；缓冲区满时丢弃最旧的事件，慢客户端不会阻塞上游
+		events := make(chan *pb.PostEvent, streamBufferSize)
+		go func() {
+			defer close(events)
+			for {
+				ev, err := stream.Recv()
+				if err != nil {
+					if err != io.EOF && status.Code(err) != codes.Canceled {
+						logger.Warn("Post event stream closed", zap.String("trace_id", traceID), zap.Error(err))
+					}
+					return
+				}
+				select {
+				case events <- ev:
+				default:
+					select {
+					case <-events:
+					default:
+					}
+					select {
+					case events <- ev:
+					default:
+					}
+				}
+			}
+		}()
+
+		logger.Info("Post stream opened", zap.String("trace_id", traceID), zap.Int64("post_id", postId))
+
+		// 4、推送事件和心跳
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+
+		heartbeat := time.NewTicker(streamHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					return false
+				}
+				c.SSEvent(ev.Type, ev)
+				return true
+			case <-heartbeat.C:
+				c.SSEvent("heartbeat", gin.H{"time": time.Now().Unix()})
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+
+		logger.Info("Post stream closed", zap.String("trace_id", traceID), zap.Int64("post_id", postId))
+	}
+}
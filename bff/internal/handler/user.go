@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"bluebell_microservices/bff/internal/dao/redis"
+	"bluebell_microservices/bff/internal/middleware"
+	"bluebell_microservices/bff/internal/response"
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/errcode"
 	"bluebell_microservices/common/pkg/logger"
 	pb "bluebell_microservices/proto/user"
-	"context"
-	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -13,6 +16,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// reasonInvalidCredentials user-service 登录失败（用户名或密码错误）的错误原因
+const reasonInvalidCredentials = "INVALID_CREDENTIALS"
+
 // UserServiceClient 是 gRPC 客户端的接口，封装了与 gRPC 服务端通信的逻辑 conn
 func SignUpHandler(client pb.UserServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -29,7 +35,7 @@ func SignUpHandler(client pb.UserServiceClient) gin.HandlerFunc {
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			logger.Warn("Invalid request", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			response.BadRequest(c, err.Error())
 			return
 		}
 		genderStr := strconv.Itoa(req.Gender)
@@ -48,25 +54,29 @@ func SignUpHandler(client pb.UserServiceClient) gin.HandlerFunc {
 		resp, err := client.SignUp(c.Request.Context(), grpcReq)
 		if err != nil {
 			logger.Error("Failed to call user-service", zap.String("trace_id", traceID), zap.String("username", req.Username), zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			response.GRPCError(c, err)
 			return
 		}
 
-		// 根据业务码处理响应
-		switch resp.Code {
-		case 0:
-			logger.Info("SignUp successful", zap.String("trace_id", traceID), zap.String("username", req.Username))
-			c.JSON(http.StatusOK, gin.H{"msg": resp.Msg})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": resp.Msg})
-		}
+		logger.Info("SignUp successful", zap.String("trace_id", traceID), zap.String("username", req.Username))
+		c.JSON(http.StatusOK, gin.H{"msg": resp.Msg})
+	}
+}
+
+// loginLockout 返回当前生效的登录暴力破解防护配置，未配置时不启用
+func loginLockout() *config.LoginLockout {
+	cfg := config.Get().RateLimit
+	if cfg == nil || !cfg.Enabled || cfg.LoginLockout == nil || cfg.LoginLockout.MaxFailures <= 0 {
+		return nil
 	}
+	return cfg.LoginLockout
 }
 
 func LoginHandler(client pb.UserServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		traceID := c.GetString("trace_id") // 从上下文获取 trace_id
+		lockout := loginLockout()
 
 		// 前端请求结构体
 		var req struct {
@@ -75,10 +85,22 @@ func LoginHandler(client pb.UserServiceClient) gin.HandlerFunc {
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			logger.Warn("Invalid request", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			response.BadRequest(c, err.Error())
 			return
 		}
 
+		// 用户名处于锁定期内直接拒绝，不再校验密码
+		if lockout != nil {
+			ttl, err := redis.LoginLockTTL(req.Username)
+			if err != nil {
+				logger.Error("Failed to check login lock", zap.String("trace_id", traceID), zap.String("username", req.Username), zap.Error(err))
+			} else if ttl > 0 {
+				logger.Warn("Login locked", zap.String("trace_id", traceID), zap.String("username", req.Username), zap.Duration("ttl", ttl))
+				middleware.AbortTooManyRequests(c, ttl, "登录失败次数过多，请稍后再试")
+				return
+			}
+		}
+
 		// 构造 gRPC 请求
 		grpcReq := &pb.LoginRequest{
 			Username: req.Username,
@@ -89,27 +111,37 @@ func LoginHandler(client pb.UserServiceClient) gin.HandlerFunc {
 		// 调用 gRPC 服务
 		resp, err := client.Login(c.Request.Context(), grpcReq)
 		if err != nil {
-			logger.Error("Failed to call user-service", zap.String("trace_id", traceID), zap.String("username", req.Username), zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			if errcode.ReasonOf(err) == reasonInvalidCredentials && lockout != nil {
+				lockFor, lockErr := redis.RecordLoginFailure(req.Username, lockout.MaxFailures, lockout.Window, lockout.LockDuration)
+				if lockErr != nil {
+					logger.Error("Failed to record login failure", zap.String("trace_id", traceID), zap.String("username", req.Username), zap.Error(lockErr))
+				} else if lockFor > 0 {
+					logger.Warn("Login locked after repeated failures", zap.String("trace_id", traceID), zap.String("username", req.Username))
+					middleware.AbortTooManyRequests(c, lockFor, "登录失败次数过多，请稍后再试")
+					return
+				}
+			}
+			logger.Warn("Login failed", zap.String("trace_id", traceID), zap.String("username", req.Username), zap.Error(err))
+			response.GRPCError(c, err)
 			return
 		}
 
-		// 根据业务码处理响应
-		switch resp.Code {
-		case 0:
-			logger.Info("Login successful", zap.String("trace_id", traceID), zap.String("username", req.Username))
-			c.JSON(http.StatusOK, gin.H{
-				"code":          resp.Code,
-				"msg":           resp.Msg,
-				"user_id":       fmt.Sprintf("%d", resp.UserId),
-				"user_name":     resp.Username,
-				"access_token":  resp.AccessToken,
-				"refresh_token": resp.RefreshToken,
-			})
-		default:
-			logger.Warn("Login failed", zap.String("trace_id", traceID), zap.String("username", req.Username), zap.String("msg", resp.Msg))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": resp.Msg})
+		logger.Info("Login successful", zap.String("trace_id", traceID), zap.String("username", req.Username))
+		if lockout != nil {
+			if err := redis.ClearLoginFailures(req.Username); err != nil {
+				logger.Error("Failed to clear login failures", zap.String("trace_id", traceID), zap.String("username", req.Username), zap.Error(err))
+			}
 		}
+		// user_id 以字符串返回（LoginResponse 中即为字符串），避免前端按 float64 解析雪花 ID 丢失精度
+		c.JSON(http.StatusOK, gin.H{
+			"code":          resp.Code,
+			"msg":           resp.Msg,
+			"user_id":       resp.UserId,
+			"user_name":     resp.Username,
+			"role":          resp.Role,
+			"access_token":  resp.AccessToken,
+			"refresh_token": resp.RefreshToken,
+		})
 	}
 }
 
@@ -123,8 +155,7 @@ func RefreshTokenHandler(client pb.UserServiceClient) gin.HandlerFunc {
 		refreshToken := c.Query("refresh_token")
 		if refreshToken == "" {
 			logger.Warn("Missing refresh_token", zap.String("trace_id", traceID))
-			c.JSON(http.StatusUnauthorized, gin.H{"msg": "缺少 refresh_token 参数"})
-			c.Abort()
+			response.Unauthorized(c, "缺少 refresh_token 参数")
 			return
 		}
 
@@ -132,16 +163,14 @@ func RefreshTokenHandler(client pb.UserServiceClient) gin.HandlerFunc {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			logger.Warn("Missing Authorization header", zap.String("trace_id", traceID))
-			c.JSON(http.StatusUnauthorized, gin.H{"msg": "请求头缺少 Authorization"})
-			c.Abort()
+			response.Unauthorized(c, "请求头缺少 Authorization")
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" {
 			logger.Warn("Invalid Authorization format", zap.String("trace_id", traceID), zap.String("header", authHeader))
-			c.JSON(http.StatusUnauthorized, gin.H{"msg": "Authorization 格式错误，应为 Bearer <token>"})
-			c.Abort()
+			response.Unauthorized(c, "Authorization 格式错误，应为 Bearer <token>")
 			return
 		}
 		accessToken := parts[1]
@@ -154,18 +183,10 @@ func RefreshTokenHandler(client pb.UserServiceClient) gin.HandlerFunc {
 		logger.Info("Calling user-service RefreshToken", zap.String("trace_id", traceID))
 
 		// 调用 gRPC 服务
-		ctx := context.WithValue(c.Request.Context(), "trace_id", traceID)
-		resp, err := client.RefreshToken(ctx, grpcReq)
+		resp, err := client.RefreshToken(c.Request.Context(), grpcReq)
 		if err != nil {
 			logger.Error("Failed to call RefreshToken", zap.String("trace_id", traceID), zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "服务器错误"})
-			return
-		}
-
-		// 处理响应
-		if resp.Code != 0 {
-			logger.Warn("Refresh token failed", zap.String("trace_id", traceID), zap.String("msg", resp.Msg))
-			c.JSON(http.StatusUnauthorized, gin.H{"code": int(resp.Code), "msg": resp.Msg})
+			response.GRPCError(c, err)
 			return
 		}
 
@@ -177,3 +198,39 @@ func RefreshTokenHandler(client pb.UserServiceClient) gin.HandlerFunc {
 
 	}
 }
+
+// SetUserRoleHandler 管理员修改用户角色
+func SetUserRoleHandler(client pb.UserServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id")
+
+		var req struct {
+			UserID uint64 `json:"user_id,string" binding:"required"`
+			Role   string `json:"role" binding:"required,oneof=user moderator admin banned"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Warn("Invalid request", zap.String("trace_id", traceID), zap.Error(err))
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		operatorID := c.GetUint64(middleware.ContextUserIDKey)
+		grpcReq := &pb.SetUserRoleRequest{
+			UserId:     req.UserID,
+			Role:       req.Role,
+			OperatorId: operatorID,
+		}
+		logger.Info("Calling user-service SetUserRole", zap.String("trace_id", traceID),
+			zap.Uint64("user_id", req.UserID), zap.String("role", req.Role), zap.Uint64("operator_id", operatorID))
+
+		resp, err := client.SetUserRole(c.Request.Context(), grpcReq)
+		if err != nil {
+			logger.Error("Failed to call user-service", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+		middleware.InvalidateRole(req.UserID)
+
+		c.JSON(http.StatusOK, gin.H{"code": resp.Code, "msg": resp.Msg})
+	}
+}
@@ -2,55 +2,67 @@ package middleware
 
 import (
 	"fmt"
-	"net/http"
 	"strings"
 
+	"bluebell_microservices/bff/internal/response"
 	"bluebell_microservices/common/pkg/jwt"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/rbac"
+	pb "bluebell_microservices/proto/user"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
 )
 
 const (
 	ContextUserIDKey = "userID"
+	ContextRoleKey   = "role"
 )
 
 // JWTAuthMiddleware 基于JWT的认证中间件
-// 中间件 主要验证 Access Token 是否有效
-func JWTAuthMiddleware() func(c *gin.Context) {
+// 中间件 主要验证 Access Token 是否有效；角色以 user-service 中的当前角色为准，不使用 token 中签发时的角色
+func JWTAuthMiddleware(client pb.UserServiceClient) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		authHeader := c.Request.Header.Get("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "请求头缺少Auth Token",
-			})
-			c.Abort()
+			response.Unauthorized(c, "请求头缺少Auth Token")
 			return
 		}
 		// 按空格分割
 		//&& parts[0] == "Bearer"
 		parts := strings.SplitN(authHeader, " ", 2)
 		if !(len(parts) == 2) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "Token格式不对",
-			})
-			c.Abort()
+			response.Unauthorized(c, "Token格式不对")
 			return
 		}
 		// parts[1]是获取到的tokenString，我们使用之前定义好的解析JWT的函数来解析它
 		mc, err := jwt.ParseToken(parts[1])
 		if err != nil {
 			fmt.Println(err)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "无效的Token",
-			})
-			c.Abort()
+			response.Unauthorized(c, "无效的Token")
+			return
+		}
+		// 查询当前角色，角色修改（如封禁）无需等用户重新登录即可生效
+		role, err := currentRole(c.Request.Context(), client, mc.UserID)
+		if err != nil {
+			logger.Warn("Failed to get current role", zap.String("trace_id", c.GetString("trace_id")),
+				zap.Uint64("user_id", mc.UserID), zap.Error(err))
+			response.GRPCError(c, err)
 			return
 		}
 		// 将当前请求的userID信息保存到请求的上下文c上
 		c.Set(ContextUserIDKey, mc.UserID)
+		c.Set(ContextRoleKey, role)
+		// 以当前角色签发短期 token 转发给下游服务，供服务端鉴权拦截器校验
+		serviceToken, err := jwt.GenServiceToken(mc.UserID, mc.Username, role)
+		if err != nil {
+			logger.Error("Failed to generate service token", zap.Uint64("user_id", mc.UserID), zap.Error(err))
+			response.Internal(c)
+			return
+		}
+		c.Request = c.Request.WithContext(metadata.AppendToOutgoingContext(c.Request.Context(),
+			rbac.MetadataAuthorizationKey, "Bearer "+serviceToken))
 		c.Next() // 后续的处理函数可以用过c.Get(ContextUserIDKey)来获取当前请求的用户信息
 	}
 }
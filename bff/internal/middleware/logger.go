@@ -2,12 +2,14 @@ package middleware
 
 import (
 	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/tracing"
 	"bytes"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"go.uber.org/zap"
 )
 
@@ -28,6 +30,9 @@ BFF（API 服务）：
 3. LoggerMiddleware() gin.HandlerFunc 统一记录所有 HTTP 请求的日志，Handler 中只记录特定业务逻辑。
 */
 
+// maxLoggedBody 记录请求体的最大长度
+const maxLoggedBody = 4 << 10
+
 // LoggerMiddleware 创建日志中间件
 func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -37,20 +42,25 @@ func LoggerMiddleware() gin.HandlerFunc {
 		// 获取请求信息
 		method := c.Request.Method
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := logger.RedactQuery(c.Request.URL.RawQuery)
 		clientIP := c.ClientIP()
 		userAgent := c.Request.UserAgent()
 
-		// 生成或获取 trace_id
-		traceID := c.GetHeader("X-Trace-ID")
+		// trace_id 取自 otelgin 创建的 span（上游带 traceparent 时沿用其 trace），与下游服务日志一致
+		traceID := tracing.TraceID(c.Request.Context())
+		if traceID == "" {
+			traceID = c.GetHeader("X-Trace-ID")
+		}
 		if traceID == "" {
-			traceID = fmt.Sprintf("%d", time.Now().UnixNano()) // 简单生成，生产中可用 UUID
+			traceID = fmt.Sprintf("%d", time.Now().UnixNano()) // 未开启追踪时的兜底
 		}
-		c.Set("trace_id", traceID) // 存入上下文，供 Handler 使用
+		c.Set("trace_id", traceID)      // 存入上下文，供 Handler 使用
+		c.Header("X-Trace-ID", traceID) // 返回给客户端，便于排查
 
-		// 读取请求体（可选）
+		// 读取 JSON 请求体（可选），过大的请求体不记录
 		var bodyBytes []byte
-		if c.Request.Body != nil {
+		if c.Request.Body != nil && c.ContentType() == binding.MIMEJSON &&
+			c.Request.ContentLength > 0 && c.Request.ContentLength <= maxLoggedBody {
 			bodyBytes, _ = io.ReadAll(c.Request.Body)
 			// 恢复请求体，供后续 Handler 使用
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
@@ -77,9 +87,9 @@ func LoggerMiddleware() gin.HandlerFunc {
 			zap.Duration("latency", latency),
 		}
 
-		// 可选：记录请求体
+		// 可选：记录请求体，密码等敏感字段脱敏
 		if len(bodyBytes) > 0 {
-			fields = append(fields, zap.String("body", string(bodyBytes)))
+			fields = append(fields, zap.String("body", logger.RedactJSON(bodyBytes)))
 		}
 
 		// 根据状态码记录不同级别的日志
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"strconv"
+
+	"bluebell_microservices/bff/internal/response"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/rbac"
+	pb "bluebell_microservices/proto/post"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	ContextCommunityIDKey = "communityID"
+)
+
+// CommunityModeratorMiddleware 校验当前用户是路由中社区(:id)的版主，需放在 JWTAuthMiddleware 之后
+func CommunityModeratorMiddleware(client pb.PostServiceClient) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		traceID := c.GetString("trace_id")
+
+		userID, ok := c.Get(ContextUserIDKey)
+		if !ok {
+			response.Unauthorized(c, "请先登录")
+			return
+		}
+
+		communityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || communityID <= 0 {
+			response.BadRequest(c, "社区ID格式错误")
+			return
+		}
+
+		// 全站版主和管理员可管理所有社区
+		if rbac.HasPermission(c.GetString(ContextRoleKey), rbac.PermModerateAll) {
+			c.Set(ContextCommunityIDKey, communityID)
+			c.Next()
+			return
+		}
+
+		// 通过 post-service 查询用户在社区中的身份
+		resp, err := client.GetCommunityRole(c.Request.Context(), &pb.GetCommunityRoleRequest{
+			UserId:      int64(userID.(uint64)),
+			CommunityId: communityID,
+		})
+		if err != nil {
+			logger.Error("Failed to get community role", zap.String("trace_id", traceID), zap.Error(err))
+			response.GRPCError(c, err)
+			return
+		}
+		if !resp.IsModerator {
+			logger.Warn("Non-moderator tried moderation route",
+				zap.String("trace_id", traceID),
+				zap.Any("user_id", userID),
+				zap.Int64("community_id", communityID))
+			response.Forbidden(c, "需要版主权限")
+			return
+		}
+
+		c.Set(ContextCommunityIDKey, communityID)
+		c.Next()
+	}
+}
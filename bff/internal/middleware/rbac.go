@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"bluebell_microservices/bff/internal/response"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/rbac"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequireRole 要求当前用户属于指定角色之一，需放在 JWTAuthMiddleware 之后
+func RequireRole(roles ...string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		role := c.GetString(ContextRoleKey)
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		forbidden(c, role, "需要角色: "+joinRoles(roles))
+	}
+}
+
+// RequirePermission 要求当前用户的角色拥有指定权限，需放在 JWTAuthMiddleware 之后
+func RequirePermission(perm rbac.Permission) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		role := c.GetString(ContextRoleKey)
+		if !rbac.HasPermission(role, perm) {
+			forbidden(c, role, "权限不足")
+			return
+		}
+		c.Next()
+	}
+}
+
+func forbidden(c *gin.Context, role, msg string) {
+	logger.Warn("Permission denied",
+		zap.String("trace_id", c.GetString("trace_id")),
+		zap.Any("user_id", c.Value(ContextUserIDKey)),
+		zap.String("role", role),
+		zap.String("path", c.FullPath()))
+	response.Forbidden(c, msg)
+}
+
+func joinRoles(roles []string) string {
+	s := ""
+	for i, r := range roles {
+		if i > 0 {
+			s += ","
+		}
+		s += r
+	}
+	return s
+}
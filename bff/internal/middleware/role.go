@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/rbac"
+	pb "bluebell_microservices/proto/user"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// 用户当前角色的本地缓存：修改角色后最多经过 roleCacheTTL 在所有 BFF 实例上生效
+const (
+	roleCacheSize = 10000
+	roleCacheTTL  = 30 * time.Second
+	roleTimeout   = 2 * time.Second
+)
+
+// ErrUserNotFound token 对应的用户已不存在
+var ErrUserNotFound = errcode.Unauthenticated("USER_NOT_FOUND", "用户不存在")
+
+var roleCache = expirable.NewLRU[uint64, string](roleCacheSize, nil, roleCacheTTL)
+
+// currentRole 查询用户当前角色，优先读取本地缓存，未命中时调用 user-service
+func currentRole(ctx context.Context, client pb.UserServiceClient, userID uint64) (string, error) {
+	if role, ok := roleCache.Get(userID); ok {
+		return role, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, roleTimeout)
+	defer cancel()
+	resp, err := client.GetUsersByIDs(ctx, &pb.GetUsersByIDsRequest{UserIds: []uint64{userID}})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Users) == 0 {
+		return "", ErrUserNotFound
+	}
+	role := rbac.Normalize(resp.Users[0].Role)
+	roleCache.Add(userID, role)
+	return role, nil
+}
+
+// InvalidateRole 修改用户角色后删除本实例的缓存，其他 BFF 实例在缓存过期后生效
+func InvalidateRole(userID uint64) {
+	roleCache.Remove(userID)
+}
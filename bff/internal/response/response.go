@@ -0,0 +1,124 @@
+package response
+
+import (
+	"net/http"
+
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+// StatusClientClosedRequest 客户端主动断开连接（nginx 约定的 499）
+const StatusClientClosedRequest = 499
+
+// ErrorBody 统一的错误响应结构
+type ErrorBody struct {
+	Code    string   `json:"code"`
+	Message string   `json:"message"`
+	Details []Detail `json:"details,omitempty"`
+	TraceID string   `json:"trace_id"`
+}
+
+// Detail 错误详情：字段校验错误或下游附带的元数据
+type Detail struct {
+	Field    string            `json:"field,omitempty"`
+	Reason   string            `json:"reason,omitempty"`
+	Message  string            `json:"message,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Error 以统一结构返回错误并中止后续处理
+func Error(c *gin.Context, httpStatus int, code, message string, details ...Detail) {
+	c.AbortWithStatusJSON(httpStatus, ErrorBody{
+		Code:    code,
+		Message: message,
+		Details: details,
+		TraceID: c.GetString("trace_id"),
+	})
+}
+
+// BadRequest 请求参数错误
+func BadRequest(c *gin.Context, message string) {
+	Error(c, http.StatusBadRequest, errcode.ReasonInvalidArgument, message)
+}
+
+// Unauthorized 未登录或凭证无效
+func Unauthorized(c *gin.Context, message string) {
+	Error(c, http.StatusUnauthorized, errcode.ReasonUnauthenticated, message)
+}
+
+// Forbidden 权限不足
+func Forbidden(c *gin.Context, message string) {
+	Error(c, http.StatusForbidden, errcode.ReasonPermissionDenied, message)
+}
+
+// Internal BFF 自身的内部错误
+func Internal(c *gin.Context) {
+	Error(c, http.StatusInternalServerError, errcode.ReasonInternal, errcode.ErrInternal.Message())
+}
+
+// GRPCError 将下游 gRPC 错误翻译为 HTTP 状态及统一结构
+func GRPCError(c *gin.Context, err error) {
+	info := errcode.FromError(err)
+	httpStatus := HTTPStatus(info.Code)
+
+	message := info.Message
+	if httpStatus >= http.StatusInternalServerError {
+		// 5xx 不向客户端暴露下游的原始错误信息
+		logger.Error("Downstream service error",
+			zap.String("trace_id", c.GetString("trace_id")),
+			zap.String("code", info.Code.String()),
+			zap.String("reason", info.Reason),
+			zap.Error(err))
+		if info.Code != codes.Unavailable && info.Code != codes.DeadlineExceeded {
+			message = errcode.ErrInternal.Message()
+		}
+	}
+
+	var details []Detail
+	for _, v := range info.FieldViolations {
+		details = append(details, Detail{
+			Field:   v.GetField(),
+			Reason:  v.GetReason(),
+			Message: v.GetDescription(),
+		})
+	}
+	if len(info.Metadata) > 0 {
+		details = append(details, Detail{Metadata: info.Metadata})
+	}
+
+	Error(c, httpStatus, info.Reason, message, details...)
+}
+
+// HTTPStatus gRPC 状态码到 HTTP 状态码的映射
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return StatusClientClosedRequest
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,62 @@
+// Package app 组装 comment-service 的 gRPC 服务，供 cmd/server 及端到端测试在进程内启动
+package app
+
+import (
+	"context"
+
+	"bluebell_microservices/comment-service/internal/client"
+	"bluebell_microservices/comment-service/internal/controller"
+	"bluebell_microservices/comment-service/internal/dao/memory"
+	"bluebell_microservices/comment-service/internal/dao/mysql"
+	"bluebell_microservices/comment-service/internal/dao/redis"
+	"bluebell_microservices/comment-service/internal/kafka"
+	"bluebell_microservices/comment-service/internal/logic"
+	"bluebell_microservices/common/config"
+	commonkafka "bluebell_microservices/common/pkg/kafka"
+	"bluebell_microservices/common/pkg/rbac"
+	pb "bluebell_microservices/proto/comment"
+	postpb "bluebell_microservices/proto/post"
+
+	"google.golang.org/grpc"
+)
+
+// ServerOptions comment-service 自身的 gRPC 选项
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(rbac.UnaryServerInterceptor(controller.AccessRules)), // 敏感 RPC 鉴权
+	}
+}
+
+// Register 注册使用 MySQL 存储的 CommentService，需先初始化 mysql、redis、kafka 及 client
+func Register(s *grpc.Server) {
+	pb.RegisterCommentServiceServer(s, controller.NewCommentController(
+		logic.NewCommentLogic(mysql.NewCommentDAO(), redis.NewEventDAO(), kafka.NewProducer(), client.NewPostClient(client.PostService()))))
+}
+
+// Memory 评论使用内存存储，帖子实时事件发布到 Redis（可以是 miniredis），评论事件同步转发给 post-service
+type Memory struct {
+	comments *memory.CommentStore
+	producer *memory.Producer
+	posts    *client.PostClient
+}
+
+// NewMemory 连接 Redis 并创建内存版 comment-service；版主身份通过 posts 查询，forward 代替 Kafka 接收评论创建、删除事件，可为 nil
+func NewMemory(conf *config.Redis, posts postpb.PostServiceClient, forward func(ctx context.Context, msg commonkafka.CommentMessage) error) (*Memory, error) {
+	if err := redis.Init(conf); err != nil {
+		return nil, err
+	}
+	producer := memory.NewProducer()
+	producer.Forward = forward
+	return &Memory{comments: memory.NewCommentStore(), producer: producer, posts: client.NewPostClient(posts)}, nil
+}
+
+// Register 注册 CommentService
+func (m *Memory) Register(s *grpc.Server) {
+	pb.RegisterCommentServiceServer(s, controller.NewCommentController(
+		logic.NewCommentLogic(m.comments, redis.NewEventDAO(), m.producer, m.posts)))
+}
+
+// Close 关闭 Redis 连接
+func (m *Memory) Close() {
+	redis.Close()
+}
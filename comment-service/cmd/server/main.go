@@ -3,114 +3,96 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
 	"log"
-	"net"
-	"time"
+	"os"
 
-	"bluebell_microservices/comment-service/internal/controller"
+	"bluebell_microservices/comment-service/app"
+	"bluebell_microservices/comment-service/internal/client"
 	"bluebell_microservices/comment-service/internal/dao/mysql"
+	"bluebell_microservices/comment-service/internal/dao/redis"
+	"bluebell_microservices/comment-service/internal/kafka"
+	"bluebell_microservices/comment-service/migrations"
 	"bluebell_microservices/common/config"
-	"bluebell_microservices/common/pkg/logger"
-	"bluebell_microservices/common/pkg/snowflake"
-	pb "bluebell_microservices/proto/comment"
-
-	clientv3 "go.etcd.io/etcd/client/v3"
-	"go.uber.org/zap"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
+	"bluebell_microservices/common/pkg/metrics"
+	"bluebell_microservices/common/pkg/migrate"
+	"bluebell_microservices/common/pkg/server"
 )
 
 func main() {
 	flag.Parse()
-
-	// 初始化日志
-	if err := logger.Init("info", "comment-service.log"); err != nil {
-		log.Fatalf("Failed to init logger: %v", err)
-	}
-	defer logger.Logger.Sync() // 确保日志在程序退出时写入
-
-	// 初始化配置
-	config.InitConfig()
-
-	// 初始化雪花算法
-	if err := snowflake.Init(3); err != nil {
-		log.Fatalf("init snowflake failed, err:%v\n", err)
-	}
-
-	// 初始化数据库连接
-	if err := mysql.Init(config.Conf.MySQL); err != nil {
-		log.Fatalf("init mysql failed, err:%v\n", err)
+	if flag.Arg(0) == "migrate" {
+		runMigrate(flag.Args()[1:])
+		return
 	}
-	defer mysql.Close()
 
-	// 初始化 etcd 客户端
-	etcdEndpoints := []string{"etcd-container:2379"}
-	cli, err := clientv3.New(clientv3.Config{
-		Endpoints:   etcdEndpoints,
-		DialTimeout: 5 * time.Second,
-	})
+	// 初始化日志、配置及雪花算法
+	srv, err := server.New("comment", app.ServerOptions()...)
 	if err != nil {
-		log.Fatalf("连接 etcd 失败: %v", err)
+		log.Fatalf("init comment service failed, err:%v\n", err)
 	}
-	defer cli.Close()
 
-	// 服务注册
-	if err := registerService(cli, "comment", "comment-service:8083"); err != nil {
-		logger.Error("Failed to register service", zap.Error(err))
-		log.Fatalf("failed to register service: %v", err)
+	// 初始化数据库连接、Redis（用于推送帖子实时事件）及 Kafka 生产者（评论事件），退出时按相反顺序关闭
+	if err := srv.Use(
+		server.Component{
+			Name: "mysql",
+			Init: func(conf *config.Config) error {
+				if err := mysql.Init(conf.MySQL); err != nil {
+					return err
+				}
+				// 按需执行迁移，表结构版本与代码不一致时拒绝启动
+				if err := migrate.Ensure(context.Background(), mysql.DB().DB, "comment", migrations.FS, conf.MySQL.AutoMigrate); err != nil {
+					return err
+				}
+				return metrics.RegisterDBStats("comment", mysql.DB().DB)
+			},
+			Close: func() error { mysql.Close(); return nil },
+			Check: mysql.Ping,
+		},
+		server.Component{
+			Name: "redis",
+			Init: func(conf *config.Config) error {
+				if err := redis.Init(conf.Redis); err != nil {
+					return err
+				}
+				return metrics.RegisterRedisPoolStats("comment", redis.Client())
+			},
+			Close: func() error { redis.Close(); return nil },
+			Check: redis.Ping,
+		},
+		server.Component{
+			Name:  "kafka-producer", // 评论事件发送失败不影响评论，帖子评论数由 post-service 定时修正，不参与就绪判断
+			Init:  kafka.Init,
+			Close: kafka.Close,
+		},
+		server.Component{
+			Name:  "clients", // 删除评论时通过 post-service 校验版主身份
+			Init:  client.Init,
+			Close: client.Close,
+		},
+	); err != nil {
+		srv.Close()
+		log.Fatalf("init comment service failed, err:%v\n", err)
 	}
 
-	// 监听端口
-	lis, err := net.Listen("tcp", ":8083")
-	if err != nil {
-		logger.Error("Failed to listen", zap.Error(err))
-		log.Fatalf("failed to listen: %v", err)
-	}
-	defer lis.Close()
-
-	// 创建 gRPC 服务器
-	s := grpc.NewServer()
-
 	// 注册微服务
-	pb.RegisterCommentServiceServer(s, controller.NewCommentController())
-
-	// 注册反射服务
-	reflection.Register(s) // 添加这行
+	app.Register(srv.GRPC())
 
-	logger.Info("Comment service running", zap.String("addr", ":8083"))
-	if err := s.Serve(lis); err != nil {
+	if err := srv.Run(); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
 
-func registerService(cli *clientv3.Client, serviceName, address string) error {
-	// 创建租约
-	leaseResp, err := cli.Grant(context.Background(), 10)
-	if err != nil {
-		return fmt.Errorf("创建租约失败: %v", err)
+// runMigrate 执行 migrate 子命令后退出，只需要 MySQL 配置
+func runMigrate(args []string) {
+	if err := config.InitConfig("comment"); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
 	}
-
-	// 注册服务
-	key := fmt.Sprintf("/services/%s", serviceName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	_, err = cli.Put(ctx, key, address, clientv3.WithLease(leaseResp.ID))
-	cancel()
-	if err != nil {
-		return fmt.Errorf("注册服务失败: %v", err)
+	if err := mysql.Init(config.Conf.MySQL); err != nil {
+		log.Fatalf("init mysql failed, err:%v\n", err)
 	}
-	fmt.Printf("服务 %s 注册成功，地址: %s\n", serviceName, address)
+	defer mysql.Close()
 
-	// 续约
-	keepAliveChan, err := cli.KeepAlive(context.Background(), leaseResp.ID)
-	if err != nil {
-		return fmt.Errorf("续约失败: %v", err)
+	if err := migrate.Command(context.Background(), mysql.DB().DB, "comment", migrations.FS, args, os.Stdout); err != nil {
+		log.Fatalf("migrate failed: %v", err)
 	}
-	go func() {
-		for range keepAliveChan {
-		}
-		fmt.Println("续约结束，租约已失效")
-	}()
-
-	return nil
 }
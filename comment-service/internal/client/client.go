@@ -0,0 +1,61 @@
+// Package client comment-service 调用其他微服务的客户端
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/registry"
+	"bluebell_microservices/common/pkg/tracing"
+	pb "bluebell_microservices/proto/post"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var (
+	etcdClient *clientv3.Client
+	postConn   *grpc.ClientConn
+)
+
+// Init 通过 etcd 发现 post-service，连接在首次调用时建立
+func Init(conf *config.Config) error {
+	var err error
+	etcdClient, err = clientv3.New(clientv3.Config{
+		Endpoints:   conf.Etcd.Endpoints(),
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("连接 etcd 失败: %v", err)
+	}
+
+	postConn, err = grpc.NewClient("etcd://post",
+		grpc.WithResolvers(registry.NewEtcdResolverBuilder(etcdClient)),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`),
+		tracing.DialOption(),
+	)
+	if err != nil {
+		Close()
+		return fmt.Errorf("连接 post 服务失败: %v", err)
+	}
+	return nil
+}
+
+// PostService 返回 post-service 客户端，需先调用 Init
+func PostService() pb.PostServiceClient {
+	return pb.NewPostServiceClient(postConn)
+}
+
+// Close 关闭连接
+func Close() error {
+	if postConn != nil {
+		postConn.Close()
+	}
+	if etcdClient != nil {
+		return etcdClient.Close()
+	}
+	return nil
+}
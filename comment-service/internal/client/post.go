@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	pb "bluebell_microservices/proto/post"
+)
+
+// postTimeout 查询版主身份的超时
+const postTimeout = 2 * time.Second
+
+// PostClient 通过 PostService.GetCommunityRole 查询用户在帖子所在社区的身份
+type PostClient struct {
+	posts pb.PostServiceClient
+}
+
+// NewPostClient 创建版主身份查询，不缓存，版主任免立即生效
+func NewPostClient(posts pb.PostServiceClient) *PostClient {
+	return &PostClient{posts: posts}
+}
+
+// IsModerator 用户是否为帖子所在社区的版主
+func (c *PostClient) IsModerator(ctx context.Context, userID, postID uint64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+
+	resp, err := c.posts.GetCommunityRole(ctx, &pb.GetCommunityRoleRequest{UserId: int64(userID), PostId: int64(postID)})
+	if err != nil {
+		return false, err
+	}
+	return resp.IsModerator, nil
+}
@@ -3,7 +3,9 @@ package controller
 import (
 	"bluebell_microservices/comment-service/internal/logic"
 	"bluebell_microservices/comment-service/internal/model"
+	"bluebell_microservices/common/pkg/errcode"
 	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/rbac"
 	"bluebell_microservices/common/pkg/snowflake"
 	pb "bluebell_microservices/proto/comment"
 	"context"
@@ -17,14 +19,14 @@ type CommentController struct {
 	commentLogic *logic.CommentLogic
 }
 
-func NewCommentController() *CommentController {
+func NewCommentController(commentLogic *logic.CommentLogic) *CommentController {
 	return &CommentController{
-		commentLogic: logic.NewCommentLogic(),
+		commentLogic: commentLogic,
 	}
 }
 
 func (c *CommentController) CreateComment(ctx context.Context, req *pb.CreateCommentRequest) (*pb.CreateCommentResponse, error) {
-	logger.Info("Received CreateComment request",
+	logger.Ctx(ctx).Info("Received CreateComment request",
 		zap.Uint64("post_id", req.PostId),
 		zap.Uint64("author_id", req.AuthorId),
 		zap.String("content", req.Content))
@@ -34,8 +36,8 @@ func (c *CommentController) CreateComment(ctx context.Context, req *pb.CreateCom
 	// 生成评论ID
 	commentID, err := snowflake.GetID()
 	if err != nil {
-		logger.Error("snowflake.GetID() failed", zap.Error(err))
-		return nil, err
+		logger.Ctx(ctx).Error("snowflake.GetID() failed", zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 	comment.CommentID = commentID
 
@@ -44,17 +46,18 @@ func (c *CommentController) CreateComment(ctx context.Context, req *pb.CreateCom
 	comment.ParentID = req.ParentId
 	comment.AuthorID = req.AuthorId
 	comment.Content = req.Content
+	comment.Status = model.CommentStatusNormal
 	comment.CreateTime = time.Now()
 
-	logger.Info("Creating comment",
+	logger.Ctx(ctx).Info("Creating comment",
 		zap.Uint64("comment_id", comment.CommentID),
 		zap.Uint64("post_id", comment.PostID),
 		zap.Uint64("author_id", comment.AuthorID))
 
 	err = c.commentLogic.CreateComment(ctx, &comment)
 	if err != nil {
-		logger.Error("Failed to create comment", zap.Error(err))
-		return nil, err
+		logger.Ctx(ctx).Error("Failed to create comment", zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 
 	return &pb.CreateCommentResponse{
@@ -64,13 +67,13 @@ func (c *CommentController) CreateComment(ctx context.Context, req *pb.CreateCom
 }
 
 func (c *CommentController) GetCommentList(ctx context.Context, req *pb.GetCommentListRequest) (*pb.GetCommentListResponse, error) {
-	logger.Info("Received GetCommentList request",
+	logger.Ctx(ctx).Info("Received GetCommentList request",
 		zap.Uint64("post_id", req.PostId))
 
 	comments, err := c.commentLogic.GetCommentList(ctx, req.PostId)
 	if err != nil {
-		logger.Error("Failed to get comment list", zap.Error(err))
-		return nil, err
+		logger.Ctx(ctx).Error("Failed to get comment list", zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 
 	// 转换评论列表
@@ -92,3 +95,61 @@ func (c *CommentController) GetCommentList(ctx context.Context, req *pb.GetComme
 		Comments: pbComments,
 	}, nil
 }
+
+func (c *CommentController) GetComment(ctx context.Context, req *pb.GetCommentRequest) (*pb.GetCommentResponse, error) {
+	logger.Ctx(ctx).Info("Received GetComment request", zap.Uint64("comment_id", req.CommentId))
+
+	comment, err := c.commentLogic.GetComment(ctx, req.CommentId)
+	if err != nil {
+		logger.Ctx(ctx).Warn("Failed to get comment", zap.Uint64("comment_id", req.CommentId), zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+
+	return &pb.GetCommentResponse{
+		Code:    0,
+		Message: "获取评论成功",
+		Comment: &pb.Comment{
+			CommentId:  comment.CommentID,
+			PostId:     comment.PostID,
+			ParentId:   comment.ParentID,
+			AuthorId:   comment.AuthorID,
+			Content:    comment.Content,
+			CreateTime: comment.CreateTime.Format("2006-01-02 15:04:05"),
+		},
+	}, nil
+}
+
+func (c *CommentController) GetCommentCounts(ctx context.Context, req *pb.GetCommentCountsRequest) (*pb.GetCommentCountsResponse, error) {
+	counts, err := c.commentLogic.GetCommentCounts(ctx, req.PostIds)
+	if err != nil {
+		logger.Ctx(ctx).Warn("Failed to get comment counts", zap.Int("count", len(req.PostIds)), zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+
+	return &pb.GetCommentCountsResponse{
+		Code:    0,
+		Message: "获取评论数成功",
+		Counts:  counts,
+	}, nil
+}
+
+func (c *CommentController) RemoveComment(ctx context.Context, req *pb.RemoveCommentRequest) (*pb.RemoveCommentResponse, error) {
+	logger.Ctx(ctx).Info("Received RemoveComment request",
+		zap.Uint64("comment_id", req.CommentId),
+		zap.Uint64("operator_id", req.OperatorId))
+
+	claims, ok := rbac.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, errcode.ErrUnauthenticated.WithMessage("missing caller identity")
+	}
+	err := c.commentLogic.RemoveComment(ctx, req.CommentId, req.OperatorId, claims.Role)
+	if err != nil {
+		logger.Ctx(ctx).Warn("Failed to remove comment", zap.Uint64("comment_id", req.CommentId), zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+
+	return &pb.RemoveCommentResponse{
+		Code:    0,
+		Message: "删除评论成功",
+	}, nil
+}
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"bluebell_microservices/common/pkg/rbac"
+	pb "bluebell_microservices/proto/comment"
+)
+
+// AccessRules comment-service 中需要鉴权的 RPC，读接口不做限制
+var AccessRules = map[string]rbac.Rule{
+	pb.CommentService_CreateComment_FullMethodName: {
+		Permission: rbac.PermCommentCreate,
+		Subject:    func(req interface{}) uint64 { return req.(*pb.CreateCommentRequest).AuthorId },
+	},
+	// 版主身份在业务层通过 post-service 校验
+	pb.CommentService_RemoveComment_FullMethodName: {
+		Permission: rbac.PermActive,
+		Subject:    func(req interface{}) uint64 { return req.(*pb.RemoveCommentRequest).OperatorId },
+	},
+}
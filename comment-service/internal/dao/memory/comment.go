@@ -0,0 +1,150 @@
+// Package memory 存储接口的内存实现，用于单元测试及本地联调，不持久化
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"bluebell_microservices/comment-service/internal/dao/mysql"
+	"bluebell_microservices/comment-service/internal/model"
+	"bluebell_microservices/common/pkg/event"
+	"bluebell_microservices/common/pkg/kafka"
+)
+
+// CommentStore 评论的内存存储，返回的错误与 mysql.CommentDAO 一致
+type CommentStore struct {
+	mu       sync.RWMutex
+	comments map[uint64]*model.Comment
+}
+
+// NewCommentStore 创建空的评论存储
+func NewCommentStore() *CommentStore {
+	return &CommentStore{comments: make(map[uint64]*model.Comment)}
+}
+
+func (s *CommentStore) CreateComment(ctx context.Context, comment *model.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *comment
+	s.comments[comment.CommentID] = &c
+	return nil
+}
+
+// GetCommentList 帖子下未删除的评论，按创建时间倒序
+func (s *CommentStore) GetCommentList(ctx context.Context, postID uint64) ([]*model.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var list []*model.Comment
+	for _, c := range s.comments {
+		if c.PostID == postID && c.Status == model.CommentStatusNormal {
+			cc := *c
+			list = append(list, &cc)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreateTime.After(list[j].CreateTime) })
+	return list, nil
+}
+
+// GetCommentByID 根据评论id查询评论（包括已删除的评论）
+func (s *CommentStore) GetCommentByID(ctx context.Context, commentID uint64) (*model.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.comments[commentID]
+	if !ok {
+		return nil, mysql.ErrCommentNotFound
+	}
+	cc := *c
+	return &cc, nil
+}
+
+// UpdateCommentStatus 修改评论状态
+func (s *CommentStore) UpdateCommentStatus(ctx context.Context, commentID uint64, status int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.comments[commentID]; ok {
+		c.Status = status
+	}
+	return nil
+}
+
+// CountComments 按帖子统计未删除的评论数
+func (s *CommentStore) CountComments(ctx context.Context, postIDs []uint64) (map[uint64]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	want := make(map[uint64]bool, len(postIDs))
+	for _, id := range postIDs {
+		want[id] = true
+	}
+	counts := make(map[uint64]int64)
+	for _, c := range s.comments {
+		if want[c.PostID] && c.Status == model.CommentStatusNormal {
+			counts[c.PostID]++
+		}
+	}
+	return counts, nil
+}
+
+// EventRecorder 记录发布的帖子事件，供测试断言
+type EventRecorder struct {
+	mu     sync.Mutex
+	events []*event.PostEvent
+	Err    error // 非空时发布返回该错误，用于模拟 Redis 不可用
+}
+
+// NewEventRecorder 创建空的事件记录
+func NewEventRecorder() *EventRecorder {
+	return &EventRecorder{}
+}
+
+// PublishPostEvent 记录帖子实时事件
+func (r *EventRecorder) PublishPostEvent(ev *event.PostEvent) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+	return nil
+}
+
+// Events 已发布的事件
+func (r *EventRecorder) Events() []*event.PostEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*event.PostEvent(nil), r.events...)
+}
+
+// Producer 记录发送的评论事件，代替 Kafka 生产者
+type Producer struct {
+	mu       sync.Mutex
+	messages []kafka.CommentMessage
+	Err      error                                                     // 非空时发送返回该错误，用于模拟 Kafka 不可用
+	Forward  func(ctx context.Context, msg kafka.CommentMessage) error // 非空时同步转发给消费方，用于进程内联调
+}
+
+// NewProducer 创建空的消息记录
+func NewProducer() *Producer {
+	return &Producer{}
+}
+
+// SendCommentMessage 记录评论事件
+func (p *Producer) SendCommentMessage(ctx context.Context, message kafka.CommentMessage) error {
+	if p.Err != nil {
+		return p.Err
+	}
+	p.mu.Lock()
+	p.messages = append(p.messages, message)
+	p.mu.Unlock()
+	if p.Forward != nil {
+		return p.Forward(ctx, message)
+	}
+	return nil
+}
+
+// Messages 已发送的评论事件
+func (p *Producer) Messages() []kafka.CommentMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]kafka.CommentMessage(nil), p.messages...)
+}
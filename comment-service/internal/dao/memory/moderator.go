@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// ModeratorStore 帖子所在社区的版主，代替 post-service
+type ModeratorStore struct {
+	mu         sync.RWMutex
+	moderators map[[2]uint64]bool // {帖子ID, 用户ID}
+}
+
+// NewModeratorStore 创建没有版主的存储
+func NewModeratorStore() *ModeratorStore {
+	return &ModeratorStore{moderators: make(map[[2]uint64]bool)}
+}
+
+// AddModerator 将用户设为帖子所在社区的版主
+func (s *ModeratorStore) AddModerator(postID, userID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.moderators[[2]uint64{postID, userID}] = true
+}
+
+// IsModerator 用户是否为帖子所在社区的版主
+func (s *ModeratorStore) IsModerator(ctx context.Context, userID, postID uint64) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.moderators[[2]uint64{postID, userID}], nil
+}
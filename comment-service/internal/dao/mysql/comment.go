@@ -2,11 +2,16 @@ package mysql
 
 import (
 	"bluebell_microservices/comment-service/internal/model"
+	"bluebell_microservices/common/pkg/errcode"
 	"context"
+	"database/sql"
 
 	"github.com/jmoiron/sqlx"
 )
 
+// ErrCommentNotFound 评论不存在
+var ErrCommentNotFound = errcode.NotFound("COMMENT_NOT_FOUND", "评论不存在")
+
 type CommentDAO struct {
 	db *sqlx.DB
 }
@@ -18,22 +23,65 @@ func NewCommentDAO() *CommentDAO {
 }
 
 func (dao *CommentDAO) CreateComment(ctx context.Context, comment *model.Comment) error {
-	sqlStr := `insert into comment(comment_id, content, post_id, author_id, parent_id, create_time)
-    values(?,?,?,?,?,?)`
+	sqlStr := `insert into comment(comment_id, content, post_id, author_id, parent_id, status, create_time)
+    values(?,?,?,?,?,?,?)`
 	_, err := dao.db.ExecContext(ctx, sqlStr, comment.CommentID, comment.Content, comment.PostID,
-		comment.AuthorID, comment.ParentID, comment.CreateTime)
+		comment.AuthorID, comment.ParentID, comment.Status, comment.CreateTime)
 	return err
 }
 
 func (dao *CommentDAO) GetCommentList(ctx context.Context, postID uint64) ([]*model.Comment, error) {
-	sqlStr := `select comment_id, content, post_id, author_id, parent_id, create_time
+	sqlStr := `select comment_id, content, post_id, author_id, parent_id, status, create_time
 	from comment
-	where post_id = ?
+	where post_id = ? and status = ?
 	order by create_time desc`
 	var commentList []*model.Comment
-	err := dao.db.SelectContext(ctx, &commentList, sqlStr, postID)
+	err := dao.db.SelectContext(ctx, &commentList, sqlStr, postID, model.CommentStatusNormal)
 	if err != nil {
 		return nil, err
 	}
 	return commentList, nil
 }
+
+// GetCommentByID 根据评论id查询评论（包括已删除的评论）
+func (dao *CommentDAO) GetCommentByID(ctx context.Context, commentID uint64) (*model.Comment, error) {
+	sqlStr := `select comment_id, content, post_id, author_id, parent_id, status, create_time
+	from comment
+	where comment_id = ?`
+	comment := new(model.Comment)
+	err := dao.db.GetContext(ctx, comment, sqlStr, commentID)
+	if err == sql.ErrNoRows {
+		return nil, ErrCommentNotFound
+	}
+	return comment, err
+}
+
+// UpdateCommentStatus 修改评论状态
+func (dao *CommentDAO) UpdateCommentStatus(ctx context.Context, commentID uint64, status int32) error {
+	sqlStr := `update comment set status = ? where comment_id = ?`
+	_, err := dao.db.ExecContext(ctx, sqlStr, status, commentID)
+	return err
+}
+
+// CountComments 按帖子统计未删除的评论数
+func (dao *CommentDAO) CountComments(ctx context.Context, postIDs []uint64) (map[uint64]int64, error) {
+	query, args, err := sqlx.In(`select post_id, count(*) as cnt
+	from comment
+	where post_id in (?) and status = ?
+	group by post_id`, postIDs, model.CommentStatusNormal)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		PostID uint64 `db:"post_id"`
+		Count  int64  `db:"cnt"`
+	}
+	if err := dao.db.SelectContext(ctx, &rows, dao.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	counts := make(map[uint64]int64, len(rows))
+	for _, r := range rows {
+		counts[r.PostID] = r.Count
+	}
+	return counts, nil
+}
@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -69,3 +71,11 @@ func Close() {
 func DB() *sqlx.DB {
 	return db
 }
+
+// Ping 检查数据库连接是否可用，用于健康检查
+func Ping(ctx context.Context) error {
+	if db == nil {
+		return errors.New("mysql not initialized")
+	}
+	return db.PingContext(ctx)
+}
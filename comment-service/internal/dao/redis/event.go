@@ -0,0 +1,28 @@
+package redis
+
+import (
+	"bluebell_microservices/common/pkg/event"
+	"encoding/json"
+)
+
+// PublishPostEvent 发布帖子实时事件
+func PublishPostEvent(ev *event.PostEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return client.Publish(event.PostChannel(ev.PostID), data).Err()
+}
+
+// EventDAO 帖子实时事件的发布，基于包级 client
+type EventDAO struct{}
+
+// NewEventDAO 创建新的 EventDAO 实例
+func NewEventDAO() *EventDAO {
+	return &EventDAO{}
+}
+
+// PublishPostEvent 发布帖子实时事件
+func (d *EventDAO) PublishPostEvent(ev *event.PostEvent) error {
+	return PublishPostEvent(ev)
+}
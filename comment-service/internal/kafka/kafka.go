@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/kafka"
+	"context"
+	"fmt"
+)
+
+var producer *kafka.Producer
+
+// Init 创建评论事件生产者
+func Init(conf *config.Config) error {
+	p, err := kafka.NewProducer(kafka.KafkaConfig{
+		Brokers: conf.Kafka.Brokers,
+		Topic:   conf.Kafka.CommentTopic,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka producer: %v", err)
+	}
+	producer = p
+	return nil
+}
+
+// Close 关闭生产者
+func Close() error {
+	if producer != nil {
+		return producer.Close()
+	}
+	return nil
+}
+
+// Producer 评论事件生产者，需先调用 Init
+type Producer struct{}
+
+// NewProducer 创建评论事件生产者
+func NewProducer() *Producer {
+	return &Producer{}
+}
+
+// SendCommentMessage 发送评论事件
+func (p *Producer) SendCommentMessage(ctx context.Context, message kafka.CommentMessage) error {
+	if producer == nil {
+		return fmt.Errorf("kafka producer is not initialized")
+	}
+	return producer.SendCommentMessage(ctx, message)
+}
@@ -1,45 +1,170 @@
 package logic
 
 import (
-	"bluebell_microservices/comment-service/internal/dao/mysql"
 	"bluebell_microservices/comment-service/internal/model"
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/event"
+	"bluebell_microservices/common/pkg/kafka"
 	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/rbac"
 	"context"
+	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 )
 
+// MaxBatchPosts GetCommentCounts 一次最多查询的帖子数
+const MaxBatchPosts = 100
+
+// ErrTooManyPostIDs 批量查询的帖子数超过上限
+var ErrTooManyPostIDs = errcode.InvalidArgument("TOO_MANY_POST_IDS", fmt.Sprintf("一次最多查询 %d 个帖子", MaxBatchPosts))
+
+// ErrNotModerator 删除评论的用户不是评论所在社区的版主
+var ErrNotModerator = errcode.PermissionDenied("NOT_MODERATOR", "不是该社区的版主")
+
 type CommentLogic struct {
-	commentDao *mysql.CommentDAO
+	commentDao CommentStore
+	events     EventPublisher
+	producer   EventProducer
+	moderators ModeratorChecker
 }
 
-func NewCommentLogic() *CommentLogic {
+// NewCommentLogic 创建 CommentLogic，线上传入 mysql.NewCommentDAO()、redis.NewEventDAO()、kafka.NewProducer()、client.NewPostClient()
+func NewCommentLogic(commentDao CommentStore, events EventPublisher, producer EventProducer, moderators ModeratorChecker) *CommentLogic {
 	return &CommentLogic{
-		commentDao: mysql.NewCommentDAO(),
+		commentDao: commentDao,
+		events:     events,
+		producer:   producer,
+		moderators: moderators,
 	}
 }
 
 func (l *CommentLogic) CreateComment(ctx context.Context, comment *model.Comment) error {
-	logger.Info("CreateComment attempt", zap.Any("comment", comment))
+	logger.Ctx(ctx).Info("CreateComment attempt", zap.Any("comment", comment))
+
+	// 校验评论内容及父评论
+	if err := l.validateComment(ctx, comment); err != nil {
+		logger.Ctx(ctx).Warn("Invalid comment", zap.Error(err))
+		return err
+	}
 
 	// 保存到数据库
 	if err := l.commentDao.CreateComment(ctx, comment); err != nil {
-		logger.Error("Failed to create comment", zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to create comment", zap.Error(err))
 		return err
 	}
 
+	// 推送新评论给帖子的订阅方，失败不影响评论创建
+	ev := &event.PostEvent{
+		Type:       event.TypeComment,
+		PostID:     int64(comment.PostID),
+		CommentID:  int64(comment.CommentID),
+		ParentID:   int64(comment.ParentID),
+		AuthorID:   int64(comment.AuthorID),
+		Content:    comment.Content,
+		CreateTime: comment.CreateTime.Format("2006-01-02 15:04:05"),
+	}
+	if err := l.events.PublishPostEvent(ev); err != nil {
+		logger.Ctx(ctx).Warn("Failed to publish comment event", zap.Uint64("post_id", comment.PostID), zap.Error(err))
+	}
+	l.sendCommentMessage(ctx, kafka.CommentCreated, comment)
+
 	return nil
 }
 
+// sendCommentMessage 发送评论创建、删除事件，失败不影响评论本身，帖子评论数由 post-service 定时修正
+func (l *CommentLogic) sendCommentMessage(ctx context.Context, typ string, comment *model.Comment) {
+	msg := kafka.CommentMessage{
+		Type:      typ,
+		CommentID: int64(comment.CommentID),
+		PostID:    int64(comment.PostID),
+		AuthorID:  int64(comment.AuthorID),
+		Timestamp: time.Now().Unix(),
+	}
+	if err := l.producer.SendCommentMessage(ctx, msg); err != nil {
+		logger.Ctx(ctx).Warn("Failed to send comment message",
+			zap.String("type", typ), zap.Uint64("comment_id", comment.CommentID), zap.Error(err))
+	}
+}
+
 // GetCommentList 获取评论列表
 func (l *CommentLogic) GetCommentList(ctx context.Context, postID uint64) ([]*model.Comment, error) {
-	logger.Info("GetCommentList attempt", zap.Uint64("post_id", postID))
+	logger.Ctx(ctx).Info("GetCommentList attempt", zap.Uint64("post_id", postID))
 
 	comments, err := l.commentDao.GetCommentList(ctx, postID)
 	if err != nil {
-		logger.Error("Failed to get comment list", zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to get comment list", zap.Error(err))
 		return nil, err
 	}
 
 	return comments, nil
 }
+
+// GetCommentCounts 批量查询帖子的评论数，结果包含请求中的每个帖子，没有评论的为 0
+func (l *CommentLogic) GetCommentCounts(ctx context.Context, postIDs []uint64) (map[uint64]int64, error) {
+	ids := make([]uint64, 0, len(postIDs))
+	counts := make(map[uint64]int64, len(postIDs))
+	for _, id := range postIDs {
+		if _, ok := counts[id]; !ok {
+			counts[id] = 0
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > MaxBatchPosts {
+		return nil, ErrTooManyPostIDs
+	}
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	found, err := l.commentDao.CountComments(ctx, ids)
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to count comments", zap.Int("count", len(ids)), zap.Error(err))
+		return nil, err
+	}
+	for id, n := range found {
+		counts[id] = n
+	}
+	return counts, nil
+}
+
+// GetComment 获取单条评论
+func (l *CommentLogic) GetComment(ctx context.Context, commentID uint64) (*model.Comment, error) {
+	comment, err := l.commentDao.GetCommentByID(ctx, commentID)
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to get comment", zap.Uint64("comment_id", commentID), zap.Error(err))
+		return nil, err
+	}
+	return comment, nil
+}
+
+// RemoveComment 软删除评论，删除后不再出现在评论列表中；重复删除直接返回成功
+// 操作人需是全站版主、管理员或评论所在社区的版主，不依赖 BFF 的校验
+func (l *CommentLogic) RemoveComment(ctx context.Context, commentID, operatorID uint64, operatorRole string) error {
+	logger.Ctx(ctx).Info("RemoveComment attempt", zap.Uint64("comment_id", commentID), zap.Uint64("operator_id", operatorID))
+
+	comment, err := l.commentDao.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if !rbac.HasPermission(operatorRole, rbac.PermModerateAll) {
+		isModerator, err := l.moderators.IsModerator(ctx, operatorID, comment.PostID)
+		if err != nil {
+			logger.Ctx(ctx).Error("Failed to check moderator", zap.Uint64("post_id", comment.PostID), zap.Error(err))
+			return err
+		}
+		if !isModerator {
+			return ErrNotModerator
+		}
+	}
+	if comment.Status == model.CommentStatusRemoved {
+		return nil
+	}
+	if err := l.commentDao.UpdateCommentStatus(ctx, commentID, model.CommentStatusRemoved); err != nil {
+		logger.Ctx(ctx).Error("Failed to remove comment", zap.Uint64("comment_id", commentID), zap.Error(err))
+		return err
+	}
+	l.sendCommentMessage(ctx, kafka.CommentDeleted, comment)
+	return nil
+}
@@ -0,0 +1,255 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bluebell_microservices/comment-service/internal/dao/memory"
+	"bluebell_microservices/comment-service/internal/dao/mysql"
+	"bluebell_microservices/comment-service/internal/model"
+	"bluebell_microservices/common/pkg/event"
+	"bluebell_microservices/common/pkg/kafka"
+	"bluebell_microservices/common/pkg/rbac"
+	"bluebell_microservices/common/pkg/validate"
+)
+
+// newTestCommentLogic 创建使用内存存储的 CommentLogic，并预置帖子 1 下的评论 10 及已删除的评论 11
+func newTestCommentLogic(t *testing.T) (*CommentLogic, *memory.CommentStore, *memory.EventRecorder, *memory.Producer) {
+	t.Helper()
+	store := memory.NewCommentStore()
+	events := memory.NewEventRecorder()
+	producer := memory.NewProducer()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	for _, c := range []*model.Comment{
+		{CommentID: 10, PostID: 1, AuthorID: 100, Content: "first", Status: model.CommentStatusNormal, CreateTime: base},
+		{CommentID: 11, PostID: 1, AuthorID: 100, Content: "removed", Status: model.CommentStatusRemoved, CreateTime: base.Add(time.Minute)},
+	} {
+		if err := store.CreateComment(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewCommentLogic(store, events, producer, memory.NewModeratorStore()), store, events, producer
+}
+
+// fieldReasons 将校验错误转换为 字段 -> 原因，便于断言
+func fieldReasons(err error) map[string]string {
+	var errs validate.Errors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+	reasons := make(map[string]string, len(errs))
+	for _, fe := range errs {
+		reasons[fe.Field] = fe.Reason
+	}
+	return reasons
+}
+
+func TestCommentLogic_CreateComment(t *testing.T) {
+	tests := []struct {
+		name       string
+		comment    model.Comment
+		publishErr error
+		wantFields map[string]string // 期望的校验错误，nil 表示创建成功
+	}{
+		{
+			name:    "ok",
+			comment: model.Comment{PostID: 1, Content: "hello"},
+		},
+		{
+			name:    "reply",
+			comment: model.Comment{PostID: 1, ParentID: 10, Content: "reply"},
+		},
+		{
+			name:       "publish failure does not fail create",
+			comment:    model.Comment{PostID: 1, Content: "hello"},
+			publishErr: errors.New("redis down"),
+		},
+		{
+			name:       "missing post and content",
+			comment:    model.Comment{},
+			wantFields: map[string]string{"post_id": validate.ReasonRequired, "content": validate.ReasonRequired},
+		},
+		{
+			name:       "parent not found",
+			comment:    model.Comment{PostID: 1, ParentID: 99, Content: "reply"},
+			wantFields: map[string]string{"parent_id": validate.ReasonNotFound},
+		},
+		{
+			name:       "parent removed",
+			comment:    model.Comment{PostID: 1, ParentID: 11, Content: "reply"},
+			wantFields: map[string]string{"parent_id": validate.ReasonNotFound},
+		},
+		{
+			name:       "parent on another post",
+			comment:    model.Comment{PostID: 2, ParentID: 10, Content: "reply"},
+			wantFields: map[string]string{"parent_id": validate.ReasonMismatch},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, store, events, producer := newTestCommentLogic(t)
+			events.Err = tt.publishErr
+
+			c := tt.comment
+			c.CommentID = 20
+			c.AuthorID = 200
+			c.Status = model.CommentStatusNormal
+			c.CreateTime = time.Now()
+			err := l.CreateComment(context.Background(), &c)
+
+			if tt.wantFields != nil {
+				got := fieldReasons(err)
+				if len(got) != len(tt.wantFields) {
+					t.Fatalf("CreateComment() error = %v, want fields %v", err, tt.wantFields)
+				}
+				for field, reason := range tt.wantFields {
+					if got[field] != reason {
+						t.Errorf("field %s reason = %q, want %q", field, got[field], reason)
+					}
+				}
+				if _, err := store.GetCommentByID(context.Background(), 20); !errors.Is(err, mysql.ErrCommentNotFound) {
+					t.Errorf("invalid comment was saved")
+				}
+				if n := len(producer.Messages()); n != 0 {
+					t.Errorf("sent %d comment messages for invalid comment", n)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CreateComment() error = %v", err)
+			}
+			if _, err := store.GetCommentByID(context.Background(), 20); err != nil {
+				t.Fatalf("comment not saved: %v", err)
+			}
+			if msgs := producer.Messages(); len(msgs) != 1 || msgs[0].Type != kafka.CommentCreated || msgs[0].CommentID != 20 || msgs[0].PostID != int64(c.PostID) {
+				t.Errorf("comment messages = %+v", msgs)
+			}
+			published := events.Events()
+			if tt.publishErr != nil {
+				if len(published) != 0 {
+					t.Errorf("published %d events, want 0", len(published))
+				}
+				return
+			}
+			if len(published) != 1 {
+				t.Fatalf("published %d events, want 1", len(published))
+			}
+			ev := published[0]
+			if ev.Type != event.TypeComment || ev.PostID != int64(c.PostID) || ev.CommentID != 20 || ev.ParentID != int64(c.ParentID) {
+				t.Errorf("published event = %+v", ev)
+			}
+		})
+	}
+}
+
+func TestCommentLogic_GetCommentList(t *testing.T) {
+	tests := []struct {
+		name   string
+		postID uint64
+		want   []uint64
+	}{
+		{name: "removed comments hidden", postID: 1, want: []uint64{10}},
+		{name: "no comments", postID: 2, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _, _, _ := newTestCommentLogic(t)
+			list, err := l.GetCommentList(context.Background(), tt.postID)
+			if err != nil {
+				t.Fatalf("GetCommentList() error = %v", err)
+			}
+			if len(list) != len(tt.want) {
+				t.Fatalf("GetCommentList() returned %d comments, want %d", len(list), len(tt.want))
+			}
+			for i, c := range list {
+				if c.CommentID != tt.want[i] {
+					t.Errorf("comment[%d] = %d, want %d", i, c.CommentID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCommentLogic_GetCommentCounts(t *testing.T) {
+	tooMany := make([]uint64, MaxBatchPosts+1)
+	for i := range tooMany {
+		tooMany[i] = uint64(i + 1)
+	}
+	tests := []struct {
+		name    string
+		postIDs []uint64
+		want    map[uint64]int64
+		wantErr error
+	}{
+		{name: "removed comments not counted", postIDs: []uint64{1, 2}, want: map[uint64]int64{1: 1, 2: 0}},
+		{name: "duplicates", postIDs: []uint64{1, 1}, want: map[uint64]int64{1: 1}},
+		{name: "empty", postIDs: nil, want: map[uint64]int64{}},
+		{name: "too many", postIDs: tooMany, wantErr: ErrTooManyPostIDs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _, _, _ := newTestCommentLogic(t)
+			counts, err := l.GetCommentCounts(context.Background(), tt.postIDs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCommentCounts() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(counts) != len(tt.want) {
+				t.Fatalf("GetCommentCounts() = %v, want %v", counts, tt.want)
+			}
+			for id, n := range tt.want {
+				if got, ok := counts[id]; !ok || got != n {
+					t.Errorf("count[%d] = %d, want %d", id, got, n)
+				}
+			}
+		})
+	}
+}
+
+func TestCommentLogic_RemoveComment(t *testing.T) {
+	tests := []struct {
+		name       string
+		commentID  uint64
+		operatorID uint64 // 用户 1 是帖子 1 所在社区的版主
+		role       string
+		wantErr    error
+		wantEvent  bool // 只有状态从正常变为删除时才发送删除事件
+	}{
+		{name: "ok", commentID: 10, operatorID: 1, role: rbac.RoleUser, wantEvent: true},
+		{name: "global moderator", commentID: 10, operatorID: 2, role: rbac.RoleModerator, wantEvent: true},
+		{name: "not moderator", commentID: 10, operatorID: 2, role: rbac.RoleUser, wantErr: ErrNotModerator},
+		{name: "already removed", commentID: 11, operatorID: 1, role: rbac.RoleUser},
+		{name: "not found", commentID: 99, operatorID: 1, role: rbac.RoleUser, wantErr: mysql.ErrCommentNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, store, _, producer := newTestCommentLogic(t)
+			moderators := memory.NewModeratorStore()
+			moderators.AddModerator(1, 1)
+			l.moderators = moderators
+			err := l.RemoveComment(context.Background(), tt.commentID, tt.operatorID, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RemoveComment() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if c, _ := store.GetCommentByID(context.Background(), 10); c.Status != model.CommentStatusNormal {
+					t.Errorf("comment 10 status = %d after failed remove, want %d", c.Status, model.CommentStatusNormal)
+				}
+				return
+			}
+			c, _ := store.GetCommentByID(context.Background(), tt.commentID)
+			if c.Status != model.CommentStatusRemoved {
+				t.Errorf("status = %d, want %d", c.Status, model.CommentStatusRemoved)
+			}
+			msgs := producer.Messages()
+			if tt.wantEvent != (len(msgs) == 1) || (tt.wantEvent && msgs[0].Type != kafka.CommentDeleted) {
+				t.Errorf("comment messages = %+v, want deleted event: %v", msgs, tt.wantEvent)
+			}
+		})
+	}
+}
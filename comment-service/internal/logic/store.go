@@ -0,0 +1,36 @@
+package logic
+
+import (
+	"context"
+
+	"bluebell_microservices/comment-service/internal/model"
+	"bluebell_microservices/common/pkg/event"
+	"bluebell_microservices/common/pkg/kafka"
+)
+
+// CommentStore 评论存储，mysql.CommentDAO 为线上实现，memory.CommentStore 为测试用的内存实现
+type CommentStore interface {
+	CreateComment(ctx context.Context, comment *model.Comment) error
+	// GetCommentList 帖子下未删除的评论，按创建时间倒序
+	GetCommentList(ctx context.Context, postID uint64) ([]*model.Comment, error)
+	// GetCommentByID 包括已删除的评论，不存在时返回 mysql.ErrCommentNotFound
+	GetCommentByID(ctx context.Context, commentID uint64) (*model.Comment, error)
+	UpdateCommentStatus(ctx context.Context, commentID uint64, status int32) error
+	// CountComments 各帖子未删除的评论数，没有评论的帖子不在结果中
+	CountComments(ctx context.Context, postIDs []uint64) (map[uint64]int64, error)
+}
+
+// ModeratorChecker 查询用户是否为帖子所在社区的版主，client.PostClient 为线上实现，memory.ModeratorStore 为测试用的内存实现
+type ModeratorChecker interface {
+	IsModerator(ctx context.Context, userID, postID uint64) (bool, error)
+}
+
+// EventPublisher 帖子实时事件的发布，redis.EventDAO 为线上实现
+type EventPublisher interface {
+	PublishPostEvent(ev *event.PostEvent) error
+}
+
+// EventProducer 评论创建、删除事件的生产者，kafka.Producer 为线上实现，post-service 据此维护帖子评论数
+type EventProducer interface {
+	SendCommentMessage(ctx context.Context, message kafka.CommentMessage) error
+}
@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"bluebell_microservices/comment-service/internal/dao/mysql"
+	"bluebell_microservices/comment-service/internal/model"
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/validate"
+)
+
+// MaxCommentContentLen 与 comment 表 content text 列一致，按字节计
+const MaxCommentContentLen = 65535
+
+// validateComment 校验评论内容及父评论是否属于同一帖子
+func (l *CommentLogic) validateComment(ctx context.Context, comment *model.Comment) error {
+	v := validate.New()
+	// 每次读取最新配置，屏蔽词修改后无需重启
+	wordFilter := validate.NewWordFilter(config.Get().BlockedWords())
+
+	if comment.PostID == 0 {
+		v.Add("post_id", validate.ReasonRequired, "不能为空")
+	}
+	if v.Required("content", comment.Content) && v.MaxBytes("content", comment.Content, MaxCommentContentLen) {
+		v.NoBlockedWords("content", comment.Content, wordFilter)
+	}
+
+	if comment.ParentID != 0 {
+		parent, err := l.commentDao.GetCommentByID(ctx, comment.ParentID)
+		switch {
+		case errors.Is(err, mysql.ErrCommentNotFound):
+			v.Add("parent_id", validate.ReasonNotFound, "父评论不存在")
+		case err != nil:
+			return err
+		case parent.Status != model.CommentStatusNormal:
+			v.Add("parent_id", validate.ReasonNotFound, "父评论不存在")
+		case parent.PostID != comment.PostID:
+			v.Add("parent_id", validate.ReasonMismatch, "父评论不属于该帖子")
+		}
+	}
+
+	return v.Err()
+}
@@ -2,11 +2,18 @@ package model
 
 import "time"
 
+// 评论状态
+const (
+	CommentStatusRemoved = 0 // 已被版主删除
+	CommentStatusNormal  = 1 // 正常
+)
+
 type Comment struct {
 	PostID     uint64    `db:"post_id" json:"post_id"`
 	ParentID   uint64    `db:"parent_id" json:"parent_id"`
 	CommentID  uint64    `db:"comment_id" json:"comment_id"`
 	AuthorID   uint64    `db:"author_id" json:"author_id"`
 	Content    string    `db:"content" json:"content"`
+	Status     int32     `db:"status" json:"status"`
 	CreateTime time.Time `db:"create_time" json:"create_time"`
 }
@@ -0,0 +1,12 @@
+-- 使用 IF NOT EXISTS，已由旧版 init.sql 建表的数据库可以直接标记为该版本
+CREATE TABLE IF NOT EXISTS `comment` (
+    `comment_id` bigint NOT NULL,
+    `content` text NOT NULL,
+    `post_id` bigint NOT NULL,
+    `author_id` bigint NOT NULL,
+    `parent_id` bigint DEFAULT NULL,
+    `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`comment_id`),
+    KEY `idx_post_id` (`post_id`),
+    KEY `idx_author_id` (`author_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
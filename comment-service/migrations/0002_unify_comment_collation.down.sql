@@ -0,0 +1 @@
+-- 不恢复旧的排序规则：utf8mb4_0900_ai_ci 仅 MySQL 8 支持，且统一后的排序规则与 0001 建表一致
@@ -0,0 +1,2 @@
+-- 旧版 init.sql 中 comment 表使用 utf8mb4_0900_ai_ci，与其他表不一致
+ALTER TABLE `comment` CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;
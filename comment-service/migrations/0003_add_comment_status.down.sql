@@ -0,0 +1 @@
+ALTER TABLE `comment` DROP COLUMN `status`;
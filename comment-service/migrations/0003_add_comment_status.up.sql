@@ -0,0 +1 @@
+ALTER TABLE `comment` ADD COLUMN `status` tinyint NOT NULL DEFAULT 1 COMMENT '评论状态：1-正常，0-已删除' AFTER `parent_id`;
@@ -2,85 +2,206 @@
 package config
 
 import (
-	"os"
-
-	"github.com/spf13/viper"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
+// Conf 启动时加载的配置；可热更新的字段（日志级别、限流等）需通过 Get 读取最新值
 var Conf *Config
 
+// current 最近一次成功加载的配置
+var current atomic.Pointer[Config]
+
+// Get 返回最新的配置，热更新后与 Conf 中的可热更新字段可能不同
+func Get() *Config {
+	if c := current.Load(); c != nil {
+		return c
+	}
+	return Conf
+}
+
+// Set 直接将 conf 作为当前配置，不读取配置文件也不校验，用于测试中在进程内启动服务
+func Set(conf *Config) {
+	Conf = conf
+	current.Store(conf)
+}
+
+// 配置统一使用 mapstructure 标签，viper 按该标签绑定配置文件、环境变量及命令行参数
 type Config struct {
-	Server *Server `yaml:"server"`
-	MySQL  *MySQL  `yaml:"mysql"`
-	Redis  *Redis  `yaml:"redis"`
-	Etcd   *Etcd   `yaml:"etcd"`
-	Kafka  *Kafka  `yaml:"kafka"`
+	Server *Server `mapstructure:"server"`
+	Log    *Log    `mapstructure:"log"`
+	MySQL  *MySQL  `mapstructure:"mysql"`
+	Redis  *Redis  `mapstructure:"redis"`
+	Etcd   *Etcd   `mapstructure:"etcd"`
+	Kafka  *Kafka  `mapstructure:"kafka"`
+
+	Services map[string]*Service `mapstructure:"services"`
+
+	RateLimit  *RateLimit  `mapstructure:"rate_limit"`
+	Validation *Validation `mapstructure:"validation"`
+	Tracing    *Tracing    `mapstructure:"tracing"`
+	Metrics    *Metrics    `mapstructure:"metrics"`
+	Cache      *Cache      `mapstructure:"cache"`
+
+	Downstreams map[string]*Downstream `mapstructure:"downstreams"` // BFF 调用各下游服务的配置，key 为服务名
 }
 
 type Server struct {
-	Port      string `yaml:"port"`
-	Version   string `yaml:"version"`
-	JwtSecret string `yaml:"jwtSecret"`
+	Port          string `mapstructure:"port"`
+	Version       string `mapstructure:"version"`
+	JwtSecret     string `mapstructure:"jwtSecret"`
+	JwtSecretFile string `mapstructure:"jwtSecret_file"` // 从文件读取 jwtSecret，优先于 jwtSecret
+
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // BFF 信任的反向代理（IP 或 CIDR），为空时不信任 X-Forwarded-For
+}
+
+// Log 日志配置，level 支持热更新
+type Log struct {
+	Level      string `mapstructure:"level"`       // debug、info、warn、error
+	Dir        string `mapstructure:"dir"`         // 日志目录，文件名为 <服务名>.log，为空时写入工作目录
+	MaxSize    int    `mapstructure:"max_size"`    // 单个文件达到多少 MB 后切割
+	MaxBackups int    `mapstructure:"max_backups"` // 保留的历史文件数，0 表示不限制
+	MaxAge     int    `mapstructure:"max_age"`     // 历史文件保留天数，0 表示不限制
+	Compress   bool   `mapstructure:"compress"`    // 是否 gzip 压缩历史文件
 }
 
 type MySQL struct {
-	Host         string `yaml:"host"`
-	Port         int    `yaml:"port"`
-	Database     string `yaml:"database"`
-	Username     string `yaml:"username"`
-	Password     string `yaml:"password"`
-	Charset      string `yaml:"charset"`
-	MaxOpenConns int    `yaml:"maxOpenConns"`
-	MaxIdleConns int    `yaml:"maxIdleConns"`
+	Host         string `mapstructure:"host"`
+	Port         int    `mapstructure:"port"`
+	Database     string `mapstructure:"database"`
+	Username     string `mapstructure:"username"`
+	Password     string `mapstructure:"password"`
+	PasswordFile string `mapstructure:"password_file"` // 从文件读取密码，优先于 password
+	Charset      string `mapstructure:"charset"`
+	MaxOpenConns int    `mapstructure:"maxOpenConns"`
+	MaxIdleConns int    `mapstructure:"maxIdleConns"`
+	AutoMigrate  bool   `mapstructure:"auto_migrate"` // 启动时自动执行未应用的数据库迁移，关闭时需先运行 migrate up
 }
 
 type Redis struct {
-	Host         string `yaml:"host"`
-	Port         int    `yaml:"port"`
-	DB           int    `yaml:"db"`
-	Password     string `yaml:"password"`
+	Host         string `mapstructure:"host"`
+	Port         int    `mapstructure:"port"`
+	DB           int    `mapstructure:"db"`
+	Password     string `mapstructure:"password"`
+	PasswordFile string `mapstructure:"password_file"` // 从文件读取密码，优先于 password
 	PoolSize     int    `mapstructure:"pool_size"`
 	MinIdleConns int    `mapstructure:"min_idle_conns"`
 }
 
 type Etcd struct {
-	Address string `yaml:"address"`
+	Address  string `mapstructure:"address"`  // 多个地址用逗号分隔
+	Balancer string `mapstructure:"balancer"` // 客户端负载均衡策略：round_robin（默认）或 weighted
 }
 
-type Kafka struct {
-	Brokers        []string `yaml:"brokers"`
-	Topic          string   `yaml:"topic"`
-	BatchSize      int      `yaml:"batch_size"`
-	VoteCountsFile string   `yaml:"vote_counts_file"`
-}
-
-func InitConfig() {
-	workDir, _ := os.Getwd()
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-
-	// 获取项目根目录
-	rootDir := workDir
-	for {
-		if _, err := os.Stat(rootDir + "/common/config/config.yaml"); err == nil {
-			break
+// Endpoints 返回 etcd 地址列表
+func (e *Etcd) Endpoints() []string {
+	var endpoints []string
+	for _, addr := range strings.Split(e.Address, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			endpoints = append(endpoints, addr)
 		}
-		parent := rootDir + "/.."
-		if parent == rootDir {
-			panic("无法找到配置文件")
-		}
-		rootDir = parent
 	}
+	return endpoints
+}
 
-	// 添加配置文件路径
-	viper.AddConfigPath(rootDir + "/common/config")
+// Service 微服务实例配置
+type Service struct {
+	Port            int           `mapstructure:"port"`             // gRPC 监听端口
+	Advertise       string        `mapstructure:"advertise"`        // 注册到 etcd 的地址，为空时使用 主机名:端口
+	MachineID       uint16        `mapstructure:"machine_id"`       // 雪花算法机器ID，为 0 时启动时从 etcd 分配；指定时只能有一个实例使用，已被占用时拒绝启动
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"` // 优雅退出等待请求完成的最长时间
+	InstanceID      string        `mapstructure:"instance_id"`      // 实例ID，为空时使用 主机名-端口
+	Weight          int           `mapstructure:"weight"`           // 负载均衡权重，默认 1
+	Zone            string        `mapstructure:"zone"`             // 所在可用区
+	MetricsPort     int           `mapstructure:"metrics_port"`     // /metrics 监听端口，0 表示不开启
+}
 
-	err := viper.ReadInConfig()
-	if err != nil {
-		panic(err)
-	}
-	err = viper.Unmarshal(&Conf)
-	if err != nil {
-		panic(err)
+type Kafka struct {
+	Brokers       []string      `mapstructure:"brokers"` // 环境变量中多个地址用逗号分隔
+	Topic         string        `mapstructure:"topic"`
+	DLQTopic      string        `mapstructure:"dlq_topic"` // 无法处理的消息转入的死信队列，为空时只记录指标
+	BatchSize     int           `mapstructure:"batch_size"`
+	DriftInterval time.Duration `mapstructure:"drift_interval"` // Redis 与 MySQL 投票数核对间隔
+
+	CommentTopic          string        `mapstructure:"comment_topic"`           // 评论创建、删除事件
+	CommentRepairInterval time.Duration `mapstructure:"comment_repair_interval"` // 按 comment-service 的数据修正帖子评论数的间隔
+}
+
+// Downstream BFF 调用下游服务的超时、重试及熔断配置
+type Downstream struct {
+	Timeout time.Duration            `mapstructure:"timeout"` // 单次调用超时，0 表示只受请求本身的 context 控制
+	Methods map[string]time.Duration `mapstructure:"methods"` // 按方法名覆盖超时，方法名忽略大小写
+	Retry   *Retry                   `mapstructure:"retry"`   // 只对幂等的读接口生效
+	Breaker *Breaker                 `mapstructure:"breaker"`
+}
+
+// Retry gRPC 重试策略，对应 service config 中的 retryPolicy
+type Retry struct {
+	MaxAttempts       int           `mapstructure:"max_attempts"` // 含首次调用，最多 5 次，小于 2 时不重试
+	InitialBackoff    time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff        time.Duration `mapstructure:"max_backoff"`
+	BackoffMultiplier float64       `mapstructure:"backoff_multiplier"`
+}
+
+// Breaker 熔断配置：连续 failure_threshold 次调用失败后熔断，open_timeout 后放行一个探测请求
+type Breaker struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"` // 0 表示不开启熔断
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`
+}
+
+// RateLimit BFF 限流配置，支持热更新
+type RateLimit struct {
+	Enabled      bool                        `mapstructure:"enabled"`
+	Policies     map[string]*RateLimitPolicy `mapstructure:"policies"`
+	LoginLockout *LoginLockout               `mapstructure:"login_lockout"`
+}
+
+// RateLimitPolicy 单条限流策略：每个 scope 标识在 window 内最多 limit 次请求
+type RateLimitPolicy struct {
+	Scope  string        `mapstructure:"scope"` // ip 或 user，user 未登录时退化为 ip
+	Limit  int           `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
+}
+
+// LoginLockout 登录暴力破解防护：window 内同一用户名失败 max_failures 次后锁定 lock_duration
+type LoginLockout struct {
+	MaxFailures  int           `mapstructure:"max_failures"`
+	Window       time.Duration `mapstructure:"window"`
+	LockDuration time.Duration `mapstructure:"lock_duration"`
+}
+
+// Validation 发帖、评论内容校验配置，支持热更新
+type Validation struct {
+	BlockedWords []string `mapstructure:"blocked_words"`
+}
+
+// BlockedWords 返回配置的屏蔽词，未配置时为空
+func (c *Config) BlockedWords() []string {
+	if c == nil || c.Validation == nil {
+		return nil
 	}
+	return c.Validation.BlockedWords
+}
+
+// Tracing 链路追踪配置
+type Tracing struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"`     // stdout 或 file
+	File        string  `mapstructure:"file"`         // exporter 为 file 时的输出文件，为空时使用 <服务名>-trace.json
+	SampleRatio float64 `mapstructure:"sample_ratio"` // 采样比例，0 或不配置时全部采样
+}
+
+// Cache 热点数据读缓存：Redis 缓存 + 可选的进程内 LRU
+type Cache struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PostTTL      time.Duration `mapstructure:"post_ttl"`      // 帖子详情在 Redis 中的缓存时间
+	CommunityTTL time.Duration `mapstructure:"community_ttl"` // 社区信息在 Redis 中的缓存时间
+	LocalSize    int           `mapstructure:"local_size"`    // 进程内 LRU 容量，0 表示不开启
+	LocalTTL     time.Duration `mapstructure:"local_ttl"`     // 进程内缓存时间，应短于 Redis 缓存时间
+}
+
+// Metrics Prometheus 指标配置，各微服务的端口见 services.<name>.metrics_port
+type Metrics struct {
+	BFFPort int `mapstructure:"bff_port"` // BFF 的 /metrics 监听端口，0 表示不开启
 }
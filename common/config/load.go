@@ -0,0 +1,251 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// 配置按以下优先级逐层覆盖（后者覆盖前者）：
+//  1. 代码中的默认值（setDefaults）
+//  2. 公共配置文件 config.yaml
+//  3. 同目录下的服务配置文件 <服务名>.yaml（可选）
+//  4. 环境变量，前缀 BLUEBELL_，层级用下划线连接，如 BLUEBELL_MYSQL_HOST、BLUEBELL_SERVICES_USER_PORT
+//  5. 命令行参数 -set key=value，可重复，如 -set mysql.host=127.0.0.1
+//
+// 配置文件路径可通过 -config 或环境变量 BLUEBELL_CONFIG 指定，未指定时从工作目录向上查找 common/config/config.yaml
+// 密码等敏感配置可通过环境变量或 *_file（如 BLUEBELL_MYSQL_PASSWORD_FILE）从文件读取
+
+// EnvPrefix 环境变量前缀
+const EnvPrefix = "BLUEBELL"
+
+// defaultConfigFile 未指定配置文件时查找的相对路径
+const defaultConfigFile = "common/config/config.yaml"
+
+var (
+	configFile = flag.String("config", "", "配置文件路径，默认从工作目录向上查找 "+defaultConfigFile)
+	overrides  setFlags
+)
+
+func init() {
+	flag.Var(&overrides, "set", "覆盖配置项，格式 key=value，可重复")
+}
+
+// setFlags 可重复的 -set 参数
+type setFlags []string
+
+func (s *setFlags) String() string { return strings.Join(*s, ",") }
+
+func (s *setFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("invalid -set %q, want key=value", value)
+	}
+	*s = append(*s, value)
+	return nil
+}
+
+// loader 记录一次加载使用的来源，热更新时按同样的来源重新加载
+type loader struct {
+	service string
+	files   []string // 实际读取的配置文件，依次合并
+}
+
+// InitConfig 按优先级加载 service 的配置并校验，service 为 user、post、comment、bff 等
+func InitConfig(service string) error {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+
+	l, err := newLoader(service)
+	if err != nil {
+		return err
+	}
+	conf, err := l.load()
+	if err != nil {
+		return err
+	}
+	if err := conf.Validate(service); err != nil {
+		return err
+	}
+
+	Conf = conf
+	current.Store(conf)
+	activeLoader = l
+	return nil
+}
+
+// newLoader 确定配置文件：显式指定的文件不存在时报错，默认文件不存在时只使用默认值、环境变量和命令行参数
+func newLoader(service string) (*loader, error) {
+	l := &loader{service: service}
+
+	base := *configFile
+	if base == "" {
+		base = os.Getenv(EnvPrefix + "_CONFIG")
+	}
+	if base != "" {
+		if _, err := os.Stat(base); err != nil {
+			return nil, fmt.Errorf("config file %s: %v", base, err)
+		}
+	} else {
+		base = findConfigFile()
+	}
+	if base == "" {
+		return l, nil
+	}
+	l.files = append(l.files, base)
+
+	// 服务配置文件与公共配置文件位于同一目录
+	overlay := filepath.Join(filepath.Dir(base), service+filepath.Ext(base))
+	if _, err := os.Stat(overlay); err == nil {
+		l.files = append(l.files, overlay)
+	}
+	return l, nil
+}
+
+// findConfigFile 从工作目录逐级向上查找默认配置文件，找不到时返回空
+func findConfigFile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, defaultConfigFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// load 按优先级合并各层配置并解析为 Config
+func (l *loader) load() (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	for i, file := range l.files {
+		v.SetConfigFile(file)
+		v.SetConfigType(strings.TrimPrefix(filepath.Ext(file), "."))
+		read := v.MergeInConfig
+		if i == 0 {
+			read = v.ReadInConfig
+		}
+		if err := read(); err != nil {
+			return nil, fmt.Errorf("read config %s: %v", file, err)
+		}
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	for _, kv := range overrides {
+		key, value, _ := strings.Cut(kv, "=")
+		v.Set(strings.TrimSpace(key), value)
+	}
+
+	conf := new(Config)
+	if err := v.Unmarshal(conf); err != nil {
+		return nil, fmt.Errorf("decode config: %v", err)
+	}
+	if err := conf.resolveSecrets(); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// setDefaults 默认值，同时让 viper 知道这些配置项，从而可以只通过环境变量设置
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.port", ":8080")
+	v.SetDefault("server.version", "1.0")
+	v.SetDefault("server.jwtSecret", "")
+	v.SetDefault("server.jwtSecret_file", "")
+
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.dir", "")
+	v.SetDefault("log.max_size", 100)
+	v.SetDefault("log.max_backups", 10)
+	v.SetDefault("log.max_age", 30)
+	v.SetDefault("log.compress", false)
+
+	v.SetDefault("mysql.host", "127.0.0.1")
+	v.SetDefault("mysql.port", 3306)
+	v.SetDefault("mysql.database", "bluebell_pre")
+	v.SetDefault("mysql.username", "root")
+	v.SetDefault("mysql.password", "")
+	v.SetDefault("mysql.password_file", "")
+	v.SetDefault("mysql.charset", "utf8mb4")
+	v.SetDefault("mysql.maxOpenConns", 200)
+	v.SetDefault("mysql.maxIdleConns", 50)
+	v.SetDefault("mysql.auto_migrate", false)
+
+	v.SetDefault("redis.host", "127.0.0.1")
+	v.SetDefault("redis.port", 6379)
+	v.SetDefault("redis.db", 0)
+	v.SetDefault("redis.password", "")
+	v.SetDefault("redis.password_file", "")
+	v.SetDefault("redis.pool_size", 100)
+	v.SetDefault("redis.min_idle_conns", 10)
+
+	v.SetDefault("etcd.address", "127.0.0.1:2379")
+	v.SetDefault("etcd.balancer", "round_robin")
+
+	v.SetDefault("kafka.brokers", []string{"127.0.0.1:9092"})
+	v.SetDefault("kafka.topic", "post-votes")
+	v.SetDefault("kafka.dlq_topic", "")
+	v.SetDefault("kafka.batch_size", 100)
+	v.SetDefault("kafka.drift_interval", time.Minute)
+	v.SetDefault("kafka.comment_topic", "comment-events")
+	v.SetDefault("kafka.comment_repair_interval", 10*time.Minute)
+
+	v.SetDefault("rate_limit.enabled", false)
+
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.exporter", "file")
+	v.SetDefault("tracing.file", "")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+
+	v.SetDefault("metrics.bff_port", 0)
+
+	v.SetDefault("cache.enabled", false)
+	v.SetDefault("cache.post_ttl", 30*time.Second)
+	v.SetDefault("cache.community_ttl", 5*time.Minute)
+	v.SetDefault("cache.local_size", 0)
+	v.SetDefault("cache.local_ttl", 5*time.Second)
+}
+
+// resolveSecrets 读取 *_file 指定的密钥文件，文件内容首尾空白会被去掉
+func (c *Config) resolveSecrets() error {
+	var errs []error
+	read := func(path string, dst *string) {
+		if path == "" {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read secret file: %v", err))
+			return
+		}
+		*dst = strings.TrimSpace(string(data))
+	}
+
+	if c.Server != nil {
+		read(c.Server.JwtSecretFile, &c.Server.JwtSecret)
+	}
+	if c.MySQL != nil {
+		read(c.MySQL.PasswordFile, &c.MySQL.Password)
+	}
+	if c.Redis != nil {
+		read(c.Redis.PasswordFile, &c.Redis.Password)
+	}
+	return errors.Join(errs...)
+}
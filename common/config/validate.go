@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// 各进程依赖的配置段
+const (
+	needGRPC = 1 << iota
+	needMySQL
+	needRedis
+	needKafka
+	needEtcd
+	needJWT
+)
+
+// requirements 各进程启动必需的配置，未列出的进程只做通用校验
+var requirements = map[string]int{
+	"user":          needGRPC | needMySQL | needEtcd | needJWT,
+	"post":          needGRPC | needMySQL | needRedis | needKafka | needEtcd | needJWT,
+	"comment":       needGRPC | needMySQL | needRedis | needKafka | needEtcd | needJWT,
+	"bff":           needRedis | needEtcd | needJWT,
+	"vote_consumer": needRedis | needKafka,
+}
+
+// Validate 校验 service 所需的配置，返回所有问题而不是遇到第一个就停止
+func (c *Config) Validate(service string) error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	need := requirements[service]
+
+	if need&needGRPC != 0 {
+		if s := c.Services[service]; s == nil {
+			fail("services.%s: missing", service)
+		} else if s.Port <= 0 || s.Port > 65535 {
+			fail("services.%s.port: %d out of range", service, s.Port)
+		}
+	}
+	// 指定的雪花算法机器ID不能重复，否则生成的ID可能冲突；为 0 的由 etcd 分配
+	machineIDs := make(map[uint16]string)
+	for name, s := range c.Services {
+		if s == nil {
+			continue
+		}
+		if other, ok := machineIDs[s.MachineID]; ok && s.MachineID != 0 {
+			fail("services.%s.machine_id: %d already used by services.%s", name, s.MachineID, other)
+		}
+		machineIDs[s.MachineID] = name
+		if s.MetricsPort < 0 || s.MetricsPort > 65535 {
+			fail("services.%s.metrics_port: %d out of range", name, s.MetricsPort)
+		}
+	}
+
+	if need&needMySQL != 0 {
+		switch {
+		case c.MySQL == nil:
+			fail("mysql: missing")
+		case c.MySQL.Host == "" || c.MySQL.Database == "":
+			fail("mysql: host and database are required")
+		case c.MySQL.Port <= 0 || c.MySQL.Port > 65535:
+			fail("mysql.port: %d out of range", c.MySQL.Port)
+		}
+	}
+
+	if need&needRedis != 0 {
+		switch {
+		case c.Redis == nil:
+			fail("redis: missing")
+		case c.Redis.Host == "":
+			fail("redis.host: required")
+		case c.Redis.Port <= 0 || c.Redis.Port > 65535:
+			fail("redis.port: %d out of range", c.Redis.Port)
+		}
+	}
+
+	if need&needKafka != 0 {
+		switch {
+		case c.Kafka == nil:
+			fail("kafka: missing")
+		case len(c.Kafka.Brokers) == 0:
+			fail("kafka.brokers: required")
+		case c.Kafka.Topic == "":
+			fail("kafka.topic: required")
+		case c.Kafka.CommentTopic == "":
+			fail("kafka.comment_topic: required")
+		case c.Kafka.BatchSize <= 0:
+			fail("kafka.batch_size: must be positive")
+		}
+	}
+
+	if need&needEtcd != 0 {
+		if c.Etcd == nil || len(c.Etcd.Endpoints()) == 0 {
+			fail("etcd.address: required")
+		} else if b := c.Etcd.Balancer; b != "" && b != "round_robin" && b != "weighted" {
+			fail("etcd.balancer: unknown balancer %q", b)
+		}
+	}
+
+	if need&needJWT != 0 && (c.Server == nil || c.Server.JwtSecret == "") {
+		fail("server.jwtSecret: required")
+	}
+	if c.Server != nil {
+		for _, proxy := range c.Server.TrustedProxies {
+			if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+				fail("server.trusted_proxies: %q is not an IP or CIDR", proxy)
+			}
+		}
+	}
+
+	if c.Log != nil {
+		switch c.Log.Level {
+		case "", "debug", "info", "warn", "error":
+		default:
+			fail("log.level: unknown level %q", c.Log.Level)
+		}
+		if c.Log.MaxSize < 0 || c.Log.MaxBackups < 0 || c.Log.MaxAge < 0 {
+			fail("log: max_size, max_backups and max_age must not be negative")
+		}
+	}
+
+	for name, d := range c.Downstreams {
+		if d == nil {
+			continue
+		}
+		if d.Timeout < 0 {
+			fail("downstreams.%s.timeout: must not be negative", name)
+		}
+		if r := d.Retry; r != nil && r.MaxAttempts > 1 {
+			if r.MaxAttempts > 5 {
+				fail("downstreams.%s.retry.max_attempts: %d exceeds 5", name, r.MaxAttempts)
+			}
+			if r.InitialBackoff <= 0 || r.MaxBackoff < r.InitialBackoff || r.BackoffMultiplier <= 0 {
+				fail("downstreams.%s.retry: initial_backoff, max_backoff and backoff_multiplier must be positive and max_backoff >= initial_backoff", name)
+			}
+		}
+		if b := d.Breaker; b != nil && b.FailureThreshold > 0 && b.OpenTimeout <= 0 {
+			fail("downstreams.%s.breaker.open_timeout: must be positive", name)
+		}
+	}
+
+	if cc := c.Cache; cc != nil && cc.Enabled {
+		if cc.PostTTL <= 0 || cc.CommunityTTL <= 0 {
+			fail("cache: post_ttl and community_ttl must be positive")
+		}
+		if cc.LocalSize < 0 || (cc.LocalSize > 0 && cc.LocalTTL <= 0) {
+			fail("cache: local_size must not be negative and local_ttl must be positive when local_size > 0")
+		}
+	}
+
+	if err := c.RateLimit.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if t := c.Tracing; t != nil && t.Enabled {
+		if t.Exporter != "stdout" && t.Exporter != "file" {
+			fail("tracing.exporter: unknown exporter %q", t.Exporter)
+		}
+		if t.SampleRatio < 0 || t.SampleRatio > 1 {
+			fail("tracing.sample_ratio: %v not in [0, 1]", t.SampleRatio)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid config for %s:\n%w", service, err)
+	}
+	return nil
+}
+
+// validate 校验限流配置，热更新时同样需要通过校验
+func (r *RateLimit) validate() error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	for name, p := range r.Policies {
+		if p == nil {
+			continue
+		}
+		if p.Scope != "ip" && p.Scope != "user" {
+			errs = append(errs, fmt.Errorf("rate_limit.policies.%s.scope: unknown scope %q", name, p.Scope))
+		}
+		if p.Limit <= 0 || p.Window <= 0 {
+			errs = append(errs, fmt.Errorf("rate_limit.policies.%s: limit and window must be positive", name))
+		}
+	}
+	if l := r.LoginLockout; l != nil && l.MaxFailures > 0 && (l.Window <= 0 || l.LockDuration <= 0) {
+		errs = append(errs, fmt.Errorf("rate_limit.login_lockout: window and lock_duration must be positive"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce 编辑器保存文件时会触发多个事件，合并为一次重新加载
+const reloadDebounce = 500 * time.Millisecond
+
+// hotFields 可以热更新的顶层配置，其余配置变更需重启后生效
+var hotFields = map[string]bool{
+	"Log":        true,
+	"RateLimit":  true,
+	"Validation": true,
+}
+
+// activeLoader InitConfig 使用的加载来源
+var activeLoader *loader
+
+// Watch 监听配置文件变更：重新加载并校验通过后，更新可热更新的字段（日志级别、限流、屏蔽词）并调用 onReload，
+// restart 为发生变化但需要重启才能生效的配置项；加载或校验失败时保留当前配置并调用 onError
+func Watch(onReload func(conf *Config, restart []string), onError func(err error)) (stop func() error, err error) {
+	l := activeLoader
+	if l == nil {
+		return nil, errors.New("config not initialized")
+	}
+	if len(l.files) == 0 {
+		return func() error { return nil }, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// 监听目录而不是文件，编辑器和 Kubernetes ConfigMap 都是通过替换文件的方式更新
+	dir := filepath.Dir(l.files[0])
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	names := map[string]bool{
+		filepath.Base(l.files[0]):            true,
+		l.service + filepath.Ext(l.files[0]): true,
+	}
+
+	var (
+		mu    sync.Mutex
+		timer *time.Timer
+	)
+	reload := func() {
+		next, restart, err := reloadConfig(l.service)
+		if err != nil {
+			onError(err)
+			return
+		}
+		onReload(next, restart)
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				name := filepath.Base(event.Name)
+				if !names[name] && !strings.HasPrefix(name, "..") {
+					continue
+				}
+				mu.Lock()
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(reloadDebounce, reload)
+				mu.Unlock()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				onError(err)
+			}
+		}
+	}()
+
+	return func() error {
+		mu.Lock()
+		if timer != nil {
+			timer.Stop()
+		}
+		mu.Unlock()
+		return watcher.Close()
+	}, nil
+}
+
+// reloadConfig 重新加载配置，只替换可热更新的字段
+func reloadConfig(service string) (*Config, []string, error) {
+	l, err := newLoader(service)
+	if err != nil {
+		return nil, nil, err
+	}
+	loaded, err := l.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := loaded.Validate(service); err != nil {
+		return nil, nil, err
+	}
+
+	old := Get()
+	next := *old
+	var restart []string
+	ov, lv, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(loaded).Elem(), reflect.ValueOf(&next).Elem()
+	for i := 0; i < ov.NumField(); i++ {
+		field := ov.Type().Field(i)
+		if reflect.DeepEqual(ov.Field(i).Interface(), lv.Field(i).Interface()) {
+			continue
+		}
+		if hotFields[field.Name] {
+			nv.Field(i).Set(lv.Field(i))
+		} else {
+			restart = append(restart, field.Tag.Get("mapstructure"))
+		}
+	}
+
+	current.Store(&next)
+	return &next, restart, nil
+}
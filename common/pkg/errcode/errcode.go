@@ -0,0 +1,247 @@
+package errcode
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain 错误详情 ErrorInfo 中的域名
+const Domain = "bluebell"
+
+// 通用错误原因，作为 ErrorInfo.Reason 及 BFF 返回的 code，取值稳定、供客户端判断
+const (
+	ReasonInvalidArgument    = "INVALID_ARGUMENT"
+	ReasonUnauthenticated    = "UNAUTHENTICATED"
+	ReasonPermissionDenied   = "PERMISSION_DENIED"
+	ReasonNotFound           = "NOT_FOUND"
+	ReasonAlreadyExists      = "ALREADY_EXISTS"
+	ReasonFailedPrecondition = "FAILED_PRECONDITION"
+	ReasonConflict           = "CONFLICT"
+	ReasonRateLimited        = "RATE_LIMITED"
+	ReasonUnavailable        = "UNAVAILABLE"
+	ReasonDeadlineExceeded   = "DEADLINE_EXCEEDED"
+	ReasonCanceled           = "CANCELED"
+	ReasonInternal           = "INTERNAL"
+)
+
+// 通用错误
+var (
+	ErrInvalidArgument  = New(codes.InvalidArgument, ReasonInvalidArgument, "请求参数错误")
+	ErrUnauthenticated  = New(codes.Unauthenticated, ReasonUnauthenticated, "请先登录")
+	ErrPermissionDenied = New(codes.PermissionDenied, ReasonPermissionDenied, "权限不足")
+	ErrNotFound         = New(codes.NotFound, ReasonNotFound, "资源不存在")
+	ErrAlreadyExists    = New(codes.AlreadyExists, ReasonAlreadyExists, "资源已存在")
+	ErrInternal         = New(codes.Internal, ReasonInternal, "服务繁忙，请稍后再试")
+)
+
+// Error 业务错误：gRPC 状态码 + 稳定的错误原因 + 面向用户的提示
+type Error struct {
+	code     codes.Code
+	reason   string
+	message  string
+	metadata map[string]string
+	cause    error
+}
+
+// New 创建业务错误，通常定义为包级变量供 errors.Is 判断
+func New(code codes.Code, reason, message string) *Error {
+	return &Error{code: code, reason: reason, message: message}
+}
+
+// NotFound 创建 NotFound 类错误
+func NotFound(reason, message string) *Error {
+	return New(codes.NotFound, reason, message)
+}
+
+// AlreadyExists 创建 AlreadyExists 类错误
+func AlreadyExists(reason, message string) *Error {
+	return New(codes.AlreadyExists, reason, message)
+}
+
+// InvalidArgument 创建 InvalidArgument 类错误
+func InvalidArgument(reason, message string) *Error {
+	return New(codes.InvalidArgument, reason, message)
+}
+
+// PermissionDenied 创建 PermissionDenied 类错误
+func PermissionDenied(reason, message string) *Error {
+	return New(codes.PermissionDenied, reason, message)
+}
+
+// Unauthenticated 创建 Unauthenticated 类错误
+func Unauthenticated(reason, message string) *Error {
+	return New(codes.Unauthenticated, reason, message)
+}
+
+// FailedPrecondition 创建 FailedPrecondition 类错误
+func FailedPrecondition(reason, message string) *Error {
+	return New(codes.FailedPrecondition, reason, message)
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.message + ": " + e.cause.Error()
+	}
+	return e.message
+}
+
+// Code 返回 gRPC 状态码
+func (e *Error) Code() codes.Code { return e.code }
+
+// Reason 返回错误原因
+func (e *Error) Reason() string { return e.reason }
+
+// Message 返回面向用户的提示
+func (e *Error) Message() string { return e.message }
+
+// Unwrap 返回底层原因
+func (e *Error) Unwrap() error { return e.cause }
+
+// Is 同一状态码和原因的业务错误视为相同，使 WithMessage 等派生出的错误仍能被 errors.Is 识别
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.code == t.code && e.reason == t.reason
+}
+
+// WithMessage 返回替换了提示信息的副本
+func (e *Error) WithMessage(message string) *Error {
+	cp := e.clone()
+	cp.message = message
+	return cp
+}
+
+// WithMetadata 返回附加了元数据的副本，元数据随 ErrorInfo 传给调用方
+func (e *Error) WithMetadata(key, value string) *Error {
+	cp := e.clone()
+	cp.metadata = make(map[string]string, len(e.metadata)+1)
+	for k, v := range e.metadata {
+		cp.metadata[k] = v
+	}
+	cp.metadata[key] = value
+	return cp
+}
+
+// Wrap 返回记录了底层原因的副本，底层原因只用于日志，不会传给调用方
+func (e *Error) Wrap(cause error) *Error {
+	cp := e.clone()
+	cp.cause = cause
+	return cp
+}
+
+func (e *Error) clone() *Error {
+	cp := *e
+	return &cp
+}
+
+// GRPCStatus 转换为携带 ErrorInfo 详情的 gRPC 状态
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.code, e.message)
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   e.reason,
+		Domain:   Domain,
+		Metadata: e.metadata,
+	})
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// ToStatus 将任意错误转换为可以直接返回给 gRPC 框架的错误：
+// 已携带 gRPC 状态的错误原样返回，上下文取消/超时转换为对应状态码，其余错误一律视为内部错误且不暴露细节
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		return grpcErr.GRPCStatus().Err()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return New(codes.Canceled, ReasonCanceled, "请求已取消").GRPCStatus().Err()
+	case errors.Is(err, context.DeadlineExceeded):
+		return New(codes.DeadlineExceeded, ReasonDeadlineExceeded, "请求超时").GRPCStatus().Err()
+	default:
+		return ErrInternal.GRPCStatus().Err()
+	}
+}
+
+// Info 从 gRPC 错误中解析出的错误信息，供调用方（BFF）使用
+type Info struct {
+	Code            codes.Code
+	Reason          string
+	Message         string
+	Metadata        map[string]string
+	FieldViolations []*errdetails.BadRequest_FieldViolation
+}
+
+// FromError 解析 gRPC 错误；没有 ErrorInfo 详情时根据状态码推导错误原因
+func FromError(err error) *Info {
+	st := status.Convert(err)
+	info := &Info{
+		Code:    st.Code(),
+		Reason:  reasonOf(st.Code()),
+		Message: st.Message(),
+	}
+	for _, d := range st.Details() {
+		switch detail := d.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.Reason != "" {
+				info.Reason = detail.Reason
+			}
+			info.Metadata = detail.Metadata
+		case *errdetails.BadRequest:
+			info.FieldViolations = append(info.FieldViolations, detail.FieldViolations...)
+		}
+	}
+	return info
+}
+
+// ReasonOf 返回错误携带的错误原因
+func ReasonOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.reason
+	}
+	return FromError(err).Reason
+}
+
+// reasonOf 没有业务原因时由状态码推导
+func reasonOf(code codes.Code) string {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return ReasonInvalidArgument
+	case codes.Unauthenticated:
+		return ReasonUnauthenticated
+	case codes.PermissionDenied:
+		return ReasonPermissionDenied
+	case codes.NotFound:
+		return ReasonNotFound
+	case codes.AlreadyExists:
+		return ReasonAlreadyExists
+	case codes.FailedPrecondition:
+		return ReasonFailedPrecondition
+	case codes.Aborted:
+		return ReasonConflict
+	case codes.ResourceExhausted:
+		return ReasonRateLimited
+	case codes.Unavailable:
+		return ReasonUnavailable
+	case codes.DeadlineExceeded:
+		return ReasonDeadlineExceeded
+	case codes.Canceled:
+		return ReasonCanceled
+	default:
+		return ReasonInternal
+	}
+}
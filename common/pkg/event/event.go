@@ -0,0 +1,34 @@
+package event
+
+import (
+	"fmt"
+)
+
+// 帖子实时事件类型
+const (
+	TypeVote    = "vote"    // 投票总数变化
+	TypeComment = "comment" // 新增评论
+)
+
+// PostEvent 帖子实时事件，各服务通过 Redis pub/sub 发布，post-service 转发给订阅方
+type PostEvent struct {
+	Type       string `json:"type"`
+	PostID     int64  `json:"post_id"`
+	VoteNum    int64  `json:"vote_num,omitempty"`
+	CommentID  int64  `json:"comment_id,omitempty"`
+	ParentID   int64  `json:"parent_id,omitempty"`
+	AuthorID   int64  `json:"author_id,omitempty"`
+	Content    string `json:"content,omitempty"`
+	CreateTime string `json:"create_time,omitempty"`
+}
+
+// PostChannel 返回指定帖子的事件频道
+func PostChannel(postID int64) string {
+	return fmt.Sprintf("bluebell-plus:post:events:%d", postID)
+}
+
+// Subscription 帖子事件订阅，Close 之后 Events 返回的 channel 会被关闭
+type Subscription interface {
+	Events() <-chan *PostEvent
+	Close() error
+}
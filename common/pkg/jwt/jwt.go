@@ -11,11 +11,19 @@ import (
 type MyClaims struct {
 	UserID   uint64 `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.StandardClaims
 }
 
 var mySecret = []byte("bluebell-plus")
 
+// Init 设置签名密钥，各服务需使用相同的 server.jwtSecret；secret 为空时保留默认密钥
+func Init(secret string) {
+	if secret != "" {
+		mySecret = []byte(secret)
+	}
+}
+
 func keyFunc(_ *jwt.Token) (interface{}, error) {
 	return mySecret, nil
 }
@@ -24,13 +32,23 @@ const TokenExpireDuration = time.Hour * 24
 const AccessTokenExpireDuration = time.Hour * 24 * 1000
 const RefreshTokenExpireDuration = time.Hour * 24 * 1000
 
-func GenToken(userID uint64, username string) (aToken, rToken string, err error) {
+// ServiceTokenExpireDuration BFF 转发给下游服务的 token 有效期，其中的角色是 BFF 查询到的当前角色
+const ServiceTokenExpireDuration = 5 * time.Minute
+
+// token 签发者：用户登录获得的 token 及 BFF 转发给下游服务的 token 不能混用
+const (
+	userIssuer    = "bluebell-plus"
+	serviceIssuer = "bluebell-plus-bff"
+)
+
+func GenToken(userID uint64, username, role string) (aToken, rToken string, err error) {
 	c := MyClaims{
 		userID,
 		username,
+		role,
 		jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(AccessTokenExpireDuration).Unix(),
-			Issuer:    "bluebell-plus",
+			Issuer:    userIssuer,
 		},
 	}
 	aToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(mySecret)
@@ -40,13 +58,37 @@ func GenToken(userID uint64, username string) (aToken, rToken string, err error)
 
 	rToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
 		ExpiresAt: time.Now().Add(RefreshTokenExpireDuration).Unix(),
-		Issuer:    "bluebell-plus",
+		Issuer:    userIssuer,
 	}).SignedString(mySecret)
 	return
 }
 
+// GenServiceToken BFF 校验用户 token 并查询到当前角色后，签发转发给下游服务的短期 token
+func GenServiceToken(userID uint64, username, role string) (string, error) {
+	c := MyClaims{
+		userID,
+		username,
+		role,
+		jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ServiceTokenExpireDuration).Unix(),
+			Issuer:    serviceIssuer,
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(mySecret)
+}
+
+// ParseToken 解析用户登录获得的 Access Token；其中的角色是签发时的角色，鉴权时需以当前角色为准
 func ParseToken(tokenString string) (claims *MyClaims, err error) {
-	claims = new(MyClaims)
+	return parse(tokenString, userIssuer)
+}
+
+// ParseServiceToken 解析 BFF 转发的 token，不接受用户 token
+func ParseServiceToken(tokenString string) (claims *MyClaims, err error) {
+	return parse(tokenString, serviceIssuer)
+}
+
+func parse(tokenString, issuer string) (*MyClaims, error) {
+	claims := new(MyClaims)
 	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
 	if err != nil {
 		return nil, err
@@ -54,10 +96,14 @@ func ParseToken(tokenString string) (claims *MyClaims, err error) {
 	if !token.Valid {
 		return nil, errors.New("invalid token")
 	}
+	if claims.Issuer != issuer {
+		return nil, fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+	}
 	return claims, nil
 }
 
-func RefreshToken(aToken, rToken string) (newAToken, newRToken string, err error) {
+// RefreshToken Access Token 过期后用 Refresh Token 换取新 token，新 token 中的角色由 currentRole 查询当前角色
+func RefreshToken(aToken, rToken string, currentRole func(userID uint64) (string, error)) (newAToken, newRToken string, err error) {
 	if aToken == "" {
 		return "", "", fmt.Errorf("access token is empty")
 	}
@@ -77,8 +123,15 @@ func RefreshToken(aToken, rToken string) (newAToken, newRToken string, err error
 		// 检查是否是 ValidationError
 		if v, ok := err.(*jwt.ValidationError); ok {
 			if v.Errors&jwt.ValidationErrorExpired != 0 {
-				// Access Token 过期，生成新 token
-				return GenToken(claims.UserID, claims.Username)
+				// Access Token 过期，生成新 token；不沿用旧 token 中的角色
+				if claims.Issuer != userIssuer {
+					return "", "", fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+				}
+				role, err := currentRole(claims.UserID)
+				if err != nil {
+					return "", "", err
+				}
+				return GenToken(claims.UserID, claims.Username, role)
 			}
 			return "", "", fmt.Errorf("access token validation error: %v", v.Error())
 		}
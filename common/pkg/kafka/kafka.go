@@ -4,17 +4,23 @@ import (
 	"bluebell_microservices/common/pkg/logger"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 )
 
 // KafkaConfig Kafka配置
 type KafkaConfig struct {
-	Brokers []string
-	Topic   string
+	Brokers  []string
+	Topic    string
+	DLQTopic string // 消费者无法处理的消息转入的死信 topic，为空时只记录指标
+	Group    string // 消费者组，为空时使用 post-service-group
 }
 
 // VoteMessage 投票消息结构
@@ -25,6 +31,21 @@ type VoteMessage struct {
 	Timestamp int64 `json:"timestamp"`
 }
 
+// 评论事件类型
+const (
+	CommentCreated = "created"
+	CommentDeleted = "deleted"
+)
+
+// CommentMessage 评论创建、删除事件，由 comment-service 发送，post-service 据此维护帖子的评论数
+type CommentMessage struct {
+	Type      string `json:"type"` // CommentCreated 或 CommentDeleted
+	CommentID int64  `json:"comment_id"`
+	PostID    int64  `json:"post_id"`
+	AuthorID  int64  `json:"author_id"`
+	Timestamp int64  `json:"timestamp"`
+}
+
 // Producer Kafka生产者
 type Producer struct {
 	producer sarama.SyncProducer
@@ -35,9 +56,15 @@ type Producer struct {
 type Consumer struct {
 	group   sarama.ConsumerGroup
 	topic   string
-	handler func(message VoteMessage) error
+	handler func(ctx context.Context, value []byte) error
 	ready   chan bool
 	topics  []string
+
+	dlq      sarama.SyncProducer
+	dlqTopic string
+
+	stopOnce sync.Once
+	stopErr  error
 }
 
 // NewProducer 创建Kafka生产者
@@ -61,33 +88,52 @@ func NewProducer(config KafkaConfig) (*Producer, error) {
 	}, nil
 }
 
-// SendVoteMessage 发送投票消息
-func (p *Producer) SendVoteMessage(message VoteMessage) error {
+// SendVoteMessage 发送投票消息，trace 上下文随消息 header 传递给消费者
+func (p *Producer) SendVoteMessage(ctx context.Context, message VoteMessage) error {
+	return p.send(ctx, fmt.Sprintf("%d-%d", message.PostID, message.UserID), message,
+		zap.Int64("post_id", message.PostID),
+		zap.Int64("user_id", message.UserID),
+		zap.Int64("direction", message.Direction))
+}
+
+// SendCommentMessage 发送评论事件，同一帖子的事件使用相同的 key，保证按发送顺序消费
+func (p *Producer) SendCommentMessage(ctx context.Context, message CommentMessage) error {
+	return p.send(ctx, strconv.FormatInt(message.PostID, 10), message,
+		zap.String("type", message.Type),
+		zap.Int64("comment_id", message.CommentID),
+		zap.Int64("post_id", message.PostID))
+}
+
+// send 将 message 编码为 JSON 后发送，fields 用于日志
+func (p *Producer) send(ctx context.Context, key string, message interface{}, fields ...zap.Field) error {
 	jsonData, err := json.Marshal(message)
 	if err != nil {
-		logger.Error("Failed to marshal vote message", zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to marshal message", zap.Error(err))
 		return err
 	}
 
 	msg := &sarama.ProducerMessage{
 		Topic: p.topic,
 		Value: sarama.StringEncoder(jsonData),
-		Key:   sarama.StringEncoder(fmt.Sprintf("%d-%d", message.PostID, message.UserID)),
+		Key:   sarama.StringEncoder(key),
 	}
 
+	ctx, span := startProducerSpan(ctx, msg)
+	defer span.End()
+
 	partition, offset, err := p.producer.SendMessage(msg)
 	if err != nil {
-		logger.Error("Failed to send message to Kafka", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Ctx(ctx).Error("Failed to send message to Kafka", zap.Error(err))
 		return err
 	}
 
-	logger.Info("Message sent to Kafka",
+	logger.Ctx(ctx).Info("Message sent to Kafka", append([]zap.Field{
 		zap.String("topic", p.topic),
 		zap.Int32("partition", partition),
 		zap.Int64("offset", offset),
-		zap.Int64("post_id", message.PostID),
-		zap.Int64("user_id", message.UserID),
-		zap.Int64("direction", message.Direction))
+	}, fields...)...)
 
 	return nil
 }
@@ -97,6 +143,28 @@ func (p *Producer) Close() error {
 	return p.producer.Close()
 }
 
+// CheckBrokers 检查是否至少有一个 broker 可以连通，用于健康检查
+func CheckBrokers(brokers []string, timeout time.Duration) error {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Net.DialTimeout = timeout
+
+	var lastErr error = errors.New("no broker configured")
+	for _, addr := range brokers {
+		broker := sarama.NewBroker(addr)
+		if err := broker.Open(saramaConfig); err != nil {
+			lastErr = err
+			continue
+		}
+		connected, err := broker.Connected()
+		_ = broker.Close()
+		if connected {
+			return nil
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("kafka brokers unreachable: %v", lastErr)
+}
+
 // NewConsumer 创建Kafka消费者
 func NewConsumer(config KafkaConfig) (*Consumer, error) {
 	saramaConfig := sarama.NewConfig()
@@ -110,22 +178,70 @@ func NewConsumer(config KafkaConfig) (*Consumer, error) {
 	saramaConfig.Consumer.Group.Heartbeat.Interval = 6 * time.Second
 
 	// 创建消费者组
-	group, err := sarama.NewConsumerGroup(config.Brokers, "post-service-group", saramaConfig)
+	groupID := config.Group
+	if groupID == "" {
+		groupID = "post-service-group"
+	}
+	group, err := sarama.NewConsumerGroup(config.Brokers, groupID, saramaConfig)
 	if err != nil {
 		logger.Error("Failed to create consumer group", zap.Error(err))
 		return nil, err
 	}
 
-	return &Consumer{
-		group:  group,
-		topic:  config.Topic,
-		ready:  make(chan bool),
-		topics: []string{config.Topic},
-	}, nil
+	consumer := &Consumer{
+		group:    group,
+		topic:    config.Topic,
+		ready:    make(chan bool),
+		topics:   []string{config.Topic},
+		dlqTopic: config.DLQTopic,
+	}
+
+	// 死信队列生产者
+	if config.DLQTopic != "" {
+		dlqConfig := sarama.NewConfig()
+		dlqConfig.Producer.Return.Successes = true
+		dlqConfig.Producer.RequiredAcks = sarama.WaitForAll
+		dlqConfig.Producer.Retry.Max = 5
+		consumer.dlq, err = sarama.NewSyncProducer(config.Brokers, dlqConfig)
+		if err != nil {
+			logger.Error("Failed to create DLQ producer", zap.Error(err))
+			group.Close()
+			return nil, err
+		}
+	}
+
+	return consumer, nil
+}
+
+// decodeError 消息无法解析，直接转入死信队列
+type decodeError struct{ err error }
+
+func (e decodeError) Error() string { return e.err.Error() }
+
+// ConsumeMessages 消费投票消息，handler 的 ctx 携带从消息 header 中恢复的 trace 上下文
+func (c *Consumer) ConsumeMessages(handler func(ctx context.Context, message VoteMessage) error) error {
+	return c.consume(func(ctx context.Context, value []byte) error {
+		var message VoteMessage
+		if err := json.Unmarshal(value, &message); err != nil {
+			return decodeError{err}
+		}
+		return handler(ctx, message)
+	})
 }
 
-// ConsumeMessages 消费消息
-func (c *Consumer) ConsumeMessages(handler func(message VoteMessage) error) error {
+// ConsumeCommentMessages 消费评论事件，handler 的 ctx 携带从消息 header 中恢复的 trace 上下文
+func (c *Consumer) ConsumeCommentMessages(handler func(ctx context.Context, message CommentMessage) error) error {
+	return c.consume(func(ctx context.Context, value []byte) error {
+		var message CommentMessage
+		if err := json.Unmarshal(value, &message); err != nil {
+			return decodeError{err}
+		}
+		return handler(ctx, message)
+	})
+}
+
+// consume 启动消费者组，handler 处理原始消息
+func (c *Consumer) consume(handler func(ctx context.Context, value []byte) error) error {
 	c.handler = handler
 
 	// 启动消费者组
@@ -135,12 +251,12 @@ func (c *Consumer) ConsumeMessages(handler func(message VoteMessage) error) erro
 			// server-side rebalance happens, the consumer session will need to be
 			// recreated to get the new claims
 			if err := c.group.Consume(context.Background(), c.topics, c); err != nil {
+				// 消费者组已关闭，退出循环
+				if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+					return
+				}
 				logger.Error("Error from consumer", zap.Error(err))
 			}
-			// check if context was cancelled, signaling that the consumer should stop
-			if context.Background().Err() != nil {
-				return
-			}
 			c.ready = make(chan bool)
 		}
 	}()
@@ -165,24 +281,81 @@ func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error {
 
 // ConsumeClaim must start a consumer loop of ConsumerGroupClaim's Messages().
 func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	lag := consumerLag.WithLabelValues(claim.Topic(), strconv.Itoa(int(claim.Partition())))
 	for message := range claim.Messages() {
-		var voteMsg VoteMessage
-		if err := json.Unmarshal(message.Value, &voteMsg); err != nil {
-			logger.Error("Failed to unmarshal vote message", zap.Error(err))
-			continue
-		}
+		ctx, span := startConsumerSpan(message)
+		consumedMessages.WithLabelValues(message.Topic).Inc()
+		lag.Set(float64(claim.HighWaterMarkOffset() - message.Offset - 1))
 
-		if err := c.handler(voteMsg); err != nil {
-			logger.Error("Failed to process vote message", zap.Error(err))
+		if err := c.handler(ctx, message.Value); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			reason := DLQReasonHandler
+			if errors.As(err, new(decodeError)) {
+				reason = DLQReasonDecode
+			}
+			logger.Ctx(ctx).Error("Failed to process message", zap.String("topic", message.Topic), zap.String("reason", reason), zap.Error(err))
+			c.sendToDLQ(ctx, message.Key, message.Value, reason)
 		}
 
 		session.MarkMessage(message, "")
+		span.End()
 	}
 
 	return nil
 }
 
-// Close 关闭消费者
+// DeadLetter 将已消费但最终处理失败的投票消息转入死信队列
+func (c *Consumer) DeadLetter(ctx context.Context, message VoteMessage, reason string) {
+	value, err := json.Marshal(message)
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to marshal dead letter", zap.Error(err))
+		return
+	}
+	key := []byte(fmt.Sprintf("%d-%d", message.PostID, message.UserID))
+	c.sendToDLQ(ctx, key, value, reason)
+}
+
+// sendToDLQ 发送到死信 topic，未配置时只记录指标
+func (c *Consumer) sendToDLQ(ctx context.Context, key, value []byte, reason string) {
+	dlqMessages.WithLabelValues(c.topic, reason).Inc()
+	if c.dlq == nil {
+		return
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: c.dlqTopic,
+		Key:   sarama.ByteEncoder(key),
+		Value: sarama.ByteEncoder(value),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("dlq-source-topic"), Value: []byte(c.topic)},
+			{Key: []byte("dlq-reason"), Value: []byte(reason)},
+		},
+	}
+	ctx, span := startProducerSpan(ctx, msg)
+	defer span.End()
+	if _, _, err := c.dlq.SendMessage(msg); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Ctx(ctx).Error("Failed to send message to DLQ", zap.String("dlq_topic", c.dlqTopic), zap.Error(err))
+	}
+}
+
+// Stop 停止拉取消息，死信生产者仍可用，便于调用方在退出前处理完已拉取的消息
+func (c *Consumer) Stop() error {
+	c.stopOnce.Do(func() {
+		c.stopErr = c.group.Close()
+	})
+	return c.stopErr
+}
+
+// Close 停止拉取并关闭死信生产者
 func (c *Consumer) Close() error {
-	return c.group.Close()
+	err := c.Stop()
+	if c.dlq != nil {
+		if dlqErr := c.dlq.Close(); err == nil {
+			err = dlqErr
+		}
+	}
+	return err
 }
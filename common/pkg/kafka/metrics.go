@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"bluebell_microservices/common/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 死信原因
+const (
+	DLQReasonDecode  = "decode"
+	DLQReasonHandler = "handler"
+	DLQReasonPersist = "persist"
+)
+
+var (
+	consumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "kafka_consumer",
+		Name:      "lag",
+		Help:      "消费者落后于分区最新消息的条数",
+	}, []string{"topic", "partition"})
+
+	consumedMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "kafka_consumer",
+		Name:      "messages_total",
+		Help:      "消费者收到的消息数",
+	}, []string{"topic"})
+
+	dlqMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "kafka",
+		Name:      "dlq_messages_total",
+		Help:      "转入死信队列的消息数（未配置死信 topic 时为无法处理而丢弃的消息数）",
+	}, []string{"topic", "reason"})
+)
@@ -1,30 +1,32 @@
 package logger
 
 import (
+	"context"
+	"net/http"
 	"os"
+	"path/filepath"
 
+	"bluebell_microservices/common/config"
+
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var Logger *zap.Logger
+// Logger 全局 Logger，Init 之前为不输出任何内容的 Nop Logger（如单元测试中）
+var Logger = zap.NewNop()
 
-// Init 初始化日志
-func Init(logLevel string, logFile string) error {
-	// 配置日志级别
-	var level zapcore.Level
-	switch logLevel {
-	case "debug":
-		level = zapcore.DebugLevel
-	case "info":
-		level = zapcore.InfoLevel
-	case "warn":
-		level = zapcore.WarnLevel
-	case "error":
-		level = zapcore.ErrorLevel
-	default:
-		level = zapcore.InfoLevel
+// level 当前日志级别，可通过 SetLevel 在运行时调整
+var level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
+// Init 初始化日志，name 为服务名，日志写入 <dir>/<name>.log 并按大小切割，同时输出到控制台
+func Init(name string, conf *config.Log) error {
+	if conf == nil {
+		conf = &config.Log{}
 	}
+	// 配置日志级别
+	SetLevel(conf.Level)
 
 	// 配置编码器
 	encoderConfig := zapcore.EncoderConfig{
@@ -41,27 +43,56 @@ func Init(logLevel string, logFile string) error {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
-	// 文件输出
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
+	// 文件输出，按大小切割
+	if conf.Dir != "" {
+		if err := os.MkdirAll(conf.Dir, 0755); err != nil {
+			return err
+		}
+	}
+	file := &lumberjack.Logger{
+		Filename:   filepath.Join(conf.Dir, name+".log"),
+		MaxSize:    conf.MaxSize,
+		MaxBackups: conf.MaxBackups,
+		MaxAge:     conf.MaxAge,
+		Compress:   conf.Compress,
+		LocalTime:  true,
 	}
 
-	// 配置 core
-	core := zapcore.NewCore(
+	// 配置 core，敏感字段在编码前脱敏
+	core := newRedactCore(zapcore.NewCore(
 		zapcore.NewJSONEncoder(encoderConfig), // JSON 格式
 		zapcore.NewMultiWriteSyncer(
 			zapcore.AddSync(file),      // 输出到文件
 			zapcore.AddSync(os.Stdout), // 输出到控制台
 		),
 		level,
-	)
+	))
 
 	// 创建 Logger
 	Logger = zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 	return nil
 }
 
+// SetLevel 调整日志级别，未知级别按 info 处理
+func SetLevel(logLevel string) {
+	switch logLevel {
+	case "debug":
+		level.SetLevel(zapcore.DebugLevel)
+	case "warn":
+		level.SetLevel(zapcore.WarnLevel)
+	case "error":
+		level.SetLevel(zapcore.ErrorLevel)
+	default:
+		level.SetLevel(zapcore.InfoLevel)
+	}
+}
+
+// LevelHandler 查看（GET）或修改（PUT {"level":"debug"}）当前日志级别，挂载在各服务的指标端口上
+// 通过接口修改的级别在配置文件下次变更时会被配置中的 log.level 覆盖
+func LevelHandler() http.Handler {
+	return level
+}
+
 // Debug 封装调试日志
 func Debug(msg string, fields ...zap.Field) {
 	Logger.Debug(msg, fields...)
@@ -81,3 +112,20 @@ func Warn(msg string, fields ...zap.Field) {
 func Error(msg string, fields ...zap.Field) {
 	Logger.Error(msg, fields...)
 }
+
+// Ctx 返回带有上下文中 trace_id、span_id 的 Logger，没有有效 span 时返回全局 Logger
+func Ctx(ctx context.Context) *zap.Logger {
+	return withSpan(ctx, Logger)
+}
+
+// withSpan 为 l 附加上下文中的 trace_id、span_id
+func withSpan(ctx context.Context, l *zap.Logger) *zap.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return l
+	}
+	return l.With(
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	)
+}
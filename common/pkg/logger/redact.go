@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted 脱敏后的占位值
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys 字段名（忽略大小写）包含这些词时视为敏感字段
+var sensitiveKeys = []string{"password", "passwd", "token", "authorization", "secret", "cookie"}
+
+// IsSensitiveKey 判断字段名是否为敏感字段
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactCore 在写入前将敏感字段替换为占位值，覆盖 With 和单次日志的字段
+type redactCore struct {
+	zapcore.Core
+}
+
+func newRedactCore(core zapcore.Core) zapcore.Core {
+	return redactCore{Core: core}
+}
+
+func (c redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// redactFields 没有敏感字段时原样返回，避免额外分配
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		if !IsSensitiveKey(f.Key) {
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields)
+		}
+		out[i] = zap.String(f.Key, Redacted)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// RedactJSON 将 JSON 中敏感字段的值替换为占位值，无法解析时整体替换，避免原文泄露
+func RedactJSON(body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return Redacted
+	}
+	data, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return Redacted
+	}
+	return string(data)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if IsSensitiveKey(k) {
+				v[k] = Redacted
+			} else {
+				v[k] = redactValue(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redactValue(val)
+		}
+	}
+	return v
+}
+
+// RedactQuery 将查询字符串中敏感参数的值替换为占位值
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Redacted
+	}
+	redacted := false
+	for k := range values {
+		if IsSensitiveKey(k) {
+			values[k] = []string{Redacted}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return rawQuery
+	}
+	return values.Encode()
+}
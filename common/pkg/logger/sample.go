@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Sampler 按调用点采样的日志，用于循环内等高频日志；每个调用点单独声明一个 Sampler
+// 每个 tick 内同一级别、同一消息的日志先全部输出 first 条，之后每 thereafter 条输出一条
+type Sampler struct {
+	tick       time.Duration
+	first      int
+	thereafter int
+
+	once   sync.Once
+	logger *zap.Logger
+}
+
+// NewSampler 创建采样器，可在包级变量中声明，首次使用时才基于全局 Logger 创建
+func NewSampler(tick time.Duration, first, thereafter int) *Sampler {
+	return &Sampler{tick: tick, first: first, thereafter: thereafter}
+}
+
+// Ctx 返回采样后的 Logger，带有上下文中的 trace_id、span_id
+func (s *Sampler) Ctx(ctx context.Context) *zap.Logger {
+	s.once.Do(func() {
+		s.logger = Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewSamplerWithOptions(core, s.tick, s.first, s.thereafter)
+		}))
+	})
+	return withSpan(ctx, s.logger)
+}
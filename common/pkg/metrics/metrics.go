@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"bluebell_microservices/common/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// Namespace 所有指标的前缀
+const Namespace = "bluebell"
+
+// Path 指标暴露路径
+const Path = "/metrics"
+
+// LogLevelPath 运行时查看、调整日志级别的路径
+const LogLevelPath = "/log/level"
+
+// Server 独立的指标及管理接口监听，与业务端口分开，避免对外暴露
+type Server struct {
+	srv *http.Server
+}
+
+// Serve 在 addr 上启动 /metrics 及 /log/level 监听，addr 为空时不启动
+func Serve(addr string) *Server {
+	if addr == "" {
+		return &Server{}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.Handler())
+	mux.Handle(LogLevelPath, logger.LevelHandler())
+	s := &Server{srv: &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}}
+
+	go func() {
+		logger.Info("Metrics server running", zap.String("addr", addr))
+		if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server stopped unexpectedly", zap.String("addr", addr), zap.Error(err))
+		}
+	}()
+	return s
+}
+
+// Shutdown 关闭指标监听
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s == nil || s.srv == nil {
+		return nil
+	}
+	return s.srv.Shutdown(ctx)
+}
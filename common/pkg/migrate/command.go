@@ -0,0 +1,86 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+)
+
+// Usage migrate 子命令的用法
+const Usage = `usage: <service> [-config file] [-set key=value] migrate <command>
+commands:
+  up        执行全部待执行的迁移
+  down [N]  回滚最近的 N 个版本，默认 1
+  status    列出各版本的执行状态`
+
+// Command 执行 migrate 子命令，args 为 migrate 之后的参数，结果输出到 w
+func Command(ctx context.Context, db *sql.DB, service string, fsys fs.FS, args []string, w io.Writer) error {
+	m, err := New(db, service, fsys)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n%s", Usage)
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s: applied %d migration(s)\n", service, n)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid down steps %q", args[1])
+			}
+		}
+		n, err := m.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s: rolled back %d migration(s)\n", service, n)
+	case "status":
+		applied, err := m.Applied(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(w, service, applied, m.Migrations())
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], Usage)
+	}
+	return nil
+}
+
+// printStatus 按版本列出代码中的迁移及数据库中未知的版本
+func printStatus(w io.Writer, service string, applied []Applied, migrations []*Migration) error {
+	byVersion := make(map[int64]Applied, len(applied))
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+	fmt.Fprintf(w, "%s migrations:\n", service)
+	for _, mig := range migrations {
+		a, ok := byVersion[mig.Version]
+		delete(byVersion, mig.Version)
+		switch {
+		case !ok:
+			fmt.Fprintf(w, "  %04d %-32s pending\n", mig.Version, mig.Name)
+		case a.Dirty:
+			fmt.Fprintf(w, "  %04d %-32s dirty\n", mig.Version, mig.Name)
+		default:
+			fmt.Fprintf(w, "  %04d %-32s applied at %s\n", mig.Version, mig.Name, a.AppliedAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+	for _, a := range applied {
+		if _, ok := byVersion[a.Version]; ok {
+			fmt.Fprintf(w, "  %04d %-32s unknown to this build\n", a.Version, a.Name)
+		}
+	}
+	_, err := verify(applied, migrations)
+	return err
+}
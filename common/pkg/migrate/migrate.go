@@ -0,0 +1,345 @@
+// Package migrate 按版本顺序执行各服务自己的数据库迁移，已执行的版本记录在 schema_migrations 表中
+//
+// 迁移文件放在服务的 migrations 目录并通过 embed 打包，文件名格式为 <版本号>_<描述>.up.sql 及 .down.sql，
+// 版本号为正整数且每个版本必须同时提供 up、down。一个文件可包含多条语句，每条语句以行尾的分号结束，
+// 以 -- 开头的行视为注释。MySQL 的 DDL 不支持事务，迁移中途失败时该版本被标记为 dirty，需人工修复后再执行
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockTimeout 等待其他实例完成迁移的最长时间（秒）
+const lockTimeout = 60
+
+var (
+	// ErrUnknownVersion 数据库中存在当前代码不认识的版本，通常是回滚到了旧版本的代码
+	ErrUnknownVersion = errors.New("unknown schema version")
+	// ErrDirty 某个版本迁移中途失败，表结构处于不确定状态
+	ErrDirty = errors.New("schema is dirty")
+	// ErrPending 存在尚未执行的迁移
+	ErrPending = errors.New("schema has pending migrations")
+)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Applied 已执行的版本
+type Applied struct {
+	Version   int64
+	Name      string
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+var fileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load 读取 fsys 根目录下的迁移文件，按版本升序返回
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		m := fileRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file name %q, want <version>_<name>.up.sql or .down.sql", e.Name())
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", e.Name())
+		}
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has different names: %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements 将迁移文件拆分为单条语句
+func splitStatements(script string) []string {
+	var stmts []string
+	var cur strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteByte('\n')
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(cur.String()), ";"))
+			cur.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(cur.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
+
+// verify 比较已执行的版本与代码中的迁移：存在未知版本或 dirty 版本时返回错误，否则返回待执行的迁移
+func verify(applied []Applied, migrations []*Migration) ([]*Migration, error) {
+	known := make(map[int64]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = true
+	}
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		if a.Dirty {
+			return nil, fmt.Errorf("%w: version %d (%s) failed halfway, fix the schema manually and delete the row from schema_migrations", ErrDirty, a.Version, a.Name)
+		}
+		if !known[a.Version] {
+			return nil, fmt.Errorf("%w: database is at version %d (%s) which this build does not know", ErrUnknownVersion, a.Version, a.Name)
+		}
+		done[a.Version] = true
+	}
+
+	var pending []*Migration
+	for _, m := range migrations {
+		if !done[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Migrator 执行一个服务的迁移
+type Migrator struct {
+	db         *sql.DB
+	service    string
+	migrations []*Migration
+}
+
+// New 读取 fsys 中的迁移，service 用于区分共用同一数据库的各服务
+func New(db *sql.DB, service string, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, service: service, migrations: migrations}, nil
+}
+
+// Migrations 代码中的全部迁移
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+const createTableSQL = "CREATE TABLE IF NOT EXISTS `schema_migrations` (" +
+	"`service` varchar(32) NOT NULL COMMENT '服务名'," +
+	"`version` bigint NOT NULL COMMENT '迁移版本'," +
+	"`name` varchar(128) NOT NULL DEFAULT '' COMMENT '迁移描述'," +
+	"`dirty` tinyint NOT NULL DEFAULT 0 COMMENT '1-执行中或执行失败'," +
+	"`applied_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+	"PRIMARY KEY (`service`, `version`)" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci"
+
+// queryer *sql.DB 与 *sql.Conn 共有的方法
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Applied 已执行的版本，按版本升序；schema_migrations 表不存在时先创建
+func (m *Migrator) Applied(ctx context.Context) ([]Applied, error) {
+	return m.applied(ctx, m.db)
+}
+
+func (m *Migrator) applied(ctx context.Context, q queryer) ([]Applied, error) {
+	if _, err := q.ExecContext(ctx, createTableSQL); err != nil {
+		return nil, fmt.Errorf("create schema_migrations failed: %w", err)
+	}
+	rows, err := q.QueryContext(ctx,
+		"SELECT version, name, dirty, applied_at FROM schema_migrations WHERE service = ? ORDER BY version", m.service)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Applied
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.Name, &a.Dirty, &a.AppliedAt); err != nil { // DSN 需开启 parseTime
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// Check 确认数据库已执行全部迁移且没有未知版本，服务启动前调用
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return err
+	}
+	pending, err := verify(applied, m.migrations)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d migration(s) starting at version %d, run `migrate up` or enable mysql.auto_migrate",
+			ErrPending, len(pending), pending[0].Version)
+	}
+	return nil
+}
+
+// Up 执行全部待执行的迁移，返回执行的版本数；多个实例同时启动时通过 MySQL 命名锁串行执行
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	var n int
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		pending, err := verify(applied, m.migrations)
+		if err != nil {
+			return err
+		}
+		for _, mig := range pending {
+			if err := m.apply(ctx, conn, mig, true); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// Down 回滚最近执行的 steps 个版本，返回回滚的版本数
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	var n int
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if _, err := verify(applied, m.migrations); err != nil {
+			return err
+		}
+		byVersion := make(map[int64]*Migration, len(m.migrations))
+		for _, mig := range m.migrations {
+			byVersion[mig.Version] = mig
+		}
+		for i := len(applied) - 1; i >= 0 && n < steps; i-- {
+			if err := m.apply(ctx, conn, byVersion[applied[i].Version], false); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// apply 执行一个版本的 up 或 down：先标记 dirty，全部语句成功后再更新记录
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig *Migration, up bool) error {
+	script := mig.Down
+	if up {
+		script = mig.Up
+	}
+	if _, err := conn.ExecContext(ctx,
+		"INSERT INTO schema_migrations (service, version, name, dirty) VALUES (?, ?, ?, 1) ON DUPLICATE KEY UPDATE dirty = 1",
+		m.service, mig.Version, mig.Name); err != nil {
+		return err
+	}
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			direction := "down"
+			if up {
+				direction = "up"
+			}
+			return fmt.Errorf("migration %d_%s %s failed: %w", mig.Version, mig.Name, direction, err)
+		}
+	}
+
+	var err error
+	if up {
+		_, err = conn.ExecContext(ctx,
+			"UPDATE schema_migrations SET dirty = 0, applied_at = CURRENT_TIMESTAMP WHERE service = ? AND version = ?",
+			m.service, mig.Version)
+	} else {
+		_, err = conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE service = ? AND version = ?", m.service, mig.Version)
+	}
+	return err
+}
+
+// withLock 在持有该服务迁移锁的连接上执行 fn
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	lockName := "schema_migrations:" + m.service
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&locked); err != nil {
+		return fmt.Errorf("acquire migration lock failed: %w", err)
+	}
+	if locked.Int64 != 1 {
+		return fmt.Errorf("acquire migration lock %q timed out", lockName)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+
+	return fn(conn)
+}
+
+// Ensure 服务启动时调用：auto 为 true 时先执行待执行的迁移，之后确认表结构版本与代码一致
+func Ensure(ctx context.Context, db *sql.DB, service string, fsys fs.FS, auto bool) error {
+	m, err := New(db, service, fsys)
+	if err != nil {
+		return err
+	}
+	if auto {
+		if _, err := m.Up(ctx); err != nil {
+			return err
+		}
+	}
+	return m.Check(ctx)
+}
@@ -0,0 +1,123 @@
+package migrate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		want     []int64
+		wantErrs bool
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"0002_add_status.up.sql":   {Data: []byte("ALTER TABLE t ADD status int;")},
+				"0002_add_status.down.sql": {Data: []byte("ALTER TABLE t DROP status;")},
+				"0001_create_t.up.sql":     {Data: []byte("CREATE TABLE t (id int);")},
+				"0001_create_t.down.sql":   {Data: []byte("DROP TABLE t;")},
+				"migrations.go":            {Data: []byte("package migrations")},
+			},
+			want: []int64{1, 2},
+		},
+		{
+			name: "missing down",
+			files: fstest.MapFS{
+				"0001_create_t.up.sql": {Data: []byte("CREATE TABLE t (id int);")},
+			},
+			wantErrs: true,
+		},
+		{
+			name: "invalid name",
+			files: fstest.MapFS{
+				"create_t.up.sql": {Data: []byte("CREATE TABLE t (id int);")},
+			},
+			wantErrs: true,
+		},
+		{
+			name: "same version different names",
+			files: fstest.MapFS{
+				"0001_create_t.up.sql":   {Data: []byte("CREATE TABLE t (id int);")},
+				"0001_create_u.down.sql": {Data: []byte("DROP TABLE u;")},
+			},
+			wantErrs: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.files)
+			if tt.wantErrs {
+				if err == nil {
+					t.Fatalf("Load() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			var got []int64
+			for _, m := range migrations {
+				got = append(got, m.Version)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- 注释
+CREATE TABLE t (
+    id int,
+    name varchar(8) DEFAULT ';'
+);
+
+INSERT INTO t VALUES (1, 'a'),
+    (2, 'b');
+DROP TABLE u`
+	want := []string{
+		"CREATE TABLE t (\n    id int,\n    name varchar(8) DEFAULT ';'\n)",
+		"INSERT INTO t VALUES (1, 'a'),\n    (2, 'b')",
+		"DROP TABLE u",
+	}
+	if got := splitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitStatements() = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	migrations := []*Migration{{Version: 1, Name: "a"}, {Version: 2, Name: "b"}, {Version: 3, Name: "c"}}
+	tests := []struct {
+		name        string
+		applied     []Applied
+		wantPending []int64
+		wantErr     error
+	}{
+		{name: "fresh database", wantPending: []int64{1, 2, 3}},
+		{name: "partially applied", applied: []Applied{{Version: 1}, {Version: 2}}, wantPending: []int64{3}},
+		{name: "up to date", applied: []Applied{{Version: 1}, {Version: 2}, {Version: 3}}},
+		{name: "unknown version", applied: []Applied{{Version: 1}, {Version: 4}}, wantErr: ErrUnknownVersion},
+		{name: "dirty", applied: []Applied{{Version: 1}, {Version: 2, Dirty: true}}, wantErr: ErrDirty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending, err := verify(tt.applied, migrations)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("verify() error = %v, want %v", err, tt.wantErr)
+			}
+			var got []int64
+			for _, m := range pending {
+				got = append(got, m.Version)
+			}
+			if !reflect.DeepEqual(got, tt.wantPending) {
+				t.Errorf("pending = %v, want %v", got, tt.wantPending)
+			}
+		})
+	}
+}
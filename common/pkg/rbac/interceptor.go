@@ -0,0 +1,90 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/jwt"
+	"bluebell_microservices/common/pkg/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataAuthorizationKey BFF 转发 token 使用的 metadata 键，token 由 jwt.GenServiceToken 签发
+const MetadataAuthorizationKey = "authorization"
+
+// Rule RPC 的访问规则
+type Rule struct {
+	// Permission 调用方角色必须拥有的权限
+	Permission Permission
+	// Subject 返回请求中代表操作人的用户ID，必须与 token 中的用户一致；为 nil 时不校验
+	Subject func(req interface{}) uint64
+}
+
+type claimsKey struct{}
+
+// ClaimsFromContext 获取拦截器解析出的调用方身份
+func ClaimsFromContext(ctx context.Context) (*jwt.MyClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*jwt.MyClaims)
+	return claims, ok
+}
+
+// UnaryServerInterceptor 按规则校验调用方权限，未配置规则的方法不做限制
+// 敏感 RPC 需要携带 BFF 签发的短期 token，其中的角色是 BFF 查询到的当前角色；用户登录获得的 token 不能直接调用
+func UnaryServerInterceptor(rules map[string]Rule) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		rule, ok := rules[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		claims, err := parseClaims(ctx)
+		if err != nil {
+			logger.Ctx(ctx).Warn("Unauthenticated RPC call", zap.String("method", info.FullMethod), zap.Error(err))
+			return nil, errcode.ErrUnauthenticated.WithMessage(err.Error())
+		}
+
+		if !HasPermission(claims.Role, rule.Permission) {
+			logger.Ctx(ctx).Warn("Permission denied",
+				zap.String("method", info.FullMethod),
+				zap.Uint64("user_id", claims.UserID),
+				zap.String("role", claims.Role),
+				zap.String("permission", string(rule.Permission)))
+			return nil, errcode.ErrPermissionDenied.WithMessage(fmt.Sprintf("permission %s required", rule.Permission))
+		}
+
+		if rule.Subject != nil && rule.Subject(req) != claims.UserID {
+			logger.Ctx(ctx).Warn("RPC subject does not match token",
+				zap.String("method", info.FullMethod),
+				zap.Uint64("user_id", claims.UserID),
+				zap.Uint64("subject", rule.Subject(req)))
+			return nil, errcode.ErrPermissionDenied.WithMessage("acting user does not match token")
+		}
+
+		return handler(context.WithValue(ctx, claimsKey{}, claims), req)
+	}
+}
+
+// parseClaims 从 metadata 中解析 BFF 转发的 token
+func parseClaims(ctx context.Context) (*jwt.MyClaims, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, errors.New("missing metadata")
+	}
+	values := md.Get(MetadataAuthorizationKey)
+	if len(values) == 0 {
+		return nil, errors.New("missing authorization")
+	}
+	token := strings.TrimPrefix(values[0], "Bearer ")
+	claims, err := jwt.ParseServiceToken(token)
+	if err != nil {
+		return nil, err
+	}
+	claims.Role = Normalize(claims.Role)
+	return claims, nil
+}
@@ -0,0 +1,65 @@
+package rbac
+
+// 用户角色
+const (
+	RoleUser      = "user"      // 普通用户
+	RoleModerator = "moderator" // 全站版主，可管理所有社区
+	RoleAdmin     = "admin"     // 管理员
+	RoleBanned    = "banned"    // 被封禁的用户，没有任何权限
+)
+
+// Permission 权限
+type Permission string
+
+const (
+	PermActive        Permission = "account:active" // 账号正常（未被封禁）
+	PermPostCreate    Permission = "post:create"    // 发帖
+	PermPostVote      Permission = "post:vote"      // 投票
+	PermCommentCreate Permission = "comment:create" // 评论
+	PermReportCreate  Permission = "report:create"  // 举报
+	PermModerateAll   Permission = "moderation:all" // 管理所有社区
+	PermUserManage    Permission = "user:manage"    // 管理用户角色
+)
+
+var basePermissions = []Permission{
+	PermActive,
+	PermPostCreate,
+	PermPostVote,
+	PermCommentCreate,
+	PermReportCreate,
+}
+
+// rolePermissions 角色与权限的对应关系
+var rolePermissions = map[string]map[Permission]bool{
+	RoleUser:      toSet(basePermissions...),
+	RoleModerator: toSet(append(basePermissions, PermModerateAll)...),
+	RoleAdmin:     toSet(append(basePermissions, PermModerateAll, PermUserManage)...),
+	RoleBanned:    {},
+}
+
+func toSet(perms ...Permission) map[Permission]bool {
+	set := make(map[Permission]bool, len(perms))
+	for _, p := range perms {
+		set[p] = true
+	}
+	return set
+}
+
+// Normalize 规范化角色，旧 token 中没有角色时视为普通用户
+func Normalize(role string) string {
+	if role == "" {
+		return RoleUser
+	}
+	return role
+}
+
+// Valid 判断是否为已定义的角色
+func Valid(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission 判断角色是否拥有指定权限
+func HasPermission(role string, perm Permission) bool {
+	return rolePermissions[Normalize(role)][perm]
+}
@@ -0,0 +1,176 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bluebell_microservices/common/pkg/logger"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// machineIDPrefix 雪花算法机器ID的占用记录，key 为 /bluebell/snowflake/<id>，值为占用的实例
+const machineIDPrefix = "/bluebell/snowflake/"
+
+// MachineID 实例占用的雪花算法机器ID，与租约绑定：进程退出或与 etcd 失联超过租约时长后释放
+type MachineID struct {
+	ID uint16
+
+	mu      sync.Mutex
+	cli     *clientv3.Client
+	owner   string
+	leaseID clientv3.LeaseID
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// ClaimMachineID 以 owner 的身份占用机器ID：id 不为 0 时占用该ID，已被其他实例占用时返回错误；
+// 为 0 时分配最小的空闲ID。租约失效后重新占用同一ID，期间及重新占用失败时调用 lost(err)，恢复后调用 lost(nil)
+func ClaimMachineID(cli *clientv3.Client, id uint16, owner string, lost func(error)) (*MachineID, error) {
+	m := &MachineID{ID: id, cli: cli, owner: owner, done: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	keepAliveChan, err := m.claim(ctx, id == 0)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	logger.Info("Machine ID claimed", zap.Uint16("machine_id", m.ID), zap.String("owner", owner))
+
+	m.cancel = cancel
+	go m.keepAlive(ctx, keepAliveChan, lost)
+	return m, nil
+}
+
+// claim 创建租约并占用 m.ID，auto 时从最小的空闲ID中选取
+func (m *MachineID) claim(ctx context.Context, auto bool) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
+	leaseResp, err := m.cli.Grant(ctx, leaseTTL)
+	if err != nil {
+		return nil, fmt.Errorf("创建租约失败: %v", err)
+	}
+	ok, err := m.put(ctx, leaseResp.ID, auto)
+	if err == nil && !ok {
+		err = fmt.Errorf("machine id %d is used by another instance", m.ID)
+	}
+	if err != nil {
+		m.cli.Revoke(context.Background(), leaseResp.ID)
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.leaseID = leaseResp.ID
+	m.mu.Unlock()
+	keepAliveChan, err := m.cli.KeepAlive(ctx, leaseResp.ID)
+	if err != nil {
+		return nil, fmt.Errorf("续约失败: %v", err)
+	}
+	return keepAliveChan, nil
+}
+
+// put 在 ID 空闲或仍由本实例占用时写入占用记录；auto 时依次尝试空闲ID，多个实例同时分配时由事务保证只有一个成功
+func (m *MachineID) put(ctx context.Context, leaseID clientv3.LeaseID, auto bool) (bool, error) {
+	if !auto {
+		return m.tryPut(ctx, m.ID, leaseID)
+	}
+	resp, err := m.cli.Get(ctx, machineIDPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return false, err
+	}
+	used := make(map[uint16]bool, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if id, err := strconv.ParseUint(strings.TrimPrefix(string(kv.Key), machineIDPrefix), 10, 16); err == nil {
+			used[uint16(id)] = true
+		}
+	}
+	for id := 1; id <= math.MaxUint16; id++ {
+		if used[uint16(id)] {
+			continue
+		}
+		ok, err := m.tryPut(ctx, uint16(id), leaseID)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			m.ID = uint16(id)
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("no free machine id")
+}
+
+// tryPut 以事务写入 id 的占用记录，已被其他实例占用时返回 false
+func (m *MachineID) tryPut(ctx context.Context, id uint16, leaseID clientv3.LeaseID) (bool, error) {
+	key := machineIDPrefix + strconv.Itoa(int(id))
+	resp, err := m.cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, m.owner, clientv3.WithLease(leaseID))).
+		Else(clientv3.OpGet(key)).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+	if resp.Succeeded {
+		return true, nil
+	}
+	// 本实例上一个租约尚未过期时同样可以继续使用
+	kvs := resp.Responses[0].GetResponseRange().Kvs
+	if len(kvs) == 0 || string(kvs[0].Value) != m.owner {
+		return false, nil
+	}
+	_, err = m.cli.Put(ctx, key, m.owner, clientv3.WithLease(leaseID))
+	return err == nil, err
+}
+
+// keepAlive 消费续约响应；租约失效后重新占用同一ID直到成功或 ctx 取消
+func (m *MachineID) keepAlive(ctx context.Context, keepAliveChan <-chan *clientv3.LeaseKeepAliveResponse, lost func(error)) {
+	defer close(m.done)
+	for {
+		for range keepAliveChan {
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		err := fmt.Errorf("machine id %d lease lost", m.ID)
+		logger.Warn("Machine ID lease keepalive stopped, reclaiming", zap.Uint16("machine_id", m.ID))
+		lost(err)
+
+		for {
+			keepAliveChan, err = m.claim(ctx, false)
+			if err == nil {
+				logger.Info("Machine ID reclaimed", zap.Uint16("machine_id", m.ID))
+				lost(nil)
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Error("Failed to reclaim machine ID", zap.Uint16("machine_id", m.ID), zap.Error(err))
+			lost(err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reregisterRetry):
+			}
+		}
+	}
+}
+
+// Release 停止续约并撤销租约，释放机器ID
+func (m *MachineID) Release(ctx context.Context) error {
+	m.cancel()
+	<-m.done
+
+	m.mu.Lock()
+	leaseID := m.leaseID
+	m.mu.Unlock()
+	if _, err := m.cli.Revoke(ctx, leaseID); err != nil {
+		return fmt.Errorf("撤销租约失败: %v", err)
+	}
+	logger.Info("Machine ID released", zap.Uint16("machine_id", m.ID))
+	return nil
+}
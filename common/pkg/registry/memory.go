@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"sort"
+	"sync"
+)
+
+// Memory 进程内的服务注册表，用于测试中代替 etcd；实例没有租约，需显式 Deregister
+type Memory struct {
+	mu        sync.Mutex
+	instances map[string]map[string]*Instance // 服务名 -> 实例 ID -> 实例
+	watchers  map[string]map[chan struct{}]bool
+}
+
+// NewMemory 创建空的注册表
+func NewMemory() *Memory {
+	return &Memory{
+		instances: make(map[string]map[string]*Instance),
+		watchers:  make(map[string]map[chan struct{}]bool),
+	}
+}
+
+// Register 注册或更新实例
+func (m *Memory) Register(ins *Instance) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := *ins
+	if c.Weight <= 0 {
+		c.Weight = DefaultWeight
+	}
+	if m.instances[ins.Name] == nil {
+		m.instances[ins.Name] = make(map[string]*Instance)
+	}
+	m.instances[ins.Name][ins.ID] = &c
+	m.notify(ins.Name)
+}
+
+// Deregister 摘除实例
+func (m *Memory) Deregister(serviceName, instanceID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.instances[serviceName], instanceID)
+	m.notify(serviceName)
+}
+
+// Instances 服务当前的全部实例（包括未就绪的），按 ID 排序
+func (m *Memory) Instances(serviceName string) []*Instance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]*Instance, 0, len(m.instances[serviceName]))
+	for _, ins := range m.instances[serviceName] {
+		c := *ins
+		list = append(list, &c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Watch 服务实例变化时向返回的 channel 发送通知，多次变化可能合并为一次；调用 cancel 停止
+func (m *Memory) Watch(serviceName string) (<-chan struct{}, func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch := make(chan struct{}, 1)
+	if m.watchers[serviceName] == nil {
+		m.watchers[serviceName] = make(map[chan struct{}]bool)
+	}
+	m.watchers[serviceName][ch] = true
+	return ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.watchers[serviceName], ch)
+	}
+}
+
+// notify 调用方需持有锁
+func (m *Memory) notify(serviceName string) {
+	for ch := range m.watchers[serviceName] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"bluebell_microservices/common/pkg/logger"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// leaseTTL 服务注册租约时长（秒），进程异常退出后最多这么久被摘除
+const leaseTTL = 10
+
+// reregisterRetry 租约失效后重新注册失败时的重试间隔
+const reregisterRetry = 2 * time.Second
+
+// servicesPrefix 服务注册的根路径，实例 key 为 /services/<name>/<instance-id>
+const servicesPrefix = "/services/"
+
+// DefaultWeight 未配置权重时实例的默认权重
+const DefaultWeight = 1
+
+// 实例状态
+const (
+	StatusReady    = "ready"     // 依赖检查通过，可以接收请求
+	StatusNotReady = "not_ready" // 依赖异常，客户端应摘除
+)
+
+// Instance 注册到 etcd 的服务实例信息
+type Instance struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Addr    string `json:"addr"`
+	Version string `json:"version,omitempty"`
+	Weight  int    `json:"weight,omitempty"`
+	Zone    string `json:"zone,omitempty"`
+	Status  string `json:"status,omitempty"`
+}
+
+// Ready 实例是否可以接收请求，未上报状态的旧版本实例视为就绪
+func (ins *Instance) Ready() bool {
+	return ins.Status != StatusNotReady
+}
+
+// Prefix 返回服务所有实例 key 的公共前缀
+func Prefix(serviceName string) string {
+	return servicesPrefix + serviceName + "/"
+}
+
+// Key 返回实例的注册 key
+func Key(serviceName, instanceID string) string {
+	return Prefix(serviceName) + instanceID
+}
+
+// Decode 解析 etcd 中的实例信息，兼容旧版本只写入地址的格式
+func Decode(key string, value []byte) (*Instance, error) {
+	ins := &Instance{}
+	if err := json.Unmarshal(value, ins); err != nil {
+		addr := strings.TrimSpace(string(value))
+		if addr == "" || strings.ContainsAny(addr, "{}") {
+			return nil, fmt.Errorf("invalid instance %s: %v", key, err)
+		}
+		ins.Addr = addr
+	}
+	if ins.Addr == "" {
+		return nil, fmt.Errorf("instance %s has no address", key)
+	}
+	if ins.ID == "" {
+		ins.ID = key[strings.LastIndex(key, "/")+1:]
+	}
+	if ins.Weight <= 0 {
+		ins.Weight = DefaultWeight
+	}
+	return ins, nil
+}
+
+// Registration 已注册的实例，Deregister 后失效
+type Registration struct {
+	mu       sync.Mutex
+	cli      *clientv3.Client
+	key      string
+	leaseID  clientv3.LeaseID
+	instance *Instance
+	cancel   context.CancelFunc // 停止续约及重新注册
+	done     chan struct{}
+}
+
+// Register 将实例写入 etcd 并持续续约；租约失效（如与 etcd 长时间断开）后重新创建租约并注册
+func Register(cli *clientv3.Client, ins *Instance) (*Registration, error) {
+	if ins.Weight <= 0 {
+		ins.Weight = DefaultWeight
+	}
+
+	r := &Registration{cli: cli, key: Key(ins.Name, ins.ID), instance: ins, done: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	keepAliveChan, err := r.register(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	logger.Info("Service registered", zap.String("key", r.key), zap.Any("instance", ins))
+
+	r.cancel = cancel
+	go r.keepAlive(ctx, keepAliveChan)
+	return r, nil
+}
+
+// register 创建租约、写入实例信息并开始续约
+func (r *Registration) register(ctx context.Context) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
+	// 创建租约
+	leaseResp, err := r.cli.Grant(ctx, leaseTTL)
+	if err != nil {
+		return nil, fmt.Errorf("创建租约失败: %v", err)
+	}
+
+	// 注册服务
+	r.mu.Lock()
+	r.leaseID = leaseResp.ID
+	putCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	err = r.put(putCtx)
+	cancel()
+	r.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("注册服务失败: %v", err)
+	}
+
+	// 续约
+	keepAliveChan, err := r.cli.KeepAlive(ctx, leaseResp.ID)
+	if err != nil {
+		return nil, fmt.Errorf("续约失败: %v", err)
+	}
+	return keepAliveChan, nil
+}
+
+// keepAlive 消费续约响应；续约通道关闭说明租约已失效或续约中断，重新注册直到成功或 ctx 取消
+func (r *Registration) keepAlive(ctx context.Context, keepAliveChan <-chan *clientv3.LeaseKeepAliveResponse) {
+	defer close(r.done)
+	for {
+		for range keepAliveChan {
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("Service lease keepalive stopped, re-registering", zap.String("key", r.key))
+
+		for {
+			var err error
+			keepAliveChan, err = r.register(ctx)
+			if err == nil {
+				logger.Info("Service re-registered", zap.String("key", r.key))
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warn("Failed to re-register service", zap.String("key", r.key), zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reregisterRetry):
+			}
+		}
+	}
+}
+
+// SetStatus 更新实例状态，客户端据此摘除或恢复本实例
+func (r *Registration) SetStatus(ctx context.Context, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.instance.Status == status {
+		return nil
+	}
+	prev := r.instance.Status
+	r.instance.Status = status
+	if err := r.put(ctx); err != nil {
+		r.instance.Status = prev
+		return err
+	}
+	logger.Info("Service status changed", zap.String("key", r.key), zap.String("status", status))
+	return nil
+}
+
+// put 将实例信息写入 etcd，绑定到注册租约
+func (r *Registration) put(ctx context.Context) error {
+	value, err := json.Marshal(r.instance)
+	if err != nil {
+		return err
+	}
+	_, err = r.cli.Put(ctx, r.key, string(value), clientv3.WithLease(r.leaseID))
+	return err
+}
+
+// Deregister 停止续约并撤销租约，注册的 key 随之删除，客户端不再将新请求发往本实例
+func (r *Registration) Deregister(ctx context.Context) error {
+	r.cancel()
+	<-r.done
+
+	r.mu.Lock()
+	leaseID := r.leaseID
+	r.mu.Unlock()
+	if _, err := r.cli.Revoke(ctx, leaseID); err != nil {
+		return fmt.Errorf("撤销租约失败: %v", err)
+	}
+	logger.Info("Service deregistered", zap.String("key", r.key))
+	return nil
+}
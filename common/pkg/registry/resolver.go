@@ -0,0 +1,270 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"bluebell_microservices/common/pkg/logger"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+)
+
+// weightAttrKey 地址属性中实例权重的 key
+type weightAttrKey struct{}
+
+// AddrWeight 读取 resolver 写入地址属性的实例权重，缺省为 1
+func AddrWeight(addr resolver.Address) int {
+	if addr.Attributes != nil {
+		if w, ok := addr.Attributes.Value(weightAttrKey{}).(int); ok && w > 0 {
+			return w
+		}
+	}
+	return 1
+}
+
+// EtcdResolverBuilder 实现 gRPC 的 Resolver 接口
+type EtcdResolverBuilder struct {
+	etcdClient *clientv3.Client
+}
+
+// NewEtcdResolverBuilder 创建一个新的EtcdResolverBuilder实例
+func NewEtcdResolverBuilder(etcdClient *clientv3.Client) *EtcdResolverBuilder {
+	return &EtcdResolverBuilder{
+		etcdClient: etcdClient,
+	}
+}
+
+// Scheme 返回 resolver 的 scheme
+func (e *EtcdResolverBuilder) Scheme() string {
+	return "etcd"
+}
+
+// Build 构建 resolver，跟踪 /services/<name>/ 前缀下的所有实例
+func (e *EtcdResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &etcdResolver{
+		etcdClient:  e.etcdClient,
+		cc:          cc,
+		serviceName: target.URL.Host, // 使用target.URL.Host作为服务名
+		ctx:         ctx,
+		cancel:      cancel,
+		instances:   make(map[string]*Instance),
+	}
+
+	rev := r.sync()
+	r.wg.Add(1)
+	go r.watch(rev) // 启动监听 etcd 变化
+
+	return r, nil
+}
+
+type etcdResolver struct {
+	etcdClient  *clientv3.Client
+	cc          resolver.ClientConn
+	serviceName string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	instances map[string]*Instance // key -> 实例
+}
+
+// ResolveNow 重新全量拉取一次实例列表
+func (r *etcdResolver) ResolveNow(options resolver.ResolveNowOptions) {
+	r.sync()
+}
+
+// sync 全量拉取实例并更新连接状态，返回本次读取的 revision，失败时返回 0
+func (r *etcdResolver) sync() int64 {
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := r.etcdClient.Get(ctx, Prefix(r.serviceName), clientv3.WithPrefix())
+	if err != nil {
+		// 不立即返回错误，等待服务上线
+		logger.Warn("Failed to list service instances", zap.String("service", r.serviceName), zap.Error(err))
+		return 0
+	}
+
+	instances := make(map[string]*Instance, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		ins, err := Decode(string(kv.Key), kv.Value)
+		if err != nil {
+			logger.Warn("Skip invalid service instance", zap.String("key", string(kv.Key)), zap.Error(err))
+			continue
+		}
+		if !ins.Ready() {
+			continue
+		}
+		instances[string(kv.Key)] = ins
+	}
+
+	r.mu.Lock()
+	r.instances = instances
+	r.mu.Unlock()
+	r.updateState()
+
+	return resp.Header.Revision
+}
+
+// watch 增量跟踪实例上下线，直到 Close
+func (r *etcdResolver) watch(rev int64) {
+	defer r.wg.Done()
+	logger.Debug("Watching service instances", zap.String("service", r.serviceName))
+
+	opts := []clientv3.OpOption{clientv3.WithPrefix()}
+	if rev > 0 {
+		opts = append(opts, clientv3.WithRev(rev+1))
+	}
+	watchChan := r.etcdClient.Watch(r.ctx, Prefix(r.serviceName), opts...)
+	for wresp := range watchChan {
+		if err := wresp.Err(); err != nil {
+			// revision 已被压缩等情况，重新全量同步
+			logger.Warn("Service watch error, resyncing", zap.String("service", r.serviceName), zap.Error(err))
+			r.sync()
+			continue
+		}
+
+		r.mu.Lock()
+		for _, ev := range wresp.Events {
+			key := string(ev.Kv.Key)
+			switch ev.Type {
+			case clientv3.EventTypePut:
+				ins, err := Decode(key, ev.Kv.Value)
+				if err != nil {
+					logger.Warn("Skip invalid service instance", zap.String("key", key), zap.Error(err))
+					continue
+				}
+				if !ins.Ready() {
+					// 实例依赖异常，暂时摘除
+					logger.Info("Service instance not ready", zap.String("service", r.serviceName), zap.String("addr", ins.Addr))
+					delete(r.instances, key)
+					continue
+				}
+				logger.Info("Service instance up", zap.String("service", r.serviceName), zap.String("addr", ins.Addr))
+				r.instances[key] = ins
+			case clientv3.EventTypeDelete:
+				logger.Info("Service instance down", zap.String("service", r.serviceName), zap.String("key", key))
+				delete(r.instances, key)
+			}
+		}
+		r.mu.Unlock()
+		r.updateState()
+	}
+	logger.Debug("Service watch stopped", zap.String("service", r.serviceName))
+}
+
+// updateState 将当前实例列表推送给 gRPC，实例权重放在地址属性中供负载均衡使用
+func (r *etcdResolver) updateState() {
+	r.mu.Lock()
+	addrs := make([]resolver.Address, 0, len(r.instances))
+	for _, ins := range r.instances {
+		addrs = append(addrs, resolver.Address{
+			Addr:       ins.Addr,
+			Attributes: attributes.New(weightAttrKey{}, ins.Weight),
+		})
+	}
+	r.mu.Unlock()
+
+	// 保证顺序稳定，避免无变化时触发重建
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Addr < addrs[j].Addr })
+
+	if len(addrs) == 0 {
+		// 推送空列表使负载均衡关闭已下线实例的连接，否则 gRPC 会继续使用上一次的地址
+		r.cc.UpdateState(resolver.State{})
+		r.cc.ReportError(fmt.Errorf("no available instance for service %s", r.serviceName))
+		return
+	}
+	if err := r.cc.UpdateState(resolver.State{Addresses: addrs}); err != nil {
+		logger.Warn("Failed to update resolver state", zap.String("service", r.serviceName), zap.Error(err))
+	}
+}
+
+// Close 停止监听并等待 watch 协程退出
+func (r *etcdResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// MemoryResolverBuilder 基于进程内注册表的服务发现，scheme 与 etcd 相同，用于测试
+type MemoryResolverBuilder struct {
+	reg *Memory
+}
+
+// NewMemoryResolverBuilder 创建基于 reg 的 resolver
+func NewMemoryResolverBuilder(reg *Memory) *MemoryResolverBuilder {
+	return &MemoryResolverBuilder{reg: reg}
+}
+
+// Scheme 返回 resolver 的 scheme
+func (b *MemoryResolverBuilder) Scheme() string {
+	return "etcd"
+}
+
+// Build 构建 resolver，跟踪注册表中该服务的实例变化
+func (b *MemoryResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	r := &memoryResolver{reg: b.reg, cc: cc, serviceName: target.URL.Host, done: make(chan struct{})}
+	var changes <-chan struct{}
+	changes, r.stop = b.reg.Watch(r.serviceName)
+	r.update()
+	go func() {
+		for {
+			select {
+			case <-changes:
+				r.update()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return r, nil
+}
+
+type memoryResolver struct {
+	reg         *Memory
+	cc          resolver.ClientConn
+	serviceName string
+	stop        func()
+	done        chan struct{}
+}
+
+// ResolveNow 重新推送一次实例列表
+func (r *memoryResolver) ResolveNow(resolver.ResolveNowOptions) {
+	r.update()
+}
+
+// update 将就绪实例推送给 gRPC
+func (r *memoryResolver) update() {
+	var addrs []resolver.Address
+	for _, ins := range r.reg.Instances(r.serviceName) {
+		if !ins.Ready() {
+			continue
+		}
+		addrs = append(addrs, resolver.Address{
+			Addr:       ins.Addr,
+			Attributes: attributes.New(weightAttrKey{}, ins.Weight),
+		})
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Addr < addrs[j].Addr })
+
+	if len(addrs) == 0 {
+		r.cc.UpdateState(resolver.State{})
+		r.cc.ReportError(fmt.Errorf("no available instance for service %s", r.serviceName))
+		return
+	}
+	r.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+// Close 停止跟踪
+func (r *memoryResolver) Close() {
+	r.stop()
+	close(r.done)
+}
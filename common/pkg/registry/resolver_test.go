@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// fakeClientConn 记录 resolver 推送的状态
+type fakeClientConn struct {
+	resolver.ClientConn
+
+	mu      sync.Mutex
+	states  []resolver.State
+	errs    []error
+	updated chan struct{}
+}
+
+func newFakeClientConn() *fakeClientConn {
+	return &fakeClientConn{updated: make(chan struct{}, 16)}
+}
+
+func (c *fakeClientConn) UpdateState(s resolver.State) error {
+	c.mu.Lock()
+	c.states = append(c.states, s)
+	c.mu.Unlock()
+	c.updated <- struct{}{}
+	return nil
+}
+
+func (c *fakeClientConn) ReportError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs = append(c.errs, err)
+}
+
+// last 最后一次推送的地址
+func (c *fakeClientConn) last(t *testing.T) []resolver.Address {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.states) == 0 {
+		t.Fatal("no state pushed")
+	}
+	return c.states[len(c.states)-1].Addresses
+}
+
+func TestEtcdResolver_InstancesGone(t *testing.T) {
+	cc := newFakeClientConn()
+	r := &etcdResolver{cc: cc, serviceName: "post", instances: map[string]*Instance{
+		Prefix("post") + "post-0": {ID: "post-0", Name: "post", Addr: "10.0.0.1:8081", Weight: DefaultWeight, Status: StatusReady},
+	}}
+	r.updateState()
+	if addrs := cc.last(t); len(addrs) != 1 {
+		t.Fatalf("addresses = %v, want one", addrs)
+	}
+
+	// 租约过期或撤销后 etcd 删除实例 key，watch 收到删除事件
+	r.mu.Lock()
+	delete(r.instances, Prefix("post")+"post-0")
+	r.mu.Unlock()
+	r.updateState()
+	if addrs := cc.last(t); len(addrs) != 0 {
+		t.Errorf("addresses after all instances gone = %v, want none", addrs)
+	}
+	if len(cc.errs) != 1 {
+		t.Errorf("reported errors = %v, want one", cc.errs)
+	}
+}
+
+func TestMemoryResolver_InstancesGone(t *testing.T) {
+	reg := NewMemory()
+	reg.Register(&Instance{ID: "post-0", Name: "post", Addr: "post-0", Status: StatusReady})
+	cc := newFakeClientConn()
+	r, err := NewMemoryResolverBuilder(reg).Build(resolver.Target{URL: url.URL{Scheme: "etcd", Host: "post"}}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	<-cc.updated
+	if addrs := cc.last(t); len(addrs) != 1 {
+		t.Fatalf("addresses = %v, want one", addrs)
+	}
+
+	reg.Deregister("post", "post-0")
+	select {
+	case <-cc.updated:
+	case <-time.After(time.Second):
+		t.Fatal("no state pushed after deregister")
+	}
+	if addrs := cc.last(t); len(addrs) != 0 {
+		t.Errorf("addresses after deregister = %v, want none", addrs)
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/registry"
+
+	"go.uber.org/zap"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// 依赖健康检查的周期及单次超时
+const (
+	healthCheckInterval = 5 * time.Second
+	healthCheckTimeout  = 2 * time.Second
+)
+
+// checkComponents 依次执行各组件的健康检查，返回失败的组件及原因
+func (s *Server) checkComponents(ctx context.Context) map[string]error {
+	failures := make(map[string]error)
+	for _, c := range s.started {
+		if c.Check == nil {
+			continue
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := c.Check(checkCtx)
+		cancel()
+		if err != nil {
+			failures[c.Name] = err
+		}
+	}
+	return failures
+}
+
+// refreshHealth 执行一次健康检查，同步到 grpc.health.v1 及 etcd 中的实例状态，返回是否就绪
+func (s *Server) refreshHealth(ctx context.Context, reg *registry.Registration) bool {
+	failures := s.checkComponents(ctx)
+	ready := len(failures) == 0
+
+	servingStatus, status := healthpb.HealthCheckResponse_SERVING, registry.StatusReady
+	if !ready {
+		servingStatus, status = healthpb.HealthCheckResponse_NOT_SERVING, registry.StatusNotReady
+		for name, err := range failures {
+			logger.Warn("Dependency check failed", zap.String("service", s.name), zap.String("component", name), zap.Error(err))
+		}
+	}
+
+	// 空服务名表示整体状态，同时按服务名上报便于按服务查询
+	s.health.SetServingStatus("", servingStatus)
+	s.health.SetServingStatus(s.name, servingStatus)
+
+	if reg != nil {
+		if err := reg.SetStatus(ctx, status); err != nil {
+			logger.Error("Failed to publish instance status", zap.String("service", s.name), zap.Error(err))
+		}
+	}
+	return ready
+}
+
+// watchHealth 周期性检查依赖健康状况，直到 ctx 取消
+func (s *Server) watchHealth(ctx context.Context, reg *registry.Registration) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refreshHealth(ctx, reg)
+		}
+	}
+}
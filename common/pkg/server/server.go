@@ -0,0 +1,329 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/jwt"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/metrics"
+	"bluebell_microservices/common/pkg/registry"
+	"bluebell_microservices/common/pkg/snowflake"
+	"bluebell_microservices/common/pkg/tracing"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// defaultShutdownTimeout 未配置时优雅退出的最长等待时间
+const defaultShutdownTimeout = 15 * time.Second
+
+// Component 服务依赖的外部组件（MySQL、Redis、Kafka 等），启动时按注册顺序初始化，退出时按相反顺序关闭
+type Component struct {
+	Name  string
+	Init  func(conf *config.Config) error
+	Close func() error
+	Check func(ctx context.Context) error // 健康检查，为空时不参与就绪判断
+}
+
+// Server 微服务启动器：加载配置、初始化组件、注册 etcd、提供 gRPC 服务并在收到退出信号时优雅关闭
+type Server struct {
+	name       string
+	conf       *config.Service
+	grpcServer *grpc.Server
+	health     *health.Server
+	etcd       *clientv3.Client
+	machineID  *registry.MachineID
+	started    []Component // 已初始化成功的组件
+
+	shutdownTracing func(context.Context) error
+	metrics         *metrics.Server
+	stopWatch       func() error // 停止监听配置文件
+}
+
+// New 初始化配置、日志，连接 etcd 占用雪花算法机器ID，并创建 gRPC 服务器；name 对应配置中 services 下的服务名
+func New(name string, opts ...grpc.ServerOption) (*Server, error) {
+	// 初始化配置
+	if err := config.InitConfig(name); err != nil {
+		return nil, err
+	}
+	conf := config.Conf.Services[name]
+	if conf.ShutdownTimeout <= 0 {
+		conf.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	// 初始化日志
+	if err := logger.Init(name+"-service", config.Conf.Log); err != nil {
+		return nil, fmt.Errorf("init logger failed: %v", err)
+	}
+	jwt.Init(config.Conf.Server.JwtSecret)
+
+	// 配置文件变更时热更新日志级别等配置
+	stopWatch, err := config.Watch(func(conf *config.Config, restart []string) {
+		logger.SetLevel(conf.Log.Level)
+		logger.Info("Config reloaded", zap.String("service", name), zap.String("log_level", conf.Log.Level))
+		if len(restart) > 0 {
+			logger.Warn("Config changes require restart", zap.String("service", name), zap.Strings("sections", restart))
+		}
+	}, func(err error) {
+		logger.Error("Failed to reload config, keeping current config", zap.String("service", name), zap.Error(err))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("watch config failed: %v", err)
+	}
+
+	s := &Server{
+		name:      name,
+		conf:      conf,
+		stopWatch: stopWatch,
+	}
+
+	// 初始化 etcd 客户端
+	s.etcd, err = clientv3.New(clientv3.Config{
+		Endpoints:   config.Conf.Etcd.Endpoints(),
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		stopWatch()
+		return nil, fmt.Errorf("连接 etcd 失败: %v", err)
+	}
+
+	// 占用机器ID并初始化雪花算法，多个实例使用同一机器ID会生成重复的ID
+	s.machineID, err = registry.ClaimMachineID(s.etcd, conf.MachineID, s.instance().ID, snowflake.SetUnavailable)
+	if err != nil {
+		s.etcd.Close()
+		stopWatch()
+		return nil, fmt.Errorf("claim machine id failed: %v", err)
+	}
+	if err := snowflake.Init(s.machineID.ID); err != nil {
+		s.releaseMachineID()
+		s.etcd.Close()
+		stopWatch()
+		return nil, fmt.Errorf("init snowflake failed: %v", err)
+	}
+
+	// 初始化链路追踪
+	s.shutdownTracing, err = tracing.Init(name+"-service", config.Conf.Tracing)
+	if err != nil {
+		s.releaseMachineID()
+		s.etcd.Close()
+		stopWatch()
+		return nil, fmt.Errorf("init tracing failed: %v", err)
+	}
+
+	s.grpcServer = grpc.NewServer(append(defaultServerOptions(), opts...)...)
+	s.health = health.NewServer()
+	// 注册标准健康检查服务，依赖检查通过前为 NOT_SERVING
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(s.grpcServer, s.health)
+	return s, nil
+}
+
+// defaultServerOptions 所有服务共用的 gRPC 选项：链路追踪及 RED 指标，指标拦截器位于最外层
+// 服务自身的拦截器需通过 grpc.ChainUnaryInterceptor 传入
+func defaultServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		tracing.ServerOption(),
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor()),
+	}
+}
+
+// Use 注册并立即初始化组件，失败时关闭已初始化的组件
+func (s *Server) Use(components ...Component) error {
+	for _, c := range components {
+		if c.Init != nil {
+			if err := c.Init(config.Conf); err != nil {
+				s.closeComponents()
+				return fmt.Errorf("init %s failed: %v", c.Name, err)
+			}
+		}
+		logger.Info("Component initialized", zap.String("service", s.name), zap.String("component", c.Name))
+		s.started = append(s.started, c)
+	}
+	return nil
+}
+
+// GRPC 返回 gRPC 服务器，用于注册服务实现
+func (s *Server) GRPC() *grpc.Server {
+	return s.grpcServer
+}
+
+// Run 监听端口、注册 etcd 并提供服务，阻塞直到收到 SIGINT/SIGTERM 后完成优雅退出
+func (s *Server) Run() error {
+	defer logger.Logger.Sync()
+
+	defer s.etcd.Close()
+	defer s.releaseMachineID()
+
+	// 监听端口
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.conf.Port))
+	if err != nil {
+		s.closeComponents()
+		return fmt.Errorf("failed to listen: %v", err)
+	}
+
+	// 注册反射服务
+	reflection.Register(s.grpcServer)
+
+	// 指标监听与 gRPC 端口分开
+	if s.conf.MetricsPort > 0 {
+		s.metrics = metrics.Serve(fmt.Sprintf(":%d", s.conf.MetricsPort))
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Service running", zap.String("service", s.name), zap.String("addr", lis.Addr().String()))
+		serveErr <- s.grpcServer.Serve(lis)
+	}()
+
+	// 服务开始监听并完成首次依赖检查后再注册，实例状态随检查结果更新
+	ins := s.instance()
+	if !s.refreshHealth(context.Background(), nil) {
+		ins.Status = registry.StatusNotReady
+	}
+	reg, err := registry.Register(s.etcd, ins)
+	if err != nil {
+		s.grpcServer.Stop()
+		s.closeComponents()
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	defer stopHealth()
+	go s.watchHealth(healthCtx, reg)
+
+	select {
+	case err := <-serveErr:
+		// gRPC 服务异常退出，同样需要摘除注册并释放资源
+		logger.Error("gRPC server stopped unexpectedly", zap.String("service", s.name), zap.Error(err))
+		stopHealth()
+		s.shutdown(reg)
+		return err
+	case <-ctx.Done():
+		logger.Info("Shutdown signal received", zap.String("service", s.name))
+	}
+
+	stopHealth()
+	s.shutdown(reg)
+	return nil
+}
+
+// shutdown 依次：健康状态置为 NOT_SERVING -> 从 etcd 摘除 -> 等待进行中的请求完成 -> 按相反顺序关闭组件
+func (s *Server) shutdown(reg *registry.Registration) {
+	// 健康检查立即返回 NOT_SERVING，开启了健康检查的客户端会先行摘除
+	s.health.Shutdown()
+
+	deregisterCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := reg.Deregister(deregisterCtx); err != nil {
+		logger.Error("Failed to deregister service", zap.String("service", s.name), zap.Error(err))
+	}
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		logger.Info("gRPC server stopped gracefully", zap.String("service", s.name))
+	case <-time.After(s.conf.ShutdownTimeout):
+		logger.Warn("Graceful stop timed out, forcing stop", zap.String("service", s.name), zap.Duration("timeout", s.conf.ShutdownTimeout))
+		s.grpcServer.Stop()
+	}
+
+	s.closeComponents()
+	s.stopWatch()
+
+	metricsCtx, cancelMetrics := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := s.metrics.Shutdown(metricsCtx); err != nil {
+		logger.Error("Failed to shutdown metrics server", zap.String("service", s.name), zap.Error(err))
+	}
+	cancelMetrics()
+
+	// 刷新尚未导出的 span
+	tracingCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := s.shutdownTracing(tracingCtx); err != nil {
+		logger.Error("Failed to shutdown tracing", zap.String("service", s.name), zap.Error(err))
+	}
+	cancel()
+	logger.Info("Service exited", zap.String("service", s.name))
+}
+
+// releaseMachineID 释放机器ID，供其他实例使用
+func (s *Server) releaseMachineID() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.machineID.Release(ctx); err != nil {
+		logger.Error("Failed to release machine ID", zap.String("service", s.name), zap.Error(err))
+	}
+}
+
+// Close 在调用 Run 之前启动失败时释放 New 及 Use 占用的资源：关闭已初始化的组件、释放机器ID并断开 etcd，
+// 下一个实例无需等待租约过期即可使用该机器ID
+func (s *Server) Close() {
+	s.closeComponents()
+	s.releaseMachineID()
+	s.etcd.Close()
+	s.stopWatch()
+
+	tracingCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.shutdownTracing(tracingCtx); err != nil {
+		logger.Error("Failed to shutdown tracing", zap.String("service", s.name), zap.Error(err))
+	}
+}
+
+// closeComponents 按初始化的相反顺序关闭组件
+func (s *Server) closeComponents() {
+	for i := len(s.started) - 1; i >= 0; i-- {
+		c := s.started[i]
+		if c.Close == nil {
+			continue
+		}
+		if err := c.Close(); err != nil {
+			logger.Error("Failed to close component", zap.String("service", s.name), zap.String("component", c.Name), zap.Error(err))
+			continue
+		}
+		logger.Info("Component closed", zap.String("service", s.name), zap.String("component", c.Name))
+	}
+	s.started = nil
+}
+
+// instance 注册到 etcd 的实例信息
+func (s *Server) instance() *registry.Instance {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "127.0.0.1"
+	}
+
+	ins := &registry.Instance{
+		ID:      s.conf.InstanceID,
+		Name:    s.name,
+		Addr:    s.conf.Advertise,
+		Version: config.Conf.Server.Version,
+		Weight:  s.conf.Weight,
+		Zone:    s.conf.Zone,
+	}
+	if ins.ID == "" {
+		ins.ID = fmt.Sprintf("%s-%d", host, s.conf.Port)
+	}
+	if ins.Addr == "" {
+		ins.Addr = fmt.Sprintf("%s:%d", host, s.conf.Port)
+	}
+	return ins
+}
@@ -2,6 +2,7 @@ package snowflake
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/sony/sonyflake"
@@ -10,6 +11,9 @@ import (
 var (
 	sonyFlake     *sonyflake.Sonyflake // 实例
 	sonyMachineID uint16               // 机器ID
+
+	mu          sync.RWMutex
+	unavailable error // 机器ID的占用失效时不再生成ID，避免与接替该ID的实例冲突
 )
 
 func getMachineID() (uint16, error) { // 返回全局定义的机器ID
@@ -28,12 +32,25 @@ func Init(machineId uint16) (err error) {
 	return
 }
 
+// SetUnavailable 机器ID的占用失效时传入原因，此后 GetID 返回该错误；重新占用后传入 nil 恢复
+func SetUnavailable(err error) {
+	mu.Lock()
+	unavailable = err
+	mu.Unlock()
+}
+
 // GetID 返回生成的id值
 func GetID() (id uint64, err error) { // 拿到sonyFlake节点生成id值
 	if sonyFlake == nil {
 		err = fmt.Errorf("snoy flake not inited")
 		return
 	}
+	mu.RLock()
+	err = unavailable
+	mu.RUnlock()
+	if err != nil {
+		return
+	}
 
 	id, err = sonyFlake.NextID()
 	return
@@ -0,0 +1,36 @@
+package validate
+
+import (
+	"strings"
+)
+
+// WordFilter 屏蔽词过滤器，匹配时忽略大小写
+type WordFilter struct {
+	words []string
+}
+
+// NewWordFilter 根据屏蔽词列表创建过滤器，忽略空词
+func NewWordFilter(words []string) *WordFilter {
+	f := &WordFilter{}
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" {
+			f.words = append(f.words, w)
+		}
+	}
+	return f
+}
+
+// Find 返回文本中命中的第一个屏蔽词
+func (f *WordFilter) Find(text string) (string, bool) {
+	if f == nil || len(f.words) == 0 {
+		return "", false
+	}
+	lower := strings.ToLower(text)
+	for _, w := range f.words {
+		if strings.Contains(lower, w) {
+			return w, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,150 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 字段错误原因
+const (
+	ReasonRequired = "required"  // 必填
+	ReasonTooLong  = "too_long"  // 超出长度限制
+	ReasonNotFound = "not_found" // 引用的对象不存在
+	ReasonMismatch = "mismatch"  // 引用的对象与上下文不匹配
+	ReasonBlocked  = "blocked"   // 包含屏蔽词
+	ReasonInvalid  = "invalid"   // 不在允许的取值范围内
+)
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// Errors 字段错误集合，实现 error 接口，可通过 GRPCStatus 在服务间传递
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// GRPCStatus 转换为 InvalidArgument 状态，字段错误以 BadRequest 详情携带
+func (e Errors) GRPCStatus() *status.Status {
+	st := status.New(codes.InvalidArgument, e.Error())
+	br := &errdetails.BadRequest{}
+	for _, fe := range e {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field,
+			Description: fe.Message,
+			Reason:      fe.Reason,
+		})
+	}
+	if withDetails, err := st.WithDetails(br); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// FromError 从 gRPC 错误中还原字段错误，非校验错误时返回 false
+func FromError(err error) (Errors, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		return nil, false
+	}
+	var errs Errors
+	for _, d := range st.Details() {
+		br, ok := d.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, v := range br.FieldViolations {
+			errs = append(errs, FieldError{Field: v.Field, Reason: v.Reason, Message: v.Description})
+		}
+	}
+	return errs, len(errs) > 0
+}
+
+// Validator 收集多个字段的校验错误
+type Validator struct {
+	errs Errors
+}
+
+// New 创建校验器
+func New() *Validator {
+	return &Validator{}
+}
+
+// Add 追加一个字段错误
+func (v *Validator) Add(field, reason, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Reason: reason, Message: message})
+}
+
+// Required 字符串去除首尾空白后不能为空
+func (v *Validator) Required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, ReasonRequired, "不能为空")
+		return false
+	}
+	return true
+}
+
+// MaxChars 字符数不超过 max，对应 varchar(max) 列
+func (v *Validator) MaxChars(field, value string, max int) bool {
+	if utf8.RuneCountInString(value) > max {
+		v.Add(field, ReasonTooLong, fmt.Sprintf("长度不能超过 %d 个字符", max))
+		return false
+	}
+	return true
+}
+
+// MaxBytes 字节数不超过 max，对应 text 等按字节计长的列
+func (v *Validator) MaxBytes(field, value string, max int) bool {
+	if len(value) > max {
+		v.Add(field, ReasonTooLong, fmt.Sprintf("长度不能超过 %d 字节", max))
+		return false
+	}
+	return true
+}
+
+// OneOf 取值必须是 allowed 之一
+func (v *Validator) OneOf(field, value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	v.Add(field, ReasonInvalid, fmt.Sprintf("必须是 %s 之一", strings.Join(allowed, "、")))
+	return false
+}
+
+// NoBlockedWords 内容不能包含屏蔽词
+func (v *Validator) NoBlockedWords(field, value string, filter *WordFilter) bool {
+	if word, ok := filter.Find(value); ok {
+		v.Add(field, ReasonBlocked, fmt.Sprintf("包含屏蔽词「%s」", word))
+		return false
+	}
+	return true
+}
+
+// Valid 当前是否没有错误
+func (v *Validator) Valid() bool {
+	return len(v.errs) == 0
+}
+
+// Err 没有错误时返回 nil，否则返回 Errors
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
@@ -0,0 +1,157 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWordFilter_Find(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   *WordFilter
+		text     string
+		wantWord string
+		wantOK   bool
+	}{
+		{name: "nil filter", filter: nil, text: "spam"},
+		{name: "no words", filter: NewWordFilter(nil), text: "spam"},
+		{name: "empty words ignored", filter: NewWordFilter([]string{"", "  "}), text: "anything"},
+		{name: "case insensitive", filter: NewWordFilter([]string{"Spam"}), text: "buy SPAM now", wantWord: "spam", wantOK: true},
+		{name: "word trimmed", filter: NewWordFilter([]string{" spam "}), text: "spammer", wantWord: "spam", wantOK: true},
+		{name: "first configured word", filter: NewWordFilter([]string{"foo", "bar"}), text: "bar foo", wantWord: "foo", wantOK: true},
+		{name: "chinese", filter: NewWordFilter([]string{"广告"}), text: "这是广告", wantWord: "广告", wantOK: true},
+		{name: "no match", filter: NewWordFilter([]string{"spam"}), text: "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			word, ok := tt.filter.Find(tt.text)
+			if word != tt.wantWord || ok != tt.wantOK {
+				t.Errorf("Find(%q) = %q, %v, want %q, %v", tt.text, word, ok, tt.wantWord, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidator(t *testing.T) {
+	filter := NewWordFilter([]string{"spam"})
+	tests := []struct {
+		name      string
+		check     func(v *Validator) bool
+		wantOK    bool
+		wantError *FieldError
+	}{
+		{
+			name:   "required",
+			check:  func(v *Validator) bool { return v.Required("title", "hi") },
+			wantOK: true,
+		},
+		{
+			name:      "required blank",
+			check:     func(v *Validator) bool { return v.Required("title", " \t\n") },
+			wantError: &FieldError{Field: "title", Reason: ReasonRequired, Message: "不能为空"},
+		},
+		{
+			name:   "max chars counts runes",
+			check:  func(v *Validator) bool { return v.MaxChars("title", "你好", 2) },
+			wantOK: true,
+		},
+		{
+			name:      "max chars exceeded",
+			check:     func(v *Validator) bool { return v.MaxChars("title", "abc", 2) },
+			wantError: &FieldError{Field: "title", Reason: ReasonTooLong, Message: "长度不能超过 2 个字符"},
+		},
+		{
+			name:      "max bytes counts bytes",
+			check:     func(v *Validator) bool { return v.MaxBytes("content", "你好", 5) },
+			wantError: &FieldError{Field: "content", Reason: ReasonTooLong, Message: "长度不能超过 5 字节"},
+		},
+		{
+			name:   "max bytes at limit",
+			check:  func(v *Validator) bool { return v.MaxBytes("content", "你好", 6) },
+			wantOK: true,
+		},
+		{
+			name:   "one of",
+			check:  func(v *Validator) bool { return v.OneOf("order", "time", "time", "score") },
+			wantOK: true,
+		},
+		{
+			name:      "one of invalid",
+			check:     func(v *Validator) bool { return v.OneOf("order", "hot", "time", "score") },
+			wantError: &FieldError{Field: "order", Reason: ReasonInvalid, Message: "必须是 time、score 之一"},
+		},
+		{
+			name:   "no blocked words",
+			check:  func(v *Validator) bool { return v.NoBlockedWords("content", "hello", filter) },
+			wantOK: true,
+		},
+		{
+			name:      "blocked word",
+			check:     func(v *Validator) bool { return v.NoBlockedWords("content", "Spam!", filter) },
+			wantError: &FieldError{Field: "content", Reason: ReasonBlocked, Message: "包含屏蔽词「spam」"},
+		},
+		{
+			name:   "nil filter",
+			check:  func(v *Validator) bool { return v.NoBlockedWords("content", "spam", nil) },
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New()
+			if ok := tt.check(v); ok != tt.wantOK {
+				t.Fatalf("check = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.wantError == nil {
+				if !v.Valid() || v.Err() != nil {
+					t.Fatalf("Err() = %v, want nil", v.Err())
+				}
+				return
+			}
+			var errs Errors
+			if !errors.As(v.Err(), &errs) {
+				t.Fatalf("Err() = %v, want Errors", v.Err())
+			}
+			if len(errs) != 1 || errs[0] != *tt.wantError {
+				t.Errorf("errors = %+v, want %+v", errs, *tt.wantError)
+			}
+		})
+	}
+}
+
+func TestFromError(t *testing.T) {
+	fieldErrs := Errors{
+		{Field: "title", Reason: ReasonRequired, Message: "不能为空"},
+		{Field: "content", Reason: ReasonBlocked, Message: "包含屏蔽词「spam」"},
+	}
+	tests := []struct {
+		name   string
+		err    error
+		want   Errors
+		wantOK bool
+	}{
+		{name: "round trip", err: fieldErrs.GRPCStatus().Err(), want: fieldErrs, wantOK: true},
+		{name: "nil", err: nil},
+		{name: "not grpc", err: errors.New("boom")},
+		{name: "other code", err: status.Error(codes.NotFound, "not found")},
+		{name: "invalid argument without details", err: status.Error(codes.InvalidArgument, "bad")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FromError(tt.err)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromError() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	st := fieldErrs.GRPCStatus()
+	if st.Code() != codes.InvalidArgument || !strings.Contains(st.Message(), "title: 不能为空") {
+		t.Errorf("GRPCStatus() = %v", st)
+	}
+}
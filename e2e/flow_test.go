@@ -0,0 +1,385 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"bluebell_microservices/common/config"
+)
+
+type loginResp struct {
+	UserID      uint64 `json:"user_id,string"`
+	AccessToken string `json:"access_token"`
+}
+
+type postItem struct {
+	Post struct {
+		PostID      int64  `json:"post_id"`
+		AuthorID    int64  `json:"author_id"`
+		CommunityID int64  `json:"community_id"`
+		Title       string `json:"title"`
+	} `json:"post"`
+	AuthorName   string `json:"author_name"`
+	VoteNum      int64  `json:"vote_num"`
+	CommentCount int64  `json:"comment_count"`
+}
+
+type postListResp struct {
+	Data struct {
+		Page struct {
+			Total int64 `json:"total"`
+		} `json:"page"`
+		List []postItem `json:"list"`
+	} `json:"data"`
+}
+
+type commentItem struct {
+	CommentID  uint64 `json:"comment_id"`
+	ParentID   uint64 `json:"parent_id"`
+	AuthorID   uint64 `json:"author_id"`
+	AuthorName string `json:"author_name"`
+	Content    string `json:"content"`
+}
+
+// signUpAndLogin 注册并登录，返回登录结果
+func signUpAndLogin(t *testing.T, h *Harness, username string) loginResp {
+	t.Helper()
+	status := h.Do(t, http.MethodPost, "/api/v1/signup", "", map[string]interface{}{
+		"username": username, "email": username + "@example.com", "gender": 1,
+		"password": "secret", "confirm_password": "secret",
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("signup %s: status = %d", username, status)
+	}
+	var resp loginResp
+	status = h.Do(t, http.MethodPost, "/api/v1/login", "", map[string]string{"username": username, "password": "secret"}, &resp)
+	if status != http.StatusOK || resp.AccessToken == "" || resp.UserID == 0 {
+		t.Fatalf("login %s: status = %d, resp = %+v", username, status, resp)
+	}
+	return resp
+}
+
+func listPosts(t *testing.T, h *Harness, query string) postListResp {
+	t.Helper()
+	var resp postListResp
+	if status := h.Do(t, http.MethodGet, "/api/v1/posts2?"+query, "", nil, &resp); status != http.StatusOK {
+		t.Fatalf("list posts %q: status = %d", query, status)
+	}
+	return resp
+}
+
+func TestFlow_SignUpPostVoteComment(t *testing.T) {
+	h := Start(t)
+	h.Posts.AddCommunity(1, "go")
+
+	alice := signUpAndLogin(t, h, "alice")
+	bob := signUpAndLogin(t, h, "bob")
+
+	// 错误的密码不能登录
+	if status := h.Do(t, http.MethodPost, "/api/v1/login", "", map[string]string{"username": "alice", "password": "bad"}, nil); status == http.StatusOK {
+		t.Fatalf("login with wrong password succeeded")
+	}
+	// 未登录不能发帖
+	if status := h.Do(t, http.MethodPost, "/api/v1/post", "", map[string]interface{}{"community_id": 1, "title": "t", "content": "c"}, nil); status != http.StatusUnauthorized {
+		t.Fatalf("create post without token: status = %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	// alice 先后发两篇帖子
+	for _, title := range []string{"first", "second"} {
+		status := h.Do(t, http.MethodPost, "/api/v1/post", alice.AccessToken, map[string]interface{}{
+			"community_id": 1, "title": title, "content": title + " content",
+		}, nil)
+		if status != http.StatusOK {
+			t.Fatalf("create post %s: status = %d", title, status)
+		}
+	}
+
+	// 按时间倒序
+	list := listPosts(t, h, "order=time")
+	if len(list.Data.List) != 2 || list.Data.Page.Total != 2 {
+		t.Fatalf("list by time = %+v", list.Data)
+	}
+	if list.Data.List[0].Post.Title != "second" || list.Data.List[1].Post.Title != "first" {
+		t.Fatalf("list by time order = %q, %q", list.Data.List[0].Post.Title, list.Data.List[1].Post.Title)
+	}
+	first := list.Data.List[1].Post
+	if first.AuthorID != int64(alice.UserID) || first.CommunityID != 1 || list.Data.List[1].AuthorName != "alice" {
+		t.Fatalf("first post = %+v", first)
+	}
+
+	// bob 给较早的帖子投赞成票后，按分数排序时排在前面
+	status := h.Do(t, http.MethodPost, "/api/v1/vote", bob.AccessToken, map[string]interface{}{"post_id": first.PostID, "direction": 1}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("vote: status = %d", status)
+	}
+	if n := h.Posts.VoteMessages(); n != 1 {
+		t.Errorf("vote messages = %d, want 1", n)
+	}
+	list = listPosts(t, h, "order=score")
+	if len(list.Data.List) != 2 || list.Data.List[0].Post.PostID != first.PostID || list.Data.List[0].VoteNum != 2 {
+		t.Fatalf("list by score = %+v", list.Data.List)
+	}
+
+	// bob 评论，alice 回复
+	status = h.Do(t, http.MethodPost, "/api/v1/comment", bob.AccessToken, map[string]interface{}{"post_id": first.PostID, "content": "nice post"}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("comment: status = %d", status)
+	}
+	var comments struct {
+		Data struct {
+			Comments []commentItem `json:"comments"`
+		} `json:"data"`
+	}
+	commentPath := fmt.Sprintf("/api/v1/comment?post_id=%d", first.PostID)
+	if status := h.Do(t, http.MethodGet, commentPath, bob.AccessToken, nil, &comments); status != http.StatusOK {
+		t.Fatalf("comment list: status = %d", status)
+	}
+	if len(comments.Data.Comments) != 1 {
+		t.Fatalf("comments = %+v", comments.Data.Comments)
+	}
+	parent := comments.Data.Comments[0]
+	if parent.AuthorID != bob.UserID || parent.AuthorName != "bob" || parent.Content != "nice post" {
+		t.Fatalf("comment = %+v", parent)
+	}
+
+	status = h.Do(t, http.MethodPost, "/api/v1/comment", alice.AccessToken, map[string]interface{}{
+		"post_id": first.PostID, "parent_id": parent.CommentID, "content": "thanks",
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("reply: status = %d", status)
+	}
+
+	// 帖子详情包含帖子及两条评论
+	var detail struct {
+		Data     postItem      `json:"data"`
+		Comments []commentItem `json:"comments"`
+		Degraded []string      `json:"degraded"`
+	}
+	if status := h.Do(t, http.MethodGet, fmt.Sprintf("/api/v1/post/%d", first.PostID), "", nil, &detail); status != http.StatusOK {
+		t.Fatalf("post detail: status = %d", status)
+	}
+	if detail.Data.Post.PostID != first.PostID || len(detail.Comments) != 2 || len(detail.Degraded) != 0 {
+		t.Fatalf("post detail = %+v", detail)
+	}
+	var reply *commentItem
+	for i := range detail.Comments {
+		if detail.Comments[i].ParentID == parent.CommentID {
+			reply = &detail.Comments[i]
+		}
+	}
+	if reply == nil || reply.AuthorID != alice.UserID {
+		t.Fatalf("reply not found in %+v", detail.Comments)
+	}
+
+	// 评论事件同步到 post-service，帖子列表带评论数
+	list = listPosts(t, h, "order=time")
+	for _, item := range list.Data.List {
+		want := int64(0)
+		if item.Post.PostID == first.PostID {
+			want = 2
+		}
+		if item.CommentCount != want {
+			t.Errorf("post %d comment_count = %d, want %d", item.Post.PostID, item.CommentCount, want)
+		}
+	}
+
+	// 评论数、活跃度排序只包含有评论、有活跃的帖子，全站及社区内一致
+	for _, query := range []string{"order=comments", "order=trending", "order=comments&community_id=1", "order=trending&community_id=1"} {
+		list = listPosts(t, h, query)
+		if len(list.Data.List) != 1 || list.Data.List[0].Post.PostID != first.PostID {
+			t.Errorf("list %q = %+v", query, list.Data.List)
+		}
+	}
+	if status := h.Do(t, http.MethodGet, "/api/v1/posts2?order=hot", "", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("list with unknown order: status = %d, want 400", status)
+	}
+}
+
+func TestFlow_DraftPublish(t *testing.T) {
+	h := Start(t)
+	h.Posts.AddCommunity(1, "go")
+
+	alice := signUpAndLogin(t, h, "alice")
+	bob := signUpAndLogin(t, h, "bob")
+
+	createDraft := func(body map[string]interface{}) string {
+		t.Helper()
+		var resp struct {
+			Data struct {
+				PostID string `json:"post_id"`
+			} `json:"data"`
+		}
+		if status := h.Do(t, http.MethodPost, "/api/v1/post", alice.AccessToken, body, &resp); status != http.StatusOK || resp.Data.PostID == "" {
+			t.Fatalf("create draft: status = %d, post_id = %q", status, resp.Data.PostID)
+		}
+		return resp.Data.PostID
+	}
+
+	// 草稿不出现在列表中，也不能查看详情
+	draftID := createDraft(map[string]interface{}{"community_id": 1, "title": "draft", "content": "wip", "draft": true})
+	if list := listPosts(t, h, "order=time"); len(list.Data.List) != 0 || list.Data.Page.Total != 0 {
+		t.Fatalf("list with a draft = %+v", list.Data)
+	}
+	if status := h.Do(t, http.MethodGet, "/api/v1/post/"+draftID, "", nil, nil); status != http.StatusNotFound {
+		t.Fatalf("get draft: status = %d, want %d", status, http.StatusNotFound)
+	}
+
+	// 只有作者可以修改、发布
+	update := map[string]interface{}{"community_id": 1, "title": "ready", "content": "done"}
+	if status := h.Do(t, http.MethodPut, "/api/v1/post/"+draftID+"/draft", bob.AccessToken, update, nil); status != http.StatusNotFound {
+		t.Fatalf("bob updates draft: status = %d, want %d", status, http.StatusNotFound)
+	}
+	if status := h.Do(t, http.MethodPut, "/api/v1/post/"+draftID+"/draft", alice.AccessToken, update, nil); status != http.StatusOK {
+		t.Fatalf("update draft: status = %d", status)
+	}
+	if status := h.Do(t, http.MethodPost, "/api/v1/post/"+draftID+"/publish", alice.AccessToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("publish draft: status = %d", status)
+	}
+	if status := h.Do(t, http.MethodPost, "/api/v1/post/"+draftID+"/publish", alice.AccessToken, nil, nil); status != http.StatusBadRequest {
+		t.Fatalf("publish twice: status = %d, want %d", status, http.StatusBadRequest)
+	}
+	list := listPosts(t, h, "order=time")
+	if len(list.Data.List) != 1 || list.Data.List[0].Post.Title != "ready" {
+		t.Fatalf("list after publishing = %+v", list.Data)
+	}
+
+	// 定时发布的草稿到时间后由定时任务发布
+	at := time.Now().Add(time.Hour)
+	createDraft(map[string]interface{}{"community_id": 1, "title": "scheduled", "content": "later", "publish_time": at.Format(time.RFC3339)})
+	if list := listPosts(t, h, "order=time"); len(list.Data.List) != 1 {
+		t.Fatalf("scheduled draft listed early: %+v", list.Data)
+	}
+	if n, err := h.Posts.PublishDueDrafts(context.Background(), at.Add(time.Minute)); err != nil || n != 1 {
+		t.Fatalf("PublishDueDrafts() = %d, %v, want 1, nil", n, err)
+	}
+	list = listPosts(t, h, "order=time")
+	if len(list.Data.List) != 2 || list.Data.Page.Total != 2 || list.Data.List[0].Post.Title != "scheduled" {
+		t.Fatalf("list after scheduled publishing = %+v", list.Data)
+	}
+}
+
+// TestFlow_RoleChange 角色修改无需用户重新登录即可生效
+func TestFlow_RoleChange(t *testing.T) {
+	h := Start(t)
+	h.Posts.AddCommunity(1, "go")
+
+	alice := signUpAndLogin(t, h, "alice")
+	bob := signUpAndLogin(t, h, "bob")
+	post := map[string]interface{}{"community_id": 1, "title": "hello", "content": "world"}
+	if status := h.Do(t, http.MethodPost, "/api/v1/post", alice.AccessToken, post, nil); status != http.StatusOK {
+		t.Fatalf("create post: status = %d", status)
+	}
+
+	// bob 登录后才被设为管理员，token 中仍是普通用户
+	if err := h.Users.SetRole(bob.UserID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	setRole := map[string]string{"user_id": fmt.Sprint(alice.UserID), "role": "banned"}
+	if status := h.Do(t, http.MethodPut, "/api/v1/admin/user/role", bob.AccessToken, setRole, nil); status != http.StatusOK {
+		t.Fatalf("set role as admin: status = %d", status)
+	}
+
+	// alice 被封禁后原有 token 不能再发帖
+	if status := h.Do(t, http.MethodPost, "/api/v1/post", alice.AccessToken, post, nil); status != http.StatusForbidden {
+		t.Fatalf("create post after ban: status = %d, want %d", status, http.StatusForbidden)
+	}
+}
+
+// TestFlow_RateLimitForwardedFor 未配置可信代理时伪造 X-Forwarded-For 不能绕过按 IP 的限流
+func TestFlow_RateLimitForwardedFor(t *testing.T) {
+	h := Start(t)
+	conf := *config.Conf
+	conf.RateLimit = &config.RateLimit{
+		Enabled:  true,
+		Policies: map[string]*config.RateLimitPolicy{"login": {Scope: "ip", Limit: 2, Window: time.Hour}},
+	}
+	config.Set(&conf)
+
+	for i := 1; i <= 3; i++ {
+		req, err := http.NewRequest(http.MethodPost, h.URL+"/api/v1/login", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if limited := resp.StatusCode == http.StatusTooManyRequests; limited != (i == 3) {
+			t.Fatalf("login #%d: status = %d", i, resp.StatusCode)
+		}
+	}
+}
+
+// TestFlow_LoginLockout 同一用户名连续登录失败后锁定，失败计数带有过期时间
+func TestFlow_LoginLockout(t *testing.T) {
+	h := Start(t)
+	conf := *config.Conf
+	conf.RateLimit = &config.RateLimit{
+		Enabled:      true,
+		LoginLockout: &config.LoginLockout{MaxFailures: 2, Window: time.Minute, LockDuration: time.Hour},
+	}
+	config.Set(&conf)
+	signUpAndLogin(t, h, "alice")
+
+	wrong := map[string]string{"username": "alice", "password": "wrong"}
+	if status := h.Do(t, http.MethodPost, "/api/v1/login", "", wrong, nil); status == http.StatusOK || status == http.StatusTooManyRequests {
+		t.Fatalf("first wrong login: status = %d", status)
+	}
+	if ttl := h.Redis.TTL("bluebell-plus:login:failure:alice"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("failure counter ttl = %v, want within the window", ttl)
+	}
+	if status := h.Do(t, http.MethodPost, "/api/v1/login", "", wrong, nil); status != http.StatusTooManyRequests {
+		t.Fatalf("second wrong login: status = %d, want %d", status, http.StatusTooManyRequests)
+	}
+	right := map[string]string{"username": "alice", "password": "secret"}
+	if status := h.Do(t, http.MethodPost, "/api/v1/login", "", right, nil); status != http.StatusTooManyRequests {
+		t.Fatalf("login while locked: status = %d, want %d", status, http.StatusTooManyRequests)
+	}
+}
+
+// TestFlow_RemoveComment 社区版主删除评论，comment-service 经 post-service 校验版主身份
+func TestFlow_RemoveComment(t *testing.T) {
+	h := Start(t)
+	h.Posts.AddCommunity(1, "go")
+
+	alice := signUpAndLogin(t, h, "alice")
+	bob := signUpAndLogin(t, h, "bob")
+	post := map[string]interface{}{"community_id": 1, "title": "hello", "content": "world"}
+	if status := h.Do(t, http.MethodPost, "/api/v1/post", alice.AccessToken, post, nil); status != http.StatusOK {
+		t.Fatalf("create post: status = %d", status)
+	}
+	list := listPosts(t, h, "order=time")
+	if len(list.Data.List) != 1 {
+		t.Fatalf("list = %+v", list.Data.List)
+	}
+	postID := list.Data.List[0].Post.PostID
+	if status := h.Do(t, http.MethodPost, "/api/v1/comment", bob.AccessToken, map[string]interface{}{"post_id": postID, "content": "spam"}, nil); status != http.StatusOK {
+		t.Fatalf("comment: status = %d", status)
+	}
+	var comments struct {
+		Data struct {
+			Comments []commentItem `json:"comments"`
+		} `json:"data"`
+	}
+	commentPath := fmt.Sprintf("/api/v1/comment?post_id=%d", postID)
+	if status := h.Do(t, http.MethodGet, commentPath, bob.AccessToken, nil, &comments); status != http.StatusOK || len(comments.Data.Comments) != 1 {
+		t.Fatalf("comment list: status = %d, comments = %+v", status, comments.Data.Comments)
+	}
+
+	h.Posts.AddModerator(1, alice.UserID)
+	remove := map[string]interface{}{"action": "remove_comment", "comment_id": comments.Data.Comments[0].CommentID}
+	if status := h.Do(t, http.MethodPost, "/api/v1/community/1/moderate", alice.AccessToken, remove, nil); status != http.StatusOK {
+		t.Fatalf("remove comment: status = %d", status)
+	}
+	comments.Data.Comments = nil
+	if status := h.Do(t, http.MethodGet, commentPath, bob.AccessToken, nil, &comments); status != http.StatusOK || len(comments.Data.Comments) != 0 {
+		t.Fatalf("comments after removal: status = %d, comments = %+v", status, comments.Data.Comments)
+	}
+}
@@ -0,0 +1,189 @@
+// Package e2e 在进程内启动全部服务的端到端测试环境：
+// user、post、comment 服务使用内存存储并监听 bufconn，Redis 使用 miniredis，
+// etcd 由内存注册表代替，Kafka 由记录消息的内存生产者代替，BFF 通过 httptest 提供 HTTP 接口
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	bffapp "bluebell_microservices/bff/app"
+	commentapp "bluebell_microservices/comment-service/app"
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/jwt"
+	"bluebell_microservices/common/pkg/registry"
+	"bluebell_microservices/common/pkg/snowflake"
+	postapp "bluebell_microservices/post-service/app"
+	commentpb "bluebell_microservices/proto/comment"
+	postpb "bluebell_microservices/proto/post"
+	userpb "bluebell_microservices/proto/user"
+	userapp "bluebell_microservices/user-service/app"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1 << 20
+
+// Harness 端到端测试环境，测试结束时自动关闭
+type Harness struct {
+	URL   string // BFF 地址
+	Redis *miniredis.Miniredis
+	Users *userapp.Memory
+	Posts *postapp.Memory
+
+	listeners map[string]*bufconn.Listener // 实例地址 -> 监听
+}
+
+// Start 启动全部服务；各服务的 Redis 客户端是包级变量，使用 Harness 的测试不能并行
+func Start(t testing.TB) *Harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+
+	mr := miniredis.RunT(t)
+	config.Set(&config.Config{
+		Server: &config.Server{JwtSecret: "e2e-secret"},
+		Redis:  &config.Redis{Host: mr.Host(), Port: mustPort(t, mr.Port())},
+		Etcd:   &config.Etcd{},
+	})
+	jwt.Init(config.Conf.Server.JwtSecret)
+	if err := snowflake.Init(1); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &Harness{Redis: mr, listeners: make(map[string]*bufconn.Listener)}
+	reg := registry.NewMemory()
+
+	h.Users = userapp.NewMemory()
+	h.serve(t, reg, "user", userapp.ServerOptions(), h.Users.Register)
+
+	// post-service 与生产环境一样经注册表发现 user-service、comment-service，连接在首次调用时建立
+	posts, err := postapp.NewMemory(config.Conf.Redis,
+		userpb.NewUserServiceClient(h.conn(t, reg, "user")),
+		commentpb.NewCommentServiceClient(h.conn(t, reg, "comment")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(posts.Close)
+	h.Posts = posts
+	h.serve(t, reg, "post", postapp.ServerOptions(), posts.Register)
+
+	// 评论事件不经过 Kafka，同步交给 post-service 处理；删除评论时经注册表查询 post-service 的版主身份
+	comments, err := commentapp.NewMemory(config.Conf.Redis,
+		postpb.NewPostServiceClient(h.conn(t, reg, "post")), posts.HandleCommentMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(comments.Close)
+	h.serve(t, reg, "comment", commentapp.ServerOptions(), comments.Register)
+
+	bff, err := bffapp.NewInProcess(reg, grpc.WithContextDialer(h.dial))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(bff.Close)
+	srv := httptest.NewServer(bff.Handler)
+	t.Cleanup(srv.Close)
+	h.URL = srv.URL
+	return h
+}
+
+// serve 在 bufconn 上启动服务并注册到注册表，健康检查直接报告 SERVING
+func (h *Harness) serve(t testing.TB, reg *registry.Memory, name string, opts []grpc.ServerOption, register func(*grpc.Server)) {
+	t.Helper()
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer(opts...)
+	register(s)
+	hs := health.NewServer()
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, hs)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	addr := name + "-0"
+	h.listeners[addr] = lis
+	reg.Register(&registry.Instance{ID: addr, Name: name, Addr: addr, Status: registry.StatusReady})
+}
+
+// conn 经注册表连接服务
+func (h *Harness) conn(t testing.TB, reg *registry.Memory, name string) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient("etcd://"+name,
+		grpc.WithResolvers(registry.NewMemoryResolverBuilder(reg)),
+		grpc.WithContextDialer(h.dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// dial 按实例地址连接对应的 bufconn
+func (h *Harness) dial(ctx context.Context, addr string) (net.Conn, error) {
+	lis, ok := h.listeners[addr]
+	if !ok {
+		return nil, fmt.Errorf("unknown instance %s", addr)
+	}
+	return lis.DialContext(ctx)
+}
+
+// Do 向 BFF 发送 JSON 请求，body 为 nil 时不带请求体；响应体解码到 out（可为 nil），返回状态码
+func (h *Harness) Do(t testing.TB, method, path, token string, body, out interface{}) int {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, h.URL+path, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", method, path, data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func mustPort(t testing.TB, port string) int {
+	var p int
+	if _, err := fmt.Sscanf(port, "%d", &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
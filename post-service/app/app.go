@@ -0,0 +1,144 @@
+// Package app 组装 post-service 的 gRPC 服务，供 cmd/server 及端到端测试在进程内启动
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bluebell_microservices/common/config"
+	commonkafka "bluebell_microservices/common/pkg/kafka"
+	"bluebell_microservices/common/pkg/rbac"
+	"bluebell_microservices/post-service/internal/client"
+	"bluebell_microservices/post-service/internal/controller"
+	"bluebell_microservices/post-service/internal/dao/memory"
+	"bluebell_microservices/post-service/internal/dao/mysql"
+	"bluebell_microservices/post-service/internal/dao/redis"
+	"bluebell_microservices/post-service/internal/kafka"
+	"bluebell_microservices/post-service/internal/logic"
+	"bluebell_microservices/post-service/internal/model"
+	commentpb "bluebell_microservices/proto/comment"
+	pb "bluebell_microservices/proto/post"
+	userpb "bluebell_microservices/proto/user"
+
+	"google.golang.org/grpc"
+)
+
+// ServerOptions post-service 自身的 gRPC 选项
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(rbac.UnaryServerInterceptor(controller.AccessRules)), // 敏感 RPC 鉴权
+	}
+}
+
+// Register 注册使用 MySQL、Redis 及 Kafka 的 PostService，需先初始化 mysql、redis 及 client
+func Register(s *grpc.Server) error {
+	producer := kafka.NewProducer()
+	if producer == nil {
+		return errors.New("failed to create kafka producer")
+	}
+
+	stores := newStores()
+	stores.Producer = producer
+	register(s, stores)
+	return nil
+}
+
+// CommentHandler 处理评论事件的业务逻辑，供评论事件消费者使用，需先初始化 mysql、redis 及 client
+func CommentHandler() kafka.CommentHandler {
+	return logic.NewCommentCountLogic(newStores())
+}
+
+// PublishDueDrafts 发布定时发布时间已到的草稿，返回发布的帖子数；供定时发布任务使用，需先初始化 mysql、redis 及 client
+func PublishDueDrafts(ctx context.Context) (int, error) {
+	return logic.NewPostLogic(newStores()).PublishDueDrafts(ctx, time.Now())
+}
+
+// newStores 组装业务逻辑所需的存储，投票消息生产者由调用方设置
+func newStores() logic.PostStores {
+	return logic.PostStores{
+		Posts:         mysql.NewPostDAO(),
+		Users:         client.NewUserClient(client.UserService()),
+		Votes:         redis.NewVoteDAO(),
+		Ranking:       redis.NewRankingDAO(),
+		CommentCounts: redis.NewCommentCountDAO(),
+		Comments:      client.NewCommentClient(client.CommentService()),
+		Moderation:    mysql.NewModerationDAO(),
+		Events:        redis.NewEventDAO(),
+	}
+}
+
+func register(s *grpc.Server, stores logic.PostStores) {
+	pb.RegisterPostServiceServer(s, controller.NewPostController(logic.NewPostLogic(stores), logic.NewModerationLogic(stores)))
+}
+
+// Memory 帖子、举报使用内存存储，投票、排序、评论数及实时事件使用 Redis（可以是 miniredis），投票消息不落库
+type Memory struct {
+	posts      *memory.PostStore
+	moderation *memory.ModerationStore
+	producer   *memory.Producer
+	stores     logic.PostStores
+	comments   *logic.CommentCountLogic
+}
+
+// NewMemory 连接 Redis 并创建内存版 post-service；作者信息通过 users 查询，评论数校准时通过 comments 查询
+func NewMemory(conf *config.Redis, users userpb.UserServiceClient, comments commentpb.CommentServiceClient) (*Memory, error) {
+	if err := redis.Init(conf); err != nil {
+		return nil, err
+	}
+	m := &Memory{posts: memory.NewPostStore(), producer: memory.NewProducer()}
+	m.moderation = memory.NewModerationStore(m.posts)
+	m.stores = logic.PostStores{
+		Posts:         m.posts,
+		Users:         client.NewUserClient(users),
+		Votes:         redis.NewVoteDAO(),
+		Ranking:       redis.NewRankingDAO(),
+		CommentCounts: redis.NewCommentCountDAO(),
+		Comments:      client.NewCommentClient(comments),
+		Moderation:    m.moderation,
+		Events:        redis.NewEventDAO(),
+		Producer:      m.producer,
+	}
+	m.comments = logic.NewCommentCountLogic(m.stores)
+	return m, nil
+}
+
+// Register 注册 PostService
+func (m *Memory) Register(s *grpc.Server) {
+	register(s, m.stores)
+}
+
+// HandleCommentMessage 同步处理评论事件，代替 Kafka 消费者
+func (m *Memory) HandleCommentMessage(ctx context.Context, msg commonkafka.CommentMessage) error {
+	return m.comments.ApplyCommentEvent(ctx, msg)
+}
+
+// RepairCommentCounts 按 comment-service 的评论数校准帖子评论数
+func (m *Memory) RepairCommentCounts(ctx context.Context) (int, error) {
+	return m.comments.RepairCommentCounts(ctx)
+}
+
+// PublishDueDrafts 发布定时发布时间不晚于 now 的草稿，代替定时发布任务
+func (m *Memory) PublishDueDrafts(ctx context.Context, now time.Time) (int, error) {
+	return logic.NewPostLogic(m.stores).PublishDueDrafts(ctx, now)
+}
+
+// AddCommunity 预置社区
+func (m *Memory) AddCommunity(communityID uint64, name string) {
+	m.posts.AddCommunity(&model.CommunityDetailRes{CommunityID: communityID, CommunityName: name})
+}
+
+// AddModerator 将用户设为社区版主
+func (m *Memory) AddModerator(communityID, userID uint64) {
+	m.moderation.AddModerator(communityID, userID)
+}
+
+// VoteMessages 已发送的投票消息数
+func (m *Memory) VoteMessages() int {
+	return len(m.producer.Messages())
+}
+
+// Close 关闭 Redis 连接
+func (m *Memory) Close() {
+	redis.Close()
+}
@@ -1,151 +1,178 @@
-// user-service/cmd/server/main.go
+// post-service/cmd/server/main.go
 package main
 
 import (
 	"context"
 	"flag"
-	"fmt"
 	"log"
-	"net"
+	"os"
 	"time"
 
 	"bluebell_microservices/common/config"
+	commonkafka "bluebell_microservices/common/pkg/kafka"
 	"bluebell_microservices/common/pkg/logger"
-	"bluebell_microservices/common/pkg/snowflake"
-	"bluebell_microservices/post-service/internal/controller"
+	"bluebell_microservices/common/pkg/metrics"
+	"bluebell_microservices/common/pkg/migrate"
+	"bluebell_microservices/common/pkg/server"
+	"bluebell_microservices/post-service/app"
+	"bluebell_microservices/post-service/internal/cache"
+	"bluebell_microservices/post-service/internal/client"
 	"bluebell_microservices/post-service/internal/dao/mysql"
 	"bluebell_microservices/post-service/internal/dao/redis"
 	"bluebell_microservices/post-service/internal/kafka"
-	pb "bluebell_microservices/proto/post"
+	"bluebell_microservices/post-service/internal/task"
+	"bluebell_microservices/post-service/migrations"
 
-	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
-	"google.golang.org/grpc"
-
-	// 添加这行
-	"google.golang.org/grpc/reflection"
 )
 
 func main() {
 	flag.Parse()
-
-	// 初始化日志
-	if err := logger.Init("info", "post-service.log"); err != nil {
-		log.Fatalf("Failed to init logger: %v", err)
-	}
-	defer logger.Logger.Sync() // 确保日志在程序退出时写入
-
-	// 初始化配置
-	config.InitConfig()
-
-	// 初始化雪花算法
-	if err := snowflake.Init(1); err != nil {
-		log.Fatalf("init snowflake failed, err:%v\n", err)
-	}
-
-	// 初始化数据库连接
-	if err := mysql.Init(config.Conf.MySQL); err != nil {
-		log.Fatalf("init mysql failed, err:%v\n", err)
-	}
-	defer mysql.Close()
-
-	// 初始化 Redis
-	if err := redis.Init(config.Conf.Redis); err != nil {
-		log.Fatalf("init redis failed, err:%v\n", err)
-	}
-	defer redis.Close()
-
-	// 初始化Kafka生产者
-	kafkaProducer := kafka.NewProducer()
-	defer kafkaProducer.Close()
-
-	// 初始化Kafka消费者
-	if err := kafka.Init(config.Conf.Kafka); err != nil {
-		logger.Error("Failed to init Kafka consumer", zap.Error(err))
+	if flag.Arg(0) == "migrate" {
+		runMigrate(flag.Args()[1:])
 		return
 	}
-	defer kafka.GetConsumer().Close()
-
-	// 启动Kafka消费者
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
-	if err := kafka.GetConsumer().Start(ctx); err != nil {
-		logger.Error("Failed to start Kafka consumer", zap.Error(err))
-		return
-	}
-
-	// 初始化 etcd 客户端
-	etcdEndpoints := []string{"host.docker.internal:2379"}
-	cli, err := clientv3.New(clientv3.Config{
-		Endpoints:   etcdEndpoints,
-		DialTimeout: 5 * time.Second,
+	// 趋势排序的时间桶滑出窗口后扣除活跃度，各实例都执行，每个桶只会被扣除一次
+	trending := task.New("trending-expiry", time.Minute, func(ctx context.Context) error {
+		n, err := redis.ExpireTrendingBuckets(time.Now())
+		if n > 0 {
+			logger.Info("Expired trending buckets", zap.Int("buckets", n))
+		}
+		return err
 	})
-	if err != nil {
-		log.Fatalf("连接 etcd 失败: %v", err)
-	}
-	defer cli.Close()
 
-	// 服务注册
-	if err := registerService(cli, "post", "post-service:8082"); err != nil {
-		logger.Error("Failed to register service", zap.Error(err))
-		log.Fatalf("failed to register service: %v", err)
+	// 定时发布到期的草稿，通过 etcd 选主只由一个实例执行；同一草稿只会被发布一次，交接期间的重复执行无害
+	publishDrafts := func(ctx context.Context) error {
+		n, err := app.PublishDueDrafts(ctx)
+		if n > 0 {
+			logger.Info("Published scheduled drafts", zap.Int("posts", n))
+		}
+		return err
 	}
+	var (
+		leader *task.Leader
+		drafts *task.Task
+	)
 
-	// 监听端口
-	lis, err := net.Listen("tcp", ":8082")
+	// 初始化日志、配置及雪花算法
+	srv, err := server.New("post", app.ServerOptions()...)
 	if err != nil {
-		logger.Error("Failed to listen", zap.Error(err))
-		log.Fatalf("failed to listen: %v", err)
+		log.Fatalf("init post service failed, err:%v\n", err)
 	}
-	defer lis.Close()
 
-	// 创建 gRPC 服务器
-	s := grpc.NewServer()
+	// 初始化存储、读缓存及Kafka消费者；退出时先关闭消费者（落库未满的批次），再关闭缓存、Redis、MySQL
+	// 评论事件消费者依赖 cache（使帖子详情缓存失效）及 comment-service 客户端，需在它们之后初始化
+	if err := srv.Use(
+		server.Component{
+			Name: "mysql",
+			Init: func(conf *config.Config) error {
+				if err := mysql.Init(conf.MySQL); err != nil {
+					return err
+				}
+				// 按需执行迁移，表结构版本与代码不一致时拒绝启动
+				if err := migrate.Ensure(context.Background(), mysql.DB().DB, "post", migrations.FS, conf.MySQL.AutoMigrate); err != nil {
+					return err
+				}
+				return metrics.RegisterDBStats("post", mysql.DB().DB)
+			},
+			Close: func() error { mysql.Close(); return nil },
+			Check: mysql.Ping,
+		},
+		server.Component{
+			Name: "redis",
+			Init: func(conf *config.Config) error {
+				if err := redis.Init(conf.Redis); err != nil {
+					return err
+				}
+				// 旧版本只记录社区集合，首次启动时生成社区排序 ZSet
+				if err := redis.MigrateCommunityFeeds(); err != nil {
+					return err
+				}
+				return metrics.RegisterRedisPoolStats("post", redis.Client())
+			},
+			Close: func() error { redis.Close(); return nil },
+			Check: redis.Ping,
+		},
+		server.Component{
+			Name:  "clients", // 通过 user-service 查询作者信息、comment-service 校准评论数，不可用时降级，不参与就绪判断
+			Init:  client.Init,
+			Close: client.Close,
+		},
+		server.Component{
+			Name:  "cache",
+			Init:  func(conf *config.Config) error { return cache.Init(conf.Cache, redis.Client()) },
+			Close: cache.Close,
+		},
+		server.Component{
+			Name:  "trending-expiry",
+			Init:  func(conf *config.Config) error { trending.Start(); return nil },
+			Close: trending.Stop,
+		},
+		server.Component{
+			Name: "draft-publisher", // 依赖 clients 中的 etcd 客户端选主
+			Init: func(conf *config.Config) error {
+				leader = task.NewLeader(client.Etcd(), "post-draft-publisher")
+				drafts = task.New("draft-publisher", 10*time.Second, leader.Run(publishDrafts))
+				leader.Start()
+				drafts.Start()
+				return nil
+			},
+			Close: func() error {
+				drafts.Stop()
+				return leader.Stop()
+			},
+		},
+		server.Component{
+			Name: "kafka-consumer",
+			Init: func(conf *config.Config) error {
+				if err := kafka.Init(conf.Kafka); err != nil {
+					return err
+				}
+				return kafka.GetConsumer().Start(context.Background())
+			},
+			Close: func() error { return kafka.GetConsumer().Close() },
+			Check: func(ctx context.Context) error {
+				return commonkafka.CheckBrokers(config.Conf.Kafka.Brokers, 2*time.Second)
+			},
+		},
+		server.Component{
+			Name: "comment-consumer", // 消费评论事件维护帖子评论数，broker 状态已由 kafka-consumer 检查
+			Init: func(conf *config.Config) error {
+				if err := kafka.InitCommentConsumer(conf.Kafka, app.CommentHandler()); err != nil {
+					return err
+				}
+				return kafka.GetCommentConsumer().Start(context.Background())
+			},
+			Close: func() error { return kafka.GetCommentConsumer().Close() },
+		},
+	); err != nil {
+		srv.Close()
+		log.Fatalf("init post service failed, err:%v\n", err)
+	}
 
 	// 注册微服务
-	postController, err := controller.NewPostController()
-	if err != nil {
-		logger.Error("Failed to create post controller", zap.Error(err))
-		log.Fatalf("failed to create post controller: %v", err)
+	if err := app.Register(srv.GRPC()); err != nil {
+		srv.Close()
+		log.Fatalf("init post service failed, err:%v\n", err)
 	}
-	pb.RegisterPostServiceServer(s, postController)
 
-	// 注册反射服务
-	reflection.Register(s) // 添加这行
-
-	logger.Info("Post service running", zap.String("addr", ":8082"))
-	if err := s.Serve(lis); err != nil {
+	if err := srv.Run(); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
 
-func registerService(cli *clientv3.Client, serviceName, address string) error {
-	leaseResp, err := cli.Grant(context.Background(), 10)
-	if err != nil {
-		return fmt.Errorf("创建租约失败: %v", err)
+// runMigrate 执行 migrate 子命令后退出，只需要 MySQL 配置
+func runMigrate(args []string) {
+	if err := config.InitConfig("post"); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
 	}
-
-	// 注册服务
-	key := fmt.Sprintf("/services/%s", serviceName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	_, err = cli.Put(ctx, key, address, clientv3.WithLease(leaseResp.ID))
-	cancel()
-	if err != nil {
-		return fmt.Errorf("注册服务失败: %v", err)
+	if err := mysql.Init(config.Conf.MySQL); err != nil {
+		log.Fatalf("init mysql failed, err:%v\n", err)
 	}
-	fmt.Printf("服务 %s 注册成功，地址: %s\n", serviceName, address)
+	defer mysql.Close()
 
-	// 续约
-	keepAliveChan, err := cli.KeepAlive(context.Background(), leaseResp.ID)
-	if err != nil {
-		return fmt.Errorf("续约失败: %v", err)
+	if err := migrate.Command(context.Background(), mysql.DB().DB, "post", migrations.FS, args, os.Stdout); err != nil {
+		log.Fatalf("migrate failed: %v", err)
 	}
-	go func() {
-		for range keepAliveChan {
-		}
-		fmt.Println("续约结束，租约已失效")
-	}()
-
-	return nil
 }
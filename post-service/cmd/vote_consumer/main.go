@@ -1,10 +1,13 @@
 package main
 
 import (
+	"bluebell_microservices/common/config"
 	"bluebell_microservices/common/pkg/kafka"
 	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/tracing"
 	"bluebell_microservices/post-service/internal/dao/redis"
-	"flag"
+	"context"
+	"log"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,18 +15,35 @@ import (
 	"go.uber.org/zap"
 )
 
-var (
-	kafkaBrokers = flag.String("kafka-brokers", "localhost:9092", "Kafka brokers, comma separated")
-	kafkaTopic   = flag.String("kafka-topic", "post-votes", "Kafka topic for vote messages")
-)
-
 func main() {
-	flag.Parse()
+	// 初始化配置，Kafka 地址等可通过 BLUEBELL_KAFKA_BROKERS 或 -set kafka.brokers=... 覆盖
+	if err := config.InitConfig("vote_consumer"); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 初始化日志
+	if err := logger.Init("vote-consumer", config.Conf.Log); err != nil {
+		log.Fatalf("Failed to init logger: %v", err)
+	}
+	defer logger.Logger.Sync()
+
+	// 初始化 Redis
+	if err := redis.Init(config.Conf.Redis); err != nil {
+		logger.Error("Failed to init redis", zap.Error(err))
+		os.Exit(1)
+	}
+	defer redis.Close()
+
+	// 只设置传播器，日志中带上生产者传来的 trace_id
+	if _, err := tracing.Init("vote-consumer", nil); err != nil {
+		logger.Error("Failed to init tracing", zap.Error(err))
+	}
 
 	// 初始化Kafka消费者
 	kafkaConfig := kafka.KafkaConfig{
-		Brokers: []string{*kafkaBrokers},
-		Topic:   *kafkaTopic,
+		Brokers:  config.Conf.Kafka.Brokers,
+		Topic:    config.Conf.Kafka.Topic,
+		DLQTopic: config.Conf.Kafka.DLQTopic,
 	}
 
 	consumer, err := kafka.NewConsumer(kafkaConfig)
@@ -34,8 +54,8 @@ func main() {
 	defer consumer.Close()
 
 	// 处理投票消息
-	err = consumer.ConsumeMessages(func(message kafka.VoteMessage) error {
-		logger.Info("Processing vote message",
+	err = consumer.ConsumeMessages(func(ctx context.Context, message kafka.VoteMessage) error {
+		logger.Ctx(ctx).Info("Processing vote message",
 			zap.Int64("post_id", message.PostID),
 			zap.Int64("user_id", message.UserID),
 			zap.Int64("direction", message.Direction),
@@ -44,14 +64,14 @@ func main() {
 		// 调用Redis处理投票
 		err := redis.CreatePostVote(message.PostID, message.UserID, message.Direction)
 		if err != nil {
-			logger.Error("Failed to process vote",
+			logger.Ctx(ctx).Error("Failed to process vote",
 				zap.Int64("post_id", message.PostID),
 				zap.Int64("user_id", message.UserID),
 				zap.Error(err))
 			return err
 		}
 
-		logger.Info("Vote processed successfully",
+		logger.Ctx(ctx).Info("Vote processed successfully",
 			zap.Int64("post_id", message.PostID),
 			zap.Int64("user_id", message.UserID),
 			zap.Int64("direction", message.Direction))
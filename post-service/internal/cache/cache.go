@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/logger"
+
+	"github.com/go-redis/redis"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// 缓存的数据类型
+const (
+	NamePost      = "post"      // 帖子详情
+	NameCommunity = "community" // 社区信息
+)
+
+// keyPrefix 缓存 key 前缀，完整 key 为 bluebell-plus:cache:<name>:<id>
+const keyPrefix = "bluebell-plus:cache:"
+
+// invalidateChannel 缓存失效通知，各实例收到后删除进程内缓存
+const invalidateChannel = "bluebell-plus:cache:invalidate"
+
+var (
+	conf   *config.Cache
+	client *redis.Client
+	local  *expirable.LRU[string, []byte] // 进程内缓存，未开启时为 nil
+	group  singleflight.Group
+	pubsub *redis.PubSub
+)
+
+// Init 初始化读缓存，conf 未开启时所有读取直接回源
+func Init(cfg *config.Cache, redisClient *redis.Client) error {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	conf, client = cfg, redisClient
+	if cfg.LocalSize <= 0 {
+		return nil
+	}
+
+	local = expirable.NewLRU[string, []byte](cfg.LocalSize, nil, cfg.LocalTTL)
+	// 其他实例修改数据时通过 Redis 发布失效通知，删除本地副本
+	pubsub = client.Subscribe(invalidateChannel)
+	if _, err := pubsub.Receive(); err != nil {
+		pubsub.Close()
+		return err
+	}
+	go func() {
+		for msg := range pubsub.Channel() {
+			local.Remove(msg.Payload)
+		}
+	}()
+	return nil
+}
+
+// Close 停止接收失效通知
+func Close() error {
+	if pubsub != nil {
+		return pubsub.Close()
+	}
+	return nil
+}
+
+// ttl 各类数据在 Redis 中的缓存时间
+func ttl(name string) time.Duration {
+	if name == NameCommunity {
+		return conf.CommunityTTL
+	}
+	return conf.PostTTL
+}
+
+// GetOrLoad 依次读取进程内缓存、Redis，都未命中时调用 load 回源并写入缓存
+// 同一 key 的并发回源通过 singleflight 合并为一次；load 返回错误时不缓存
+func GetOrLoad[T any](ctx context.Context, name, id string, load func() (T, error)) (T, error) {
+	if conf == nil {
+		return load()
+	}
+
+	key := keyPrefix + name + ":" + id
+	if local != nil {
+		if data, ok := local.Get(key); ok {
+			requests.WithLabelValues(name, resultLocalHit).Inc()
+			return decode[T](data)
+		}
+	}
+
+	data, err, _ := group.Do(key, func() (interface{}, error) {
+		data, err := client.Get(key).Bytes()
+		if err == nil {
+			requests.WithLabelValues(name, resultRedisHit).Inc()
+			return data, nil
+		}
+		if err != redis.Nil {
+			// Redis 不可用时直接回源，不影响读取
+			logger.Ctx(ctx).Warn("Failed to read cache", zap.String("key", key), zap.Error(err))
+		}
+
+		requests.WithLabelValues(name, resultMiss).Inc()
+		v, err := load()
+		if err != nil {
+			return nil, err
+		}
+		data, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := client.Set(key, data, ttl(name)).Err(); err != nil {
+			logger.Ctx(ctx).Warn("Failed to write cache", zap.String("key", key), zap.Error(err))
+		}
+		return data, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	if local != nil {
+		local.Add(key, data.([]byte))
+	}
+	return decode[T](data.([]byte))
+}
+
+// Invalidate 删除缓存并通知各实例删除进程内副本，数据修改后调用
+func Invalidate(ctx context.Context, name, id string) {
+	if conf == nil {
+		return
+	}
+	key := keyPrefix + name + ":" + id
+	if local != nil {
+		local.Remove(key)
+	}
+	if err := client.Del(key).Err(); err != nil {
+		logger.Ctx(ctx).Error("Failed to invalidate cache", zap.String("key", key), zap.Error(err))
+	}
+	if local != nil {
+		if err := client.Publish(invalidateChannel, key).Err(); err != nil {
+			logger.Ctx(ctx).Warn("Failed to publish cache invalidation", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// decode 每次返回新的对象，调用方修改不会影响缓存
+func decode[T any](data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
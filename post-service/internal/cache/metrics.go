@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"bluebell_microservices/common/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 缓存读取结果
+const (
+	resultLocalHit = "local_hit"
+	resultRedisHit = "redis_hit"
+	resultMiss     = "miss"
+)
+
+var requests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "cache",
+	Name:      "requests_total",
+	Help:      "读缓存请求数，按命中层级区分",
+}, []string{"name", "result"})
@@ -0,0 +1,72 @@
+// Package client post-service 调用其他微服务的客户端
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/registry"
+	"bluebell_microservices/common/pkg/tracing"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var (
+	etcdClient  *clientv3.Client
+	userConn    *grpc.ClientConn
+	commentConn *grpc.ClientConn
+)
+
+// Init 通过 etcd 发现 user-service 及 comment-service，连接在首次调用时建立
+func Init(conf *config.Config) error {
+	var err error
+	etcdClient, err = clientv3.New(clientv3.Config{
+		Endpoints:   conf.Etcd.Endpoints(),
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("连接 etcd 失败: %v", err)
+	}
+
+	if userConn, err = dial("user"); err != nil {
+		Close()
+		return fmt.Errorf("连接 user 服务失败: %v", err)
+	}
+	if commentConn, err = dial("comment"); err != nil {
+		Close()
+		return fmt.Errorf("连接 comment 服务失败: %v", err)
+	}
+	return nil
+}
+
+// Etcd 服务发现使用的 etcd 客户端，也用于后台任务选主
+func Etcd() *clientv3.Client {
+	return etcdClient
+}
+
+// dial 创建通过 etcd 解析、轮询负载均衡的连接
+func dial(service string) (*grpc.ClientConn, error) {
+	return grpc.NewClient("etcd://"+service,
+		grpc.WithResolvers(registry.NewEtcdResolverBuilder(etcdClient)),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`),
+		tracing.DialOption(),
+	)
+}
+
+// Close 关闭连接
+func Close() error {
+	if commentConn != nil {
+		commentConn.Close()
+	}
+	if userConn != nil {
+		userConn.Close()
+	}
+	if etcdClient != nil {
+		return etcdClient.Close()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	pb "bluebell_microservices/proto/comment"
+)
+
+const (
+	commentBatchSize = 100 // 与 comment-service 单次批量查询的上限一致
+	commentTimeout   = 5 * time.Second
+)
+
+// CommentService 返回 comment-service 客户端，需先调用 Init
+func CommentService() pb.CommentServiceClient {
+	return pb.NewCommentServiceClient(commentConn)
+}
+
+// CommentClient 通过 CommentService.GetCommentCounts 批量查询帖子评论数
+type CommentClient struct {
+	comments pb.CommentServiceClient
+}
+
+// NewCommentClient 创建评论数查询
+func NewCommentClient(comments pb.CommentServiceClient) *CommentClient {
+	return &CommentClient{comments: comments}
+}
+
+// GetCommentCounts 按批查询各帖子未删除的评论数，没有评论的帖子为 0
+func (c *CommentClient) GetCommentCounts(ctx context.Context, postIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(postIDs))
+	for start := 0; start < len(postIDs); start += commentBatchSize {
+		end := min(start+commentBatchSize, len(postIDs))
+		if err := c.fetch(ctx, postIDs[start:end], counts); err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// fetch 查询一批帖子的评论数并写入 counts
+func (c *CommentClient) fetch(ctx context.Context, postIDs []uint64, counts map[uint64]int64) error {
+	ctx, cancel := context.WithTimeout(ctx, commentTimeout)
+	defer cancel()
+
+	resp, err := c.comments.GetCommentCounts(ctx, &pb.GetCommentCountsRequest{PostIds: postIDs})
+	if err != nil {
+		return err
+	}
+	for id, n := range resp.Counts {
+		counts[id] = n
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"bluebell_microservices/post-service/internal/model"
+	pb "bluebell_microservices/proto/user"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// 作者信息本地缓存：用户名不可修改，缓存只需避免每次列表都调用 user-service
+const (
+	userCacheSize = 10000
+	userCacheTTL  = 5 * time.Minute
+	userBatchSize = 100 // 与 user-service 单次批量查询的上限一致
+	userTimeout   = 2 * time.Second
+)
+
+// UserService 返回 user-service 客户端，需先调用 Init
+func UserService() pb.UserServiceClient {
+	return pb.NewUserServiceClient(userConn)
+}
+
+// UserClient 通过 UserService.GetUsersByIDs 批量查询作者信息，并在进程内缓存
+type UserClient struct {
+	users pb.UserServiceClient
+	cache *expirable.LRU[uint64, *model.User]
+}
+
+// NewUserClient 创建带本地缓存的作者查询
+func NewUserClient(users pb.UserServiceClient) *UserClient {
+	return &UserClient{
+		users: users,
+		cache: expirable.NewLRU[uint64, *model.User](userCacheSize, nil, userCacheTTL),
+	}
+}
+
+// GetUsersByIDs 批量查询作者，优先读取本地缓存，未命中的按批调用 user-service；不存在的用户不缓存
+func (c *UserClient) GetUsersByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.User, error) {
+	users := make(map[uint64]*model.User, len(ids))
+	var missing []uint64
+	for _, id := range ids {
+		if _, ok := users[id]; ok {
+			continue
+		}
+		if u, ok := c.cache.Get(id); ok {
+			users[id] = u
+			continue
+		}
+		users[id] = nil // 占位，避免重复查询
+		missing = append(missing, id)
+	}
+
+	for start := 0; start < len(missing); start += userBatchSize {
+		end := min(start+userBatchSize, len(missing))
+		if err := c.fetch(ctx, missing[start:end], users); err != nil {
+			return nil, err
+		}
+	}
+
+	for id, u := range users {
+		if u == nil {
+			delete(users, id)
+		}
+	}
+	return users, nil
+}
+
+// fetch 查询一批用户并写入 users 及缓存
+func (c *UserClient) fetch(ctx context.Context, ids []uint64, users map[uint64]*model.User) error {
+	ctx, cancel := context.WithTimeout(ctx, userTimeout)
+	defer cancel()
+
+	resp, err := c.users.GetUsersByIDs(ctx, &pb.GetUsersByIDsRequest{UserIds: ids})
+	if err != nil {
+		return err
+	}
+	for _, p := range resp.Users {
+		u := &model.User{UserID: p.UserId, UserName: p.Username}
+		c.cache.Add(p.UserId, u)
+		users[p.UserId] = u
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"context"
+
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/rbac"
+	"bluebell_microservices/common/pkg/snowflake"
+	"bluebell_microservices/post-service/internal/model"
+	pb "bluebell_microservices/proto/post"
+
+	"go.uber.org/zap"
+)
+
+func (c *PostController) ReportPost(ctx context.Context, req *pb.ReportPostRequest) (*pb.ReportResponse, error) {
+	logger.Ctx(ctx).Info("Received ReportPost request",
+		zap.Int64("post_id", req.PostId),
+		zap.Int64("reporter_id", req.ReporterId))
+
+	if req.PostId == 0 || req.ReporterId == 0 {
+		return nil, errcode.ErrInvalidArgument.WithMessage("invalid post_id or reporter_id")
+	}
+
+	reportID, err := snowflake.GetID()
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to generate report ID", zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+
+	report := &model.Report{
+		ReportID:   reportID,
+		TargetID:   uint64(req.PostId),
+		ReporterID: uint64(req.ReporterId),
+		Reason:     req.Reason,
+	}
+	if err := c.moderationLogic.ReportPost(ctx, report); err != nil {
+		return nil, moderationError(ctx, "failed to report post", err)
+	}
+
+	return &pb.ReportResponse{
+		Code:     0,
+		Msg:      "success",
+		ReportId: int64(reportID),
+	}, nil
+}
+
+func (c *PostController) ReportComment(ctx context.Context, req *pb.ReportCommentRequest) (*pb.ReportResponse, error) {
+	logger.Ctx(ctx).Info("Received ReportComment request",
+		zap.Int64("comment_id", req.CommentId),
+		zap.Int64("post_id", req.PostId),
+		zap.Int64("reporter_id", req.ReporterId))
+
+	if req.CommentId == 0 || req.PostId == 0 || req.ReporterId == 0 {
+		return nil, errcode.ErrInvalidArgument.WithMessage("invalid comment_id, post_id or reporter_id")
+	}
+
+	reportID, err := snowflake.GetID()
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to generate report ID", zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+
+	report := &model.Report{
+		ReportID:       reportID,
+		TargetID:       uint64(req.CommentId),
+		PostID:         uint64(req.PostId),
+		TargetAuthorID: uint64(req.AuthorId),
+		ReporterID:     uint64(req.ReporterId),
+		Reason:         req.Reason,
+	}
+	if err := c.moderationLogic.ReportComment(ctx, report); err != nil {
+		return nil, moderationError(ctx, "failed to report comment", err)
+	}
+
+	return &pb.ReportResponse{
+		Code:     0,
+		Msg:      "success",
+		ReportId: int64(reportID),
+	}, nil
+}
+
+func (c *PostController) ListReports(ctx context.Context, req *pb.ListReportsRequest) (*pb.ListReportsResponse, error) {
+	logger.Ctx(ctx).Info("Received ListReports request",
+		zap.Int64("community_id", req.CommunityId),
+		zap.String("status", req.Status),
+		zap.Int64("page", req.Page),
+		zap.Int64("size", req.Size))
+
+	claims, ok := rbac.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, errcode.ErrUnauthenticated.WithMessage("missing caller identity")
+	}
+	if err := c.moderationLogic.CheckModerator(ctx, claims.UserID, claims.Role, uint64(req.CommunityId)); err != nil {
+		return nil, moderationError(ctx, "failed to list reports", err)
+	}
+
+	reports, total, err := c.moderationLogic.ListReports(ctx, uint64(req.CommunityId), req.Status, req.Page, req.Size)
+	if err != nil {
+		logger.Ctx(ctx).Error("ListReports failed", zap.Error(err))
+		return nil, moderationError(ctx, "failed to list reports", err)
+	}
+
+	pbReports := make([]*pb.Report, 0, len(reports))
+	for _, report := range reports {
+		pbReports = append(pbReports, convertReport(report))
+	}
+
+	return &pb.ListReportsResponse{
+		Code: 0,
+		Msg:  "success",
+		Page: &pb.Page{
+			Total: total,
+			Page:  req.Page,
+			Size:  req.Size,
+		},
+		Reports: pbReports,
+	}, nil
+}
+
+func (c *PostController) GetReport(ctx context.Context, req *pb.GetReportRequest) (*pb.GetReportResponse, error) {
+	logger.Ctx(ctx).Info("Received GetReport request", zap.Int64("report_id", req.ReportId))
+
+	report, err := c.moderationLogic.GetReport(ctx, uint64(req.ReportId))
+	if err != nil {
+		return nil, moderationError(ctx, "failed to get report", err)
+	}
+	claims, ok := rbac.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, errcode.ErrUnauthenticated.WithMessage("missing caller identity")
+	}
+	if err := c.moderationLogic.CheckModerator(ctx, claims.UserID, claims.Role, report.CommunityID); err != nil {
+		return nil, moderationError(ctx, "failed to get report", err)
+	}
+
+	return &pb.GetReportResponse{
+		Code:   0,
+		Msg:    "success",
+		Report: convertReport(report),
+	}, nil
+}
+
+func (c *PostController) Moderate(ctx context.Context, req *pb.ModerateRequest) (*pb.ModerateResponse, error) {
+	logger.Ctx(ctx).Info("Received Moderate request",
+		zap.Int64("moderator_id", req.ModeratorId),
+		zap.Int64("community_id", req.CommunityId),
+		zap.String("action", req.Action),
+		zap.Int64("report_id", req.ReportId))
+
+	claims, ok := rbac.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, errcode.ErrUnauthenticated.WithMessage("missing caller identity")
+	}
+	err := c.moderationLogic.Moderate(ctx, &model.ParamModerate{
+		ModeratorID:   uint64(req.ModeratorId),
+		ModeratorRole: claims.Role,
+		CommunityID:   uint64(req.CommunityId),
+		Action:        req.Action,
+		PostID:        uint64(req.PostId),
+		CommentID:     uint64(req.CommentId),
+		UserID:        uint64(req.UserId),
+		ReportID:      uint64(req.ReportId),
+		Reason:        req.Reason,
+		ValidateOnly:  req.ValidateOnly,
+	})
+	if err != nil {
+		return nil, moderationError(ctx, "failed to moderate", err)
+	}
+
+	return &pb.ModerateResponse{
+		Code: 0,
+		Msg:  "success",
+	}, nil
+}
+
+func (c *PostController) GetCommunityRole(ctx context.Context, req *pb.GetCommunityRoleRequest) (*pb.GetCommunityRoleResponse, error) {
+	role, err := c.moderationLogic.GetCommunityRole(ctx, uint64(req.UserId), uint64(req.CommunityId), uint64(req.PostId))
+	if err != nil {
+		return nil, moderationError(ctx, "failed to get community role", err)
+	}
+
+	return &pb.GetCommunityRoleResponse{
+		Code:        0,
+		Msg:         "success",
+		CommunityId: int64(role.CommunityID),
+		IsModerator: role.IsModerator,
+		IsBanned:    role.IsBanned,
+	}, nil
+}
+
+// moderationError 将举报与版主操作的业务错误转换为 gRPC 错误
+func moderationError(ctx context.Context, msg string, err error) error {
+	logger.Ctx(ctx).Warn(msg, zap.Error(err))
+	return errcode.ToStatus(err)
+}
+
+// convertReport 将 model.Report 转换为 pb.Report
+func convertReport(report *model.Report) *pb.Report {
+	return &pb.Report{
+		ReportId:       int64(report.ReportID),
+		TargetType:     report.TargetType,
+		TargetId:       int64(report.TargetID),
+		PostId:         int64(report.PostID),
+		CommunityId:    int64(report.CommunityID),
+		TargetAuthorId: int64(report.TargetAuthorID),
+		ReporterId:     int64(report.ReporterID),
+		Reason:         report.Reason,
+		Status:         report.Status,
+		HandlerId:      int64(report.HandlerID),
+		CreateTime:     report.CreateTime.Format("2006-01-02 15:04:05"),
+		UpdateTime:     report.UpdateTime.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -2,8 +2,11 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/event"
 	"bluebell_microservices/common/pkg/logger"
 	"bluebell_microservices/common/pkg/snowflake"
 	"bluebell_microservices/post-service/internal/logic"
@@ -11,29 +14,23 @@ import (
 	pb "bluebell_microservices/proto/post"
 
 	"go.uber.org/zap"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type PostController struct {
 	pb.UnimplementedPostServiceServer
-	postLogic *logic.PostLogic
+	postLogic       *logic.PostLogic
+	moderationLogic *logic.ModerationLogic
 }
 
-func NewPostController() (*PostController, error) {
-	postLogic, err := logic.NewPostLogic()
-	if err != nil {
-		logger.Error("Failed to create post logic", zap.Error(err))
-		return nil, err
-	}
-
+func NewPostController(postLogic *logic.PostLogic, moderationLogic *logic.ModerationLogic) *PostController {
 	return &PostController{
-		postLogic: postLogic,
-	}, nil
+		postLogic:       postLogic,
+		moderationLogic: moderationLogic,
+	}
 }
 
 func (c *PostController) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.CreatePostResponse, error) {
-	logger.Info("Received CreatePost request",
+	logger.Ctx(ctx).Info("Received CreatePost request",
 		zap.Int64("author_id", req.AuthorId),
 		zap.Int64("community_id", req.CommunityId),
 		zap.String("title", req.Title))
@@ -41,14 +38,14 @@ func (c *PostController) CreatePost(ctx context.Context, req *pb.CreatePostReque
 	// 生成帖子ID
 	postID, err := snowflake.GetID()
 	if err != nil {
-		logger.Error("Failed to generate post ID", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "failed to generate post ID: %v", err)
+		logger.Ctx(ctx).Error("Failed to generate post ID", zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 
 	// 确保 author_id 不为 0
 	if req.AuthorId == 0 {
-		logger.Error("Invalid author_id", zap.Int64("author_id", req.AuthorId))
-		return nil, status.Errorf(codes.InvalidArgument, "invalid author_id: %d", req.AuthorId)
+		logger.Ctx(ctx).Error("Invalid author_id", zap.Int64("author_id", req.AuthorId))
+		return nil, errcode.ErrInvalidArgument.WithMessage(fmt.Sprintf("invalid author_id: %d", req.AuthorId))
 	}
 
 	post := &model.Post{
@@ -59,32 +56,81 @@ func (c *PostController) CreatePost(ctx context.Context, req *pb.CreatePostReque
 		CommunityID: uint64(req.CommunityId),
 		CreateTime:  time.Now(),
 		UpdateTime:  time.Now(),
+		PublishTime: publishTime(req.PublishTime),
+	}
+	// 设置了定时发布时间的帖子总是保存为草稿
+	if req.Draft || post.PublishTime != nil {
+		post.Status = model.PostStatusDraft
 	}
 
-	logger.Info("Creating post",
+	logger.Ctx(ctx).Info("Creating post",
 		zap.Uint64("post_id", post.PostID),
 		zap.Uint64("author_id", post.AuthorId),
 		zap.Uint64("community_id", post.CommunityID))
 
 	err = c.postLogic.CreatePost(ctx, post)
 	if err != nil {
-		logger.Error("Failed to create post", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "failed to create post: %v", err)
+		logger.Ctx(ctx).Error("Failed to create post", zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 
 	return &pb.CreatePostResponse{
-		Code: 0,
-		Msg:  "success",
+		Code:   0,
+		Msg:    "success",
+		PostId: int64(post.PostID),
 	}, nil
 }
 
+// UpdateDraft 修改草稿，publish_time 为 0 时取消定时发布
+func (c *PostController) UpdateDraft(ctx context.Context, req *pb.UpdateDraftRequest) (*pb.UpdateDraftResponse, error) {
+	logger.Ctx(ctx).Info("Received UpdateDraft request",
+		zap.Int64("post_id", req.PostId),
+		zap.Int64("author_id", req.AuthorId),
+		zap.Int64("publish_time", req.PublishTime))
+
+	post := &model.Post{
+		PostID:      uint64(req.PostId),
+		Title:       req.Title,
+		Content:     req.Content,
+		CommunityID: uint64(req.CommunityId),
+		PublishTime: publishTime(req.PublishTime),
+	}
+	if err := c.postLogic.UpdateDraft(ctx, uint64(req.AuthorId), post); err != nil {
+		logger.Ctx(ctx).Error("UpdateDraft failed", zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+	return &pb.UpdateDraftResponse{Code: 0, Msg: "success"}, nil
+}
+
+// PublishDraft 立即发布草稿
+func (c *PostController) PublishDraft(ctx context.Context, req *pb.PublishDraftRequest) (*pb.PublishDraftResponse, error) {
+	logger.Ctx(ctx).Info("Received PublishDraft request",
+		zap.Int64("post_id", req.PostId),
+		zap.Int64("author_id", req.AuthorId))
+
+	if err := c.postLogic.PublishDraft(ctx, uint64(req.AuthorId), req.PostId); err != nil {
+		logger.Ctx(ctx).Error("PublishDraft failed", zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+	return &pb.PublishDraftResponse{Code: 0, Msg: "success"}, nil
+}
+
+// publishTime 将请求中的 Unix 秒转换为定时发布时间，0 表示不定时
+func publishTime(sec int64) *time.Time {
+	if sec <= 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0)
+	return &t
+}
+
 func (c *PostController) GetPostById(ctx context.Context, req *pb.GetPostByIdRequest) (*pb.GetPostByIdResponse, error) {
-	logger.Info("Received GetPostById request", zap.Int64("post_id", req.PostId))
+	logger.Ctx(ctx).Info("Received GetPostById request", zap.Int64("post_id", req.PostId))
 
 	post, err := c.postLogic.GetPostById(ctx, req.PostId)
 	if err != nil {
-		logger.Error("GetPostById failed", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "failed to get post by id: %v", err)
+		logger.Ctx(ctx).Error("GetPostById failed", zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 
 	return &pb.GetPostByIdResponse{
@@ -107,14 +153,29 @@ func (c *PostController) GetPostById(ctx context.Context, req *pb.GetPostByIdReq
 				Introduction:  post.CommunityDetailRes.Introduction,
 				CreateTime:    post.CommunityDetailRes.CreateTime,
 			},
-			AuthorName: post.AuthorName,
-			VoteNum:    post.VoteNum,
+			AuthorName:   post.AuthorName,
+			VoteNum:      post.VoteNum,
+			CommentCount: post.CommentCount,
 		},
 	}, nil
 }
 
+func (c *PostController) GetPostsByIDs(ctx context.Context, req *pb.GetPostsByIDsRequest) (*pb.GetPostsByIDsResponse, error) {
+	posts, err := c.postLogic.GetPostsByIDs(ctx, req.PostIds)
+	if err != nil {
+		logger.Ctx(ctx).Warn("GetPostsByIDs failed", zap.Int("count", len(req.PostIds)), zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+
+	return &pb.GetPostsByIDsResponse{
+		Code:  0,
+		Msg:   "success",
+		Posts: convertPostList(posts),
+	}, nil
+}
+
 func (c *PostController) GetPostList(ctx context.Context, req *pb.GetPostListRequest) (*pb.GetPostListResponse, error) {
-	logger.Info("Received GetPostList request",
+	logger.Ctx(ctx).Info("Received GetPostList request",
 		zap.String("search", req.Search),
 		zap.Int64("page", req.Page),
 		zap.Int64("size", req.Size),
@@ -132,17 +193,17 @@ func (c *PostController) GetPostList(ctx context.Context, req *pb.GetPostListReq
 	// 调用逻辑层获取帖子列表
 	data, err := c.postLogic.GetPostListPre(ctx, param)
 	if err != nil {
-		logger.Error("GetPostList failed",
+		logger.Ctx(ctx).Error("GetPostList failed",
 			zap.String("search", req.Search),
 			zap.Int64("page", req.Page),
 			zap.Int64("size", req.Size),
 			zap.String("order", req.Order),
 			zap.Int64("community_id", req.CommunityId),
 			zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "failed to get post list: %v", err)
+		return nil, errcode.ToStatus(err)
 	}
 
-	logger.Info("GetPostList success",
+	logger.Ctx(ctx).Info("GetPostList success",
 		zap.Int64("total", data.Page.Total),
 		zap.Int64("page", data.Page.Page),
 		zap.Int64("size", data.Page.Size),
@@ -161,7 +222,7 @@ func (c *PostController) GetPostList(ctx context.Context, req *pb.GetPostListReq
 }
 
 func (c *PostController) SearchPosts(ctx context.Context, req *pb.SearchPostsRequest) (*pb.SearchPostsResponse, error) {
-	logger.Info("Received SearchPosts request",
+	logger.Ctx(ctx).Info("Received SearchPosts request",
 		zap.String("search", req.Search),
 		zap.Int64("page", req.Page),
 		zap.Int64("size", req.Size),
@@ -178,8 +239,8 @@ func (c *PostController) SearchPosts(ctx context.Context, req *pb.SearchPostsReq
 
 	data, err := c.postLogic.GetPostListPre(ctx, param)
 	if err != nil {
-		logger.Error("SearchPosts failed", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "failed to search posts: %v", err)
+		logger.Ctx(ctx).Error("SearchPosts failed", zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 
 	return &pb.SearchPostsResponse{
@@ -215,8 +276,9 @@ func convertPostList(posts []*model.ApiPostDetail) []*pb.ApiPostDetail {
 				Introduction:  postDetail.CommunityDetailRes.Introduction,
 				CreateTime:    postDetail.CommunityDetailRes.CreateTime,
 			},
-			AuthorName: postDetail.AuthorName,
-			VoteNum:    postDetail.VoteNum,
+			AuthorName:   postDetail.AuthorName,
+			VoteNum:      postDetail.VoteNum,
+			CommentCount: postDetail.CommentCount,
 		}
 		result = append(result, pbPost)
 	}
@@ -224,15 +286,15 @@ func convertPostList(posts []*model.ApiPostDetail) []*pb.ApiPostDetail {
 }
 
 func (c *PostController) Vote(ctx context.Context, req *pb.VoteRequest) (*pb.VoteResponse, error) {
-	logger.Info("Received Vote request",
+	logger.Ctx(ctx).Info("Received Vote request",
 		zap.Int64("post_id", req.PostId),
 		zap.Int64("direction", req.Direction),
 		zap.Int64("user_id", req.UserId))
 
 	err := c.postLogic.Vote(ctx, req.PostId, req.Direction, req.UserId)
 	if err != nil {
-		logger.Error("Vote failed", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "failed to vote: %v", err)
+		logger.Ctx(ctx).Error("Vote failed", zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 
 	return &pb.VoteResponse{
@@ -240,3 +302,30 @@ func (c *PostController) Vote(ctx context.Context, req *pb.VoteRequest) (*pb.Vot
 		Msg:  "success",
 	}, nil
 }
+
+func (c *PostController) SubscribePost(req *pb.SubscribePostRequest, stream pb.PostService_SubscribePostServer) error {
+	ctx := stream.Context()
+	logger.Ctx(ctx).Info("Received SubscribePost request", zap.Int64("post_id", req.PostId))
+
+	if req.PostId == 0 {
+		return errcode.ErrInvalidArgument.WithMessage(fmt.Sprintf("invalid post_id: %d", req.PostId))
+	}
+
+	err := c.postLogic.SubscribePost(ctx, req.PostId, func(ev *event.PostEvent) error {
+		return stream.Send(&pb.PostEvent{
+			Type:       ev.Type,
+			PostId:     ev.PostID,
+			VoteNum:    ev.VoteNum,
+			CommentId:  ev.CommentID,
+			ParentId:   ev.ParentID,
+			AuthorId:   ev.AuthorID,
+			Content:    ev.Content,
+			CreateTime: ev.CreateTime,
+		})
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("SubscribePost failed", zap.Int64("post_id", req.PostId), zap.Error(err))
+		return errcode.ToStatus(err)
+	}
+	return nil
+}
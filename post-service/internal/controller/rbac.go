@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"bluebell_microservices/common/pkg/rbac"
+	pb "bluebell_microservices/proto/post"
+)
+
+// AccessRules post-service 中需要鉴权的 RPC，读接口不做限制
+var AccessRules = map[string]rbac.Rule{
+	pb.PostService_CreatePost_FullMethodName: {
+		Permission: rbac.PermPostCreate,
+		Subject:    func(req interface{}) uint64 { return uint64(req.(*pb.CreatePostRequest).AuthorId) },
+	},
+	// 修改、发布草稿与发帖使用同一权限，是否为作者在业务层校验
+	pb.PostService_UpdateDraft_FullMethodName: {
+		Permission: rbac.PermPostCreate,
+		Subject:    func(req interface{}) uint64 { return uint64(req.(*pb.UpdateDraftRequest).AuthorId) },
+	},
+	pb.PostService_PublishDraft_FullMethodName: {
+		Permission: rbac.PermPostCreate,
+		Subject:    func(req interface{}) uint64 { return uint64(req.(*pb.PublishDraftRequest).AuthorId) },
+	},
+	pb.PostService_Vote_FullMethodName: {
+		Permission: rbac.PermPostVote,
+		Subject:    func(req interface{}) uint64 { return uint64(req.(*pb.VoteRequest).UserId) },
+	},
+	pb.PostService_ReportPost_FullMethodName: {
+		Permission: rbac.PermReportCreate,
+		Subject:    func(req interface{}) uint64 { return uint64(req.(*pb.ReportPostRequest).ReporterId) },
+	},
+	pb.PostService_ReportComment_FullMethodName: {
+		Permission: rbac.PermReportCreate,
+		Subject:    func(req interface{}) uint64 { return uint64(req.(*pb.ReportCommentRequest).ReporterId) },
+	},
+	// 社区版主身份在业务层校验
+	pb.PostService_ListReports_FullMethodName: {
+		Permission: rbac.PermActive,
+	},
+	pb.PostService_GetReport_FullMethodName: {
+		Permission: rbac.PermActive,
+	},
+	pb.PostService_Moderate_FullMethodName: {
+		Permission: rbac.PermActive,
+		Subject:    func(req interface{}) uint64 { return uint64(req.(*pb.ModerateRequest).ModeratorId) },
+	},
+}
@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// CommentCounter 评论数的内存实现，模拟 comment-service 的 GetCommentCounts
+type CommentCounter struct {
+	mu     sync.Mutex
+	counts map[uint64]int64
+	Err    error // 不为 nil 时 GetCommentCounts 返回该错误
+}
+
+// NewCommentCounter 创建所有帖子评论数为 0 的 CommentCounter
+func NewCommentCounter() *CommentCounter {
+	return &CommentCounter{counts: make(map[uint64]int64)}
+}
+
+// SetCount 设置帖子的评论数
+func (c *CommentCounter) SetCount(postID uint64, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[postID] = n
+}
+
+// GetCommentCounts 各帖子的评论数，没有评论的帖子为 0
+func (c *CommentCounter) GetCommentCounts(ctx context.Context, postIDs []uint64) (map[uint64]int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	counts := make(map[uint64]int64, len(postIDs))
+	for _, id := range postIDs {
+		counts[id] = c.counts[id]
+	}
+	return counts, nil
+}
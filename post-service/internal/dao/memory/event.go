@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"bluebell_microservices/common/pkg/event"
+	commonkafka "bluebell_microservices/common/pkg/kafka"
+)
+
+// EventBus 帖子实时事件的内存发布订阅，同时记录所有发布的事件
+type EventBus struct {
+	mu        sync.Mutex
+	published []*event.PostEvent
+	subs      map[int64]map[*subscription]bool
+}
+
+// NewEventBus 创建没有订阅方的事件总线
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[int64]map[*subscription]bool)}
+}
+
+// PublishPostEvent 发布帖子实时事件，订阅方缓冲区已满时丢弃，与 Redis pub/sub 一样不保证送达
+func (b *EventBus) PublishPostEvent(ev *event.PostEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, ev)
+	for s := range b.subs[ev.PostID] {
+		select {
+		case s.events <- ev:
+		default:
+		}
+	}
+	return nil
+}
+
+// SubscribePostEvents 订阅指定帖子的实时事件
+func (b *EventBus) SubscribePostEvents(postID int64) (event.Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := &subscription{bus: b, postID: postID, events: make(chan *event.PostEvent, 16)}
+	if b.subs[postID] == nil {
+		b.subs[postID] = make(map[*subscription]bool)
+	}
+	b.subs[postID][s] = true
+	return s, nil
+}
+
+// Published 已发布的事件
+func (b *EventBus) Published() []*event.PostEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*event.PostEvent(nil), b.published...)
+}
+
+// Subscribers 指定帖子当前的订阅数
+func (b *EventBus) Subscribers(postID int64) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[postID])
+}
+
+type subscription struct {
+	bus    *EventBus
+	postID int64
+	events chan *event.PostEvent
+}
+
+func (s *subscription) Events() <-chan *event.PostEvent {
+	return s.events
+}
+
+func (s *subscription) Close() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if s.bus.subs[s.postID][s] {
+		delete(s.bus.subs[s.postID], s)
+		close(s.events)
+	}
+	return nil
+}
+
+// Producer 记录发送的投票消息，代替 Kafka 生产者
+type Producer struct {
+	mu       sync.Mutex
+	messages []commonkafka.VoteMessage
+	Err      error // 非空时发送返回该错误，用于模拟 Kafka 不可用
+}
+
+// NewProducer 创建空的消息记录
+func NewProducer() *Producer {
+	return &Producer{}
+}
+
+// SendVoteMessage 记录投票消息
+func (p *Producer) SendVoteMessage(ctx context.Context, message commonkafka.VoteMessage) error {
+	if p.Err != nil {
+		return p.Err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, message)
+	return nil
+}
+
+// Messages 已发送的投票消息
+func (p *Producer) Messages() []commonkafka.VoteMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]commonkafka.VoteMessage(nil), p.messages...)
+}
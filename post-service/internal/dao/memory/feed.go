@@ -0,0 +1,325 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	postredis "bluebell_microservices/post-service/internal/dao/redis"
+	"bluebell_microservices/post-service/internal/model"
+)
+
+// FeedStore 投票记录、帖子排序索引及评论数的内存存储，同时实现 logic.VoteStore、logic.RankingIndex 与 logic.CommentCountIndex
+// 三者在 Redis 中共用数据（发帖时作者默认投赞成票、投票改变帖子分数、评论数保存在帖子 hash 中），因此合为一个类型，返回的错误与 redis 包一致
+type FeedStore struct {
+	mu          sync.Mutex
+	now         func() time.Time
+	times       map[uint64]float64           // 对应时间 ZSet
+	scores      map[uint64]float64           // 对应分数 ZSet
+	communities map[uint64]map[uint64]bool   // 社区 -> 帖子集合
+	votes       map[uint64]map[int64]float64 // 帖子 -> 用户 -> 票值
+	status      map[[2]int64]int64
+	locks       map[[2]int64]time.Time // 锁的过期时间
+	comments    map[uint64]int64       // 对应帖子 hash 的 comments 字段
+	trending    map[uint64]float64     // 对应活跃度 ZSet，不模拟时间桶的过期
+	events      map[string]bool        // 已处理的评论事件
+}
+
+// NewFeedStore 创建空的投票及排序索引
+func NewFeedStore() *FeedStore {
+	return &FeedStore{
+		now:         time.Now,
+		times:       make(map[uint64]float64),
+		scores:      make(map[uint64]float64),
+		communities: make(map[uint64]map[uint64]bool),
+		votes:       make(map[uint64]map[int64]float64),
+		status:      make(map[[2]int64]int64),
+		locks:       make(map[[2]int64]time.Time),
+		comments:    make(map[uint64]int64),
+		trending:    make(map[uint64]float64),
+		events:      make(map[string]bool),
+	}
+}
+
+// SetPostTime 修改帖子的发布时间及对应分数，用于模拟超过投票期限的帖子
+func (s *FeedStore) SetPostTime(postID uint64, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	diff := float64(t.Unix()) - s.times[postID]
+	s.times[postID] += diff
+	s.scores[postID] += diff
+}
+
+// Score 帖子当前分数，供测试断言
+func (s *FeedStore) Score(postID uint64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scores[postID]
+}
+
+// VoteStatus 投票的落库状态，没有记录时返回 false
+func (s *FeedStore) VoteStatus(postID, userID int64) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.status[[2]int64{postID, userID}]
+	return v, ok
+}
+
+// CreatePost 将新帖子加入索引，作者默认投赞成票
+func (s *FeedStore) CreatePost(postID, authorID uint64, title, content string, communityID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := float64(s.now().Unix())
+	s.times[postID] = now
+	s.scores[postID] = now + postredis.VoteScore
+	if s.communities[communityID] == nil {
+		s.communities[communityID] = make(map[uint64]bool)
+	}
+	s.communities[communityID][postID] = true
+	s.votes[postID] = map[int64]float64{int64(authorID): 1}
+	s.comments[postID] = 0
+	return nil
+}
+
+// GetPostIDsInOrder 按排序方式从大到小分页
+func (s *FeedStore) GetPostIDsInOrder(req *model.ParamPostList) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.idsInOrder(req, nil), nil
+}
+
+// GetCommunityPostIDsInOrder 社区内的帖子按排序方式从大到小分页
+func (s *FeedStore) GetCommunityPostIDsInOrder(p *model.ParamPostList) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.idsInOrder(p, s.communityMembers(p.CommunityID)), nil
+}
+
+// CountPosts 按排序方式列出的帖子数，communityID 为 0 时统计全站
+func (s *FeedStore) CountPosts(order string, communityID int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var members map[uint64]bool
+	if communityID > 0 {
+		members = s.communityMembers(communityID)
+	}
+	return int64(len(s.inOrder(order, members))), nil
+}
+
+// communityMembers 社区内的帖子，社区没有帖子时返回空集合而不是 nil
+func (s *FeedStore) communityMembers(communityID int64) map[uint64]bool {
+	if members := s.communities[uint64(communityID)]; members != nil {
+		return members
+	}
+	return map[uint64]bool{}
+}
+
+// idsInOrder members 为 nil 时不限社区
+func (s *FeedStore) idsInOrder(req *model.ParamPostList, members map[uint64]bool) []string {
+	ids := s.inOrder(req.Order, members)
+	out := make([]string, 0, req.Size)
+	for _, id := range paginate(ids, req.Page, req.Size) {
+		out = append(out, strconv.FormatUint(id, 10))
+	}
+	return out
+}
+
+// inOrder 按排序方式从大到小排列的帖子；与 Redis 一致，评论数、活跃度排序只包含评论数、活跃度大于 0 的帖子
+func (s *FeedStore) inOrder(order string, members map[uint64]bool) []uint64 {
+	zset := s.orderScores(order)
+	ids := make([]uint64, 0, len(zset))
+	for id := range zset {
+		if members == nil || members[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if zset[ids[i]] != zset[ids[j]] {
+			return zset[ids[i]] > zset[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+	return ids
+}
+
+// orderScores 排序方式对应的分数，只包含仍在列表中的帖子
+func (s *FeedStore) orderScores(order string) map[uint64]float64 {
+	switch order {
+	case model.OrderScore:
+		return s.scores
+	case model.OrderComments:
+		zset := make(map[uint64]float64)
+		for id, n := range s.comments {
+			if _, ok := s.times[id]; ok && n > 0 {
+				zset[id] = float64(n)
+			}
+		}
+		return zset
+	case model.OrderTrending:
+		return s.trending
+	default:
+		return s.times
+	}
+}
+
+// RemovePostFromFeeds 将帖子从各排序及社区集合中移除
+func (s *FeedStore) RemovePostFromFeeds(postID, communityID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.times, postID)
+	delete(s.scores, postID)
+	delete(s.trending, postID)
+	delete(s.communities[communityID], postID)
+	return nil
+}
+
+// CreatePostVote 记录投票并更新帖子分数，规则与 redis.CreatePostVote 一致
+func (s *FeedStore) CreatePostVote(postID, userID, direction int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := uint64(postID)
+	if float64(s.now().Unix())-s.times[id] > postredis.OneWeekInSeconds {
+		return postredis.ErrorVoteTimeExpire
+	}
+
+	v, ov := float64(direction), s.votes[id][userID]
+	if v == ov {
+		return postredis.ErrVoteRepeated
+	}
+	s.scores[id] += postredis.VoteScore * (v - ov)
+	if v == 0 {
+		delete(s.votes[id], userID)
+		return nil
+	}
+	if s.votes[id] == nil {
+		s.votes[id] = make(map[int64]float64)
+	}
+	s.votes[id][userID] = v
+	return nil
+}
+
+// GetPostVoteNum 帖子的赞成票数
+func (s *FeedStore) GetPostVoteNum(id int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.upVotes(uint64(id)), nil
+}
+
+// GetPostVoteData 按 ids 的顺序返回各帖子的赞成票数
+func (s *FeedStore) GetPostVoteData(ids []string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		n, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, s.upVotes(n))
+	}
+	return data, nil
+}
+
+func (s *FeedStore) upVotes(postID uint64) int64 {
+	var n int64
+	for _, v := range s.votes[postID] {
+		if v == 1 {
+			n++
+		}
+	}
+	return n
+}
+
+// SetVoteStatus 设置投票状态，过期时间被忽略
+func (s *FeedStore) SetVoteStatus(postID, userID int64, status int64, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[[2]int64{postID, userID}] = status
+	return nil
+}
+
+// AcquireLock 获取投票锁，锁未过期时返回 false
+func (s *FeedStore) AcquireLock(postID, userID int64, expiration time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := [2]int64{postID, userID}
+	if expireAt, ok := s.locks[key]; ok && s.now().Before(expireAt) {
+		return false, nil
+	}
+	s.locks[key] = s.now().Add(expiration)
+	return true, nil
+}
+
+// ReleaseLock 释放投票锁
+func (s *FeedStore) ReleaseLock(postID, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.locks, [2]int64{postID, userID})
+	return nil
+}
+
+// MarkCommentEvent 记录评论事件已处理，事件此前已处理过时返回 false
+func (s *FeedStore) MarkCommentEvent(commentID int64, typ string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := typ + ":" + strconv.FormatInt(commentID, 10)
+	if s.events[key] {
+		return false, nil
+	}
+	s.events[key] = true
+	return true, nil
+}
+
+// UnmarkCommentEvent 删除评论事件的处理记录
+func (s *FeedStore) UnmarkCommentEvent(commentID int64, typ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.events, typ+":"+strconv.FormatInt(commentID, 10))
+	return nil
+}
+
+// IncrPostComments 增减帖子评论数，不会减为负数；与 Redis 一致，帖子不存在时不记录
+func (s *FeedStore) IncrPostComments(postID, delta int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n, ok := s.comments[uint64(postID)]; ok {
+		s.comments[uint64(postID)] = max(n+delta, 0)
+	}
+	return nil
+}
+
+// SetPostComments 校准帖子评论数，帖子不存在时不记录
+func (s *FeedStore) SetPostComments(postID, count int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.comments[uint64(postID)]; ok {
+		s.comments[uint64(postID)] = count
+	}
+	return nil
+}
+
+// GetPostCommentData 按 ids 的顺序返回各帖子的评论数
+func (s *FeedStore) GetPostCommentData(ids []string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		n, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, s.comments[n])
+	}
+	return data, nil
+}
+
+// AddTrendingActivity 记录帖子的一次活跃，帖子已不在列表中时忽略
+func (s *FeedStore) AddTrendingActivity(postID int64, weight float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.times[uint64(postID)]; ok {
+		s.trending[uint64(postID)] += weight
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"bluebell_microservices/post-service/internal/dao/mysql"
+	"bluebell_microservices/post-service/internal/model"
+)
+
+// ModerationStore 举报、版主及社区封禁的内存存储，返回的错误与 mysql.ModerationDAO 一致
+type ModerationStore struct {
+	posts *PostStore // 隐藏帖子时修改帖子状态
+
+	mu         sync.RWMutex
+	reports    map[uint64]*model.Report
+	moderators map[[2]uint64]bool // {communityID, userID}
+	banned     map[[2]uint64]bool // {communityID, userID}
+	logs       []*model.ModerationLog
+}
+
+// NewModerationStore 创建空的版主数据存储，hide_post 操作修改 posts 中的帖子状态
+func NewModerationStore(posts *PostStore) *ModerationStore {
+	return &ModerationStore{
+		posts:      posts,
+		reports:    make(map[uint64]*model.Report),
+		moderators: make(map[[2]uint64]bool),
+		banned:     make(map[[2]uint64]bool),
+	}
+}
+
+// AddModerator 将用户设为社区版主
+func (s *ModerationStore) AddModerator(communityID, userID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.moderators[[2]uint64{communityID, userID}] = true
+}
+
+// Ban 禁止用户在社区发言
+func (s *ModerationStore) Ban(communityID, userID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.banned[[2]uint64{communityID, userID}] = true
+}
+
+// Logs 已写入的审计日志
+func (s *ModerationStore) Logs() []*model.ModerationLog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*model.ModerationLog(nil), s.logs...)
+}
+
+// CreateReport 保存举报
+func (s *ModerationStore) CreateReport(ctx context.Context, report *model.Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := *report
+	r.CreateTime = time.Now()
+	r.UpdateTime = r.CreateTime
+	s.reports[report.ReportID] = &r
+	return nil
+}
+
+// GetReportByID 根据举报id查询举报
+func (s *ModerationStore) GetReportByID(ctx context.Context, reportID uint64) (*model.Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.reports[reportID]
+	if !ok {
+		return nil, mysql.ErrReportNotFound
+	}
+	rr := *r
+	return &rr, nil
+}
+
+// ListReports 按社区和状态分页查询举报队列，按时间先后排列
+func (s *ModerationStore) ListReports(ctx context.Context, communityID uint64, status string, page, size int64) ([]*model.Report, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var list []*model.Report
+	for _, r := range s.reports {
+		if r.CommunityID == communityID && (status == "" || r.Status == status) {
+			rr := *r
+			list = append(list, &rr)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreateTime.Before(list[j].CreateTime) })
+	return paginate(list, page, size), int64(len(list)), nil
+}
+
+// IsModerator 判断用户是否为社区版主
+func (s *ModerationStore) IsModerator(ctx context.Context, communityID, userID uint64) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.moderators[[2]uint64{communityID, userID}], nil
+}
+
+// IsBanned 判断用户是否被禁止在社区发言
+func (s *ModerationStore) IsBanned(ctx context.Context, communityID, userID uint64) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.banned[[2]uint64{communityID, userID}], nil
+}
+
+// Apply 执行版主操作、关闭关联举报并写入审计日志，举报已处理时不做任何修改
+func (s *ModerationStore) Apply(ctx context.Context, p *model.ParamModerate, log *model.ModerationLog, reportStatus string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ReportID != 0 {
+		r, ok := s.reports[p.ReportID]
+		if !ok || r.Status != model.ReportStatusOpen {
+			return mysql.ErrReportClosed
+		}
+		r.Status, r.HandlerID, r.UpdateTime = reportStatus, p.ModeratorID, time.Now()
+	}
+	switch p.Action {
+	case model.ModerationHidePost:
+		s.posts.SetPostStatus(p.PostID, model.PostStatusHidden)
+	case model.ModerationBanUser:
+		s.banned[[2]uint64{p.CommunityID, p.UserID}] = true
+	}
+	l := *log
+	s.logs = append(s.logs, &l)
+	return nil
+}
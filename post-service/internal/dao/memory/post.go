@@ -0,0 +1,262 @@
+// Package memory 存储接口的内存实现，用于单元测试及本地联调，不持久化
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bluebell_microservices/post-service/internal/model"
+)
+
+// PostStore 帖子及社区的内存存储，返回的错误与 mysql.PostDAO 一致
+type PostStore struct {
+	mu          sync.RWMutex
+	posts       map[uint64]*model.Post
+	communities map[uint64]*model.CommunityDetailRes
+	comments    map[uint64]int64 // 对应 post 表的 comment_count 列
+}
+
+// NewPostStore 创建空的帖子存储
+func NewPostStore() *PostStore {
+	return &PostStore{
+		posts:       make(map[uint64]*model.Post),
+		communities: make(map[uint64]*model.CommunityDetailRes),
+		comments:    make(map[uint64]int64),
+	}
+}
+
+// AddCommunity 预置社区
+func (s *PostStore) AddCommunity(c *model.CommunityDetailRes) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cc := *c
+	s.communities[c.CommunityID] = &cc
+}
+
+// SetPostStatus 修改帖子状态，模拟版主隐藏帖子
+func (s *PostStore) SetPostStatus(postID uint64, status int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.posts[postID]; ok {
+		p.Status = status
+	}
+}
+
+// CreatePost 保存帖子，新帖子是草稿或正常状态，隐藏帖子需调用 SetPostStatus
+func (s *PostStore) CreatePost(ctx context.Context, post *model.Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := *post
+	if p.Status != model.PostStatusDraft {
+		p.Status = model.PostStatusNormal
+	}
+	s.posts[post.PostID] = &p
+	return nil
+}
+
+// UpdateDraft 修改草稿的标题、内容、社区及定时发布时间；帖子已不是草稿时不修改并返回 false
+func (s *PostStore) UpdateDraft(ctx context.Context, post *model.Post) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.posts[post.PostID]
+	if !ok || p.Status != model.PostStatusDraft {
+		return false, nil
+	}
+	p.Title = post.Title
+	p.Content = post.Content
+	p.CommunityID = post.CommunityID
+	p.PublishTime = post.PublishTime
+	return true, nil
+}
+
+// PublishDraft 将草稿改为正常状态，帖子已不是草稿时返回 false
+func (s *PostStore) PublishDraft(ctx context.Context, postID int64, publishTime time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.posts[uint64(postID)]
+	if !ok || p.Status != model.PostStatusDraft {
+		return false, nil
+	}
+	p.Status = model.PostStatusNormal
+	p.PublishTime = nil
+	p.CreateTime = publishTime
+	return true, nil
+}
+
+// GetDueDrafts 定时发布时间不晚于 now 的草稿，按定时发布时间先后最多返回 limit 条
+func (s *PostStore) GetDueDrafts(ctx context.Context, now time.Time, limit int) ([]*model.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var due []*model.Post
+	for _, p := range s.posts {
+		if p.Status == model.PostStatusDraft && p.PublishTime != nil && !p.PublishTime.After(now) {
+			pp := *p
+			due = append(due, &pp)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].PublishTime.Before(*due[j].PublishTime) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// GetPostByID 根据帖子id查询帖子信息
+func (s *PostStore) GetPostByID(id int64) (*model.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.posts[uint64(id)]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	pp := *p
+	return &pp, nil
+}
+
+// GetPostListByIDs 按 ids 的顺序返回帖子，不存在的 id 被忽略
+func (s *PostStore) GetPostListByIDs(ids []string) ([]*model.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]*model.Post, 0, len(ids))
+	for _, id := range ids {
+		n, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if p, ok := s.posts[n]; ok {
+			pp := *p
+			list = append(list, &pp)
+		}
+	}
+	return list, nil
+}
+
+// SearchPosts 标题或内容匹配 search 的帖子，按创建时间倒序分页，同时返回匹配的总数
+func (s *PostStore) SearchPosts(search string, page, size int64, communityID int64) ([]string, int64, error) {
+	matched := s.search(search, communityID)
+	sort.Slice(matched, func(i, j int) bool { return matched[i].CreateTime.After(matched[j].CreateTime) })
+	ids := make([]string, 0, size)
+	for _, p := range paginate(matched, page, size) {
+		ids = append(ids, strconv.FormatUint(p.PostID, 10))
+	}
+	return ids, int64(len(matched)), nil
+}
+
+// GetCommunityByID 根据ID查询分类社区详情
+func (s *PostStore) GetCommunityByID(id uint64) (*model.CommunityDetailRes, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.communities[id]
+	if !ok {
+		return nil, errors.New("无效的ID")
+	}
+	cc := *c
+	return &cc, nil
+}
+
+// CommunityExists 判断社区是否存在
+func (s *PostStore) CommunityExists(id uint64) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.communities[id]
+	return ok, nil
+}
+
+// IncrCommentCount 按评论事件增减帖子评论数，不会减为负数
+func (s *PostStore) IncrCommentCount(ctx context.Context, postID, delta int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.posts[uint64(postID)]; ok {
+		s.comments[uint64(postID)] = max(s.comments[uint64(postID)]+delta, 0)
+	}
+	return nil
+}
+
+// SetCommentCount 校准帖子评论数
+func (s *PostStore) SetCommentCount(ctx context.Context, postID, count int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.posts[uint64(postID)]; ok {
+		s.comments[uint64(postID)] = count
+	}
+	return nil
+}
+
+// CommentCount post 表中记录的评论数，供测试断言
+func (s *PostStore) CommentCount(postID uint64) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.comments[postID]
+}
+
+// search 与 MySQL 的 LIKE 查询一致，search 为空时匹配全部，communityID 为 0 时不限社区
+func (s *PostStore) search(search string, communityID int64) []*model.Post {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var list []*model.Post
+	for _, p := range s.posts {
+		if p.Status != model.PostStatusNormal {
+			continue
+		}
+		if communityID > 0 && p.CommunityID != uint64(communityID) {
+			continue
+		}
+		if search != "" && !strings.Contains(p.Title, search) && !strings.Contains(p.Content, search) {
+			continue
+		}
+		list = append(list, p)
+	}
+	return list
+}
+
+// paginate 返回第 page 页（从 1 开始）的元素
+func paginate[T any](list []T, page, size int64) []T {
+	start := (page - 1) * size
+	if start < 0 || start >= int64(len(list)) {
+		return nil
+	}
+	end := start + size
+	if end > int64(len(list)) {
+		end = int64(len(list))
+	}
+	return list[start:end]
+}
+
+// UserStore 作者信息的内存存储
+type UserStore struct {
+	mu    sync.RWMutex
+	users map[uint64]*model.User
+}
+
+// NewUserStore 创建空的作者存储
+func NewUserStore() *UserStore {
+	return &UserStore{users: make(map[uint64]*model.User)}
+}
+
+// AddUser 预置作者
+func (s *UserStore) AddUser(u *model.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uu := *u
+	s.users[u.UserID] = &uu
+}
+
+// GetUsersByIDs 批量查询作者信息，不存在的用户被忽略
+func (s *UserStore) GetUsersByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := make(map[uint64]*model.User, len(ids))
+	for _, id := range ids {
+		if u, ok := s.users[id]; ok {
+			uu := *u
+			users[id] = &uu
+		}
+	}
+	return users, nil
+}
@@ -0,0 +1,164 @@
+package mysql
+
+import (
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/post-service/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// 举报相关错误
+var (
+	ErrReportNotFound = errcode.NotFound("REPORT_NOT_FOUND", "举报不存在")
+	ErrReportClosed   = errcode.FailedPrecondition("REPORT_CLOSED", "举报已处理")
+)
+
+// ModerationDAO 举报与版主操作数据访问对象
+type ModerationDAO struct {
+	db *sqlx.DB
+}
+
+// NewModerationDAO 创建新的 ModerationDAO 实例
+func NewModerationDAO() *ModerationDAO {
+	return &ModerationDAO{
+		db: db,
+	}
+}
+
+// CreateReport 保存举报
+func (d *ModerationDAO) CreateReport(ctx context.Context, report *model.Report) error {
+	sqlStr := `
+		INSERT INTO report (report_id, target_type, target_id, post_id, community_id, target_author_id, reporter_id, reason, status)
+		VALUES (:report_id, :target_type, :target_id, :post_id, :community_id, :target_author_id, :reporter_id, :reason, :status)
+	`
+	_, err := d.db.NamedExecContext(ctx, sqlStr, report)
+	return err
+}
+
+// GetReportByID 根据举报id查询举报
+func (d *ModerationDAO) GetReportByID(ctx context.Context, reportID uint64) (*model.Report, error) {
+	report := new(model.Report)
+	sqlStr := `select report_id, target_type, target_id, post_id, community_id, target_author_id, reporter_id, reason, status, handler_id, create_time, update_time
+	from report
+	where report_id = ?`
+	err := d.db.GetContext(ctx, report, sqlStr, reportID)
+	if err == sql.ErrNoRows {
+		return nil, ErrReportNotFound
+	}
+	return report, err
+}
+
+// ListReports 按社区和状态分页查询举报队列，按时间先后排列
+func (d *ModerationDAO) ListReports(ctx context.Context, communityID uint64, status string, page, size int64) ([]*model.Report, int64, error) {
+	where := `where community_id = ?`
+	args := []interface{}{communityID}
+	if status != "" {
+		where += ` and status = ?`
+		args = append(args, status)
+	}
+
+	var total int64
+	if err := d.db.GetContext(ctx, &total, `select count(*) from report `+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	sqlStr := `select report_id, target_type, target_id, post_id, community_id, target_author_id, reporter_id, reason, status, handler_id, create_time, update_time
+	from report ` + where + `
+	order by create_time asc
+	limit ? offset ?`
+	reports := make([]*model.Report, 0, size)
+	err := d.db.SelectContext(ctx, &reports, sqlStr, append(args, size, (page-1)*size)...)
+	return reports, total, err
+}
+
+// ResolveReport 更新举报状态，仅处理仍为 open 的举报，举报已被处理时返回 ErrReportClosed
+func (d *ModerationDAO) ResolveReport(tx *sqlx.Tx, reportID, handlerID uint64, status string) error {
+	sqlStr := `update report set status = ?, handler_id = ? where report_id = ? and status = ?`
+	result, err := tx.Exec(sqlStr, status, handlerID, reportID, model.ReportStatusOpen)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrReportClosed
+	}
+	return nil
+}
+
+// IsModerator 判断用户是否为社区版主
+func (d *ModerationDAO) IsModerator(ctx context.Context, communityID, userID uint64) (bool, error) {
+	var count int
+	sqlStr := `select count(*) from community_moderator where community_id = ? and user_id = ?`
+	err := d.db.GetContext(ctx, &count, sqlStr, communityID, userID)
+	return count > 0, err
+}
+
+// IsBanned 判断用户是否被禁止在社区发言
+func (d *ModerationDAO) IsBanned(ctx context.Context, communityID, userID uint64) (bool, error) {
+	var count int
+	sqlStr := `select count(*) from community_ban where community_id = ? and user_id = ?`
+	err := d.db.GetContext(ctx, &count, sqlStr, communityID, userID)
+	return count > 0, err
+}
+
+// BanUser 禁止用户在社区发言，重复封禁时更新原因
+func (d *ModerationDAO) BanUser(tx *sqlx.Tx, communityID, userID, moderatorID uint64, reason string) error {
+	sqlStr := `
+		INSERT INTO community_ban (community_id, user_id, moderator_id, reason)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE moderator_id = VALUES(moderator_id), reason = VALUES(reason)
+	`
+	_, err := tx.Exec(sqlStr, communityID, userID, moderatorID, reason)
+	return err
+}
+
+// UpdatePostStatus 修改帖子状态
+func (d *ModerationDAO) UpdatePostStatus(tx *sqlx.Tx, postID uint64, status int32) error {
+	_, err := tx.Exec(`update post set status = ? where post_id = ?`, status, postID)
+	return err
+}
+
+// CreateModerationLog 写入版主操作审计日志
+func (d *ModerationDAO) CreateModerationLog(tx *sqlx.Tx, log *model.ModerationLog) error {
+	sqlStr := `
+		INSERT INTO moderation_log (moderator_id, community_id, action, target_type, target_id, report_id, reason)
+		VALUES (:moderator_id, :community_id, :action, :target_type, :target_id, :report_id, :reason)
+	`
+	_, err := tx.NamedExec(sqlStr, log)
+	return err
+}
+
+// Apply 在同一事务中执行版主操作（隐藏帖子、封禁用户）、关闭关联举报并写入审计日志
+func (d *ModerationDAO) Apply(ctx context.Context, p *model.ParamModerate, log *model.ModerationLog, reportStatus string) error {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	switch p.Action {
+	case model.ModerationHidePost:
+		err = d.UpdatePostStatus(tx, p.PostID, model.PostStatusHidden)
+	case model.ModerationBanUser:
+		err = d.BanUser(tx, p.CommunityID, p.UserID, p.ModeratorID, p.Reason)
+	}
+	if err != nil {
+		return fmt.Errorf("apply %s: %w", p.Action, err)
+	}
+
+	if p.ReportID != 0 {
+		if err := d.ResolveReport(tx, p.ReportID, p.ModeratorID, reportStatus); err != nil {
+			return fmt.Errorf("resolve report %d: %w", p.ReportID, err)
+		}
+	}
+	if err := d.CreateModerationLog(tx, log); err != nil {
+		return fmt.Errorf("write moderation log: %w", err)
+	}
+	return tx.Commit()
+}
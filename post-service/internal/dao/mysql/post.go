@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"errors"
 	"strings"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
@@ -27,8 +28,8 @@ func NewPostDAO() *PostDAO {
 
 func (p *PostDAO) CreatePost(ctx context.Context, post *model.Post) error {
 	sqlStr := `
-		INSERT INTO post (post_id, title, content, author_id, community_id, create_time, update_time)
-		VALUES (:post_id, :title, :content, :author_id, :community_id, :create_time, :update_time)
+		INSERT INTO post (post_id, title, content, author_id, community_id, status, publish_time, create_time, update_time)
+		VALUES (:post_id, :title, :content, :author_id, :community_id, :status, :publish_time, :create_time, :update_time)
 	`
 	_, err := db.NamedExec(sqlStr, post)
 	return err
@@ -51,55 +52,9 @@ func (p *PostDAO) GetCommunityNameByID(param any) (any, error) {
 	return community, nil
 }
 
-func GetPostTotalCount(search string, communityID int64) (int64, error) {
-	var sqlStr string
-	var args []interface{}
-
-	if search != "" {
-		if communityID > 0 {
-			sqlStr = `
-				SELECT COUNT(*) FROM post 
-				WHERE (title LIKE ? OR content LIKE ?)
-				AND community_id = ?
-			`
-			searchPattern := "%" + search + "%"
-			args = []interface{}{searchPattern, searchPattern, communityID}
-		} else {
-			sqlStr = `
-				SELECT COUNT(*) FROM post 
-				WHERE title LIKE ? OR content LIKE ?
-			`
-			searchPattern := "%" + search + "%"
-			args = []interface{}{searchPattern, searchPattern}
-		}
-	} else {
-		if communityID > 0 {
-			sqlStr = `
-				SELECT COUNT(*) FROM post 
-				WHERE community_id = ?
-			`
-			args = []interface{}{communityID}
-		} else {
-			sqlStr = `
-				SELECT COUNT(*) FROM post
-			`
-			args = nil
-		}
-	}
-
-	var count int64
-	var err error
-	if args != nil {
-		err = db.QueryRow(sqlStr, args...).Scan(&count)
-	} else {
-		err = db.QueryRow(sqlStr).Scan(&count)
-	}
-	return count, err
-}
-
 // GetPostListByIDs 根据给定的id列表查询帖子数据
 func GetPostListByIDs(ids []string) (postList []*model.Post, err error) {
-	sqlStr := `select post_id, title, content, author_id, community_id, create_time, update_time
+	sqlStr := `select post_id, title, content, author_id, community_id, status, create_time, update_time
 	from post
 	where post_id in (?)
 	order by FIND_IN_SET(post_id, ?)`
@@ -114,11 +69,14 @@ func GetPostListByIDs(ids []string) (postList []*model.Post, err error) {
 	return
 }
 
-// GetUserByID 根据ID查询作者信息
-func GetUserByID(id uint64) (user *model.User, err error) {
-	user = new(model.User)
-	sqlStr := `select user_id, username from user where user_id = ?`
-	err = db.Get(user, sqlStr, id)
+// GetPostVoteCounts 查询已落库的帖子赞成票、反对票数
+func GetPostVoteCounts(postID int64) (up, down int64, err error) {
+	sqlStr := `select
+	coalesce(sum(vote_type = 1), 0),
+	coalesce(sum(vote_type = -1), 0)
+	from vote
+	where post_id = ?`
+	err = db.QueryRow(sqlStr, postID).Scan(&up, &down)
 	return
 }
 
@@ -144,65 +102,58 @@ func GetCommunityByID(id uint64) (*model.CommunityDetailRes, error) {
 	}, err
 }
 
-// GetCommunityPostTotalCount 根据社区id查询数据库帖子总数
-func GetCommunityPostTotalCount(communityID uint64) (count int64, err error) {
-	sqlStr := `select count(post_id) from post where community_id = ?`
-	err = db.Get(&count, sqlStr, communityID)
-	if err != nil {
-		zap.L().Error("db.Get(&count, sqlStr) failed", zap.Error(err))
-		return 0, err
+// CommunityExists 判断社区是否存在
+func CommunityExists(id uint64) (bool, error) {
+	var count int
+	sqlStr := `select count(community_id) from community where community_id = ?`
+	if err := db.Get(&count, sqlStr, id); err != nil {
+		return false, err
 	}
-	return
+	return count > 0, nil
 }
 
 // GetPostByID 根据帖子id查询帖子信息
 func (p *PostDAO) GetPostByID(id int64) (*model.Post, error) {
 	post := new(model.Post)
-	sqlStr := `select post_id, title, content, author_id, community_id, create_time, update_time
+	sqlStr := `select post_id, title, content, author_id, community_id, status, publish_time, create_time, update_time
 	from post
 	where post_id = ?`
 	err := db.Get(post, sqlStr, id)
 	return post, err
 }
 
-// GetPostIDsBySearch 根据搜索关键词获取匹配的帖子ID列表
-func GetPostIDsBySearch(search string, page, size int64, communityID int64) ([]string, error) {
-	var sqlStr string
-	var args []interface{}
-
+// SearchPosts 标题或内容包含 search 的帖子，按创建时间倒序分页，同时返回匹配的总数；communityID 为 0 时不限社区
+func SearchPosts(search string, page, size int64, communityID int64) ([]string, int64, error) {
+	where := `WHERE status = ? AND (title LIKE ? OR content LIKE ?)` // 隐藏的帖子及草稿不出现在搜索结果中
+	searchPattern := "%" + search + "%"
+	args := []interface{}{model.PostStatusNormal, searchPattern, searchPattern}
 	if communityID > 0 {
-		sqlStr = `
-			SELECT post_id FROM post 
-			WHERE (title LIKE ? OR content LIKE ?)
-			AND community_id = ?
-			ORDER BY create_time DESC
-			LIMIT ? OFFSET ?
-		`
-		searchPattern := "%" + search + "%"
-		offset := (page - 1) * size
-		args = []interface{}{searchPattern, searchPattern, communityID, size, offset}
-	} else {
-		sqlStr = `
-			SELECT post_id FROM post 
-			WHERE title LIKE ? OR content LIKE ?
-			ORDER BY create_time DESC
-			LIMIT ? OFFSET ?
-		`
-		searchPattern := "%" + search + "%"
-		offset := (page - 1) * size
-		args = []interface{}{searchPattern, searchPattern, size, offset}
+		where += ` AND community_id = ?`
+		args = append(args, communityID)
 	}
 
-	var ids []string
-	err := db.Select(&ids, sqlStr, args...)
-	if err != nil {
-		logger.Error("Failed to get post IDs by search",
+	// 总数与分页使用同一个过滤条件
+	var total int64
+	if err := db.Get(&total, `SELECT COUNT(*) FROM post `+where, args...); err != nil {
+		logger.Error("Failed to count posts by search",
 			zap.String("search", search),
-			zap.Int64("page", page),
-			zap.Int64("size", size),
 			zap.Int64("community_id", communityID),
 			zap.Error(err))
-		return nil, err
+		return nil, 0, err
+	}
+
+	ids := make([]string, 0, size)
+	if total > 0 {
+		sqlStr := `SELECT post_id FROM post ` + where + ` ORDER BY create_time DESC LIMIT ? OFFSET ?`
+		if err := db.Select(&ids, sqlStr, append(args, size, (page-1)*size)...); err != nil {
+			logger.Error("Failed to get post IDs by search",
+				zap.String("search", search),
+				zap.Int64("page", page),
+				zap.Int64("size", size),
+				zap.Int64("community_id", communityID),
+				zap.Error(err))
+			return nil, 0, err
+		}
 	}
 
 	logger.Info("Got post IDs by search",
@@ -210,8 +161,86 @@ func GetPostIDsBySearch(search string, page, size int64, communityID int64) ([]s
 		zap.Int64("page", page),
 		zap.Int64("size", size),
 		zap.Int64("community_id", communityID),
-		zap.Int("count", len(ids)),
+		zap.Int64("total", total),
 		zap.Strings("ids", ids))
 
-	return ids, nil
+	return ids, total, nil
+}
+
+// UpdateDraft 修改草稿的标题、内容、社区及定时发布时间；帖子已不是草稿时不修改并返回 false
+func (p *PostDAO) UpdateDraft(ctx context.Context, post *model.Post) (bool, error) {
+	sqlStr := `update post set title = ?, content = ?, community_id = ?, publish_time = ?
+	where post_id = ? and status = ?`
+	res, err := db.ExecContext(ctx, sqlStr,
+		post.Title, post.Content, post.CommunityID, post.PublishTime, post.PostID, model.PostStatusDraft)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return n > 0, err
+	}
+	// 内容未变化时 MySQL 同样返回 0 行，需确认帖子是否仍是草稿；帖子只会从草稿变为已发布，不会反向
+	var status int32
+	err = db.GetContext(ctx, &status, `select status from post where post_id = ?`, post.PostID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return status == model.PostStatusDraft, err
+}
+
+// PublishDraft 将草稿改为正常状态，发帖时间记为 publishTime；帖子已不是草稿（已被其他实例或作者发布）时返回 false
+func (p *PostDAO) PublishDraft(ctx context.Context, postID int64, publishTime time.Time) (bool, error) {
+	sqlStr := `update post set status = ?, publish_time = null, create_time = ?
+	where post_id = ? and status = ?`
+	res, err := db.ExecContext(ctx, sqlStr, model.PostStatusNormal, publishTime, postID, model.PostStatusDraft)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetDueDrafts 定时发布时间不晚于 now 的草稿，按定时发布时间先后最多返回 limit 条
+func (p *PostDAO) GetDueDrafts(ctx context.Context, now time.Time, limit int) ([]*model.Post, error) {
+	sqlStr := `select post_id, title, content, author_id, community_id, status, publish_time, create_time, update_time
+	from post
+	where status = ? and publish_time <= ?
+	order by publish_time
+	limit ?`
+	var posts []*model.Post
+	err := db.SelectContext(ctx, &posts, sqlStr, model.PostStatusDraft, now, limit)
+	return posts, err
+}
+
+// IncrCommentCount 按评论事件增减帖子评论数，不会减为负数
+func (p *PostDAO) IncrCommentCount(ctx context.Context, postID, delta int64) error {
+	sqlStr := `update post set comment_count = greatest(cast(comment_count as signed) + ?, 0) where post_id = ?`
+	_, err := db.ExecContext(ctx, sqlStr, delta, postID)
+	return err
+}
+
+// SetCommentCount 校准帖子评论数
+func (p *PostDAO) SetCommentCount(ctx context.Context, postID, count int64) error {
+	sqlStr := `update post set comment_count = ? where post_id = ?`
+	_, err := db.ExecContext(ctx, sqlStr, count, postID)
+	return err
+}
+
+// 以下方法供 logic 层通过接口调用，与同名包级函数一致
+
+func (p *PostDAO) GetPostListByIDs(ids []string) ([]*model.Post, error) {
+	return GetPostListByIDs(ids)
+}
+
+func (p *PostDAO) SearchPosts(search string, page, size int64, communityID int64) ([]string, int64, error) {
+	return SearchPosts(search, page, size, communityID)
+}
+
+func (p *PostDAO) GetCommunityByID(id uint64) (*model.CommunityDetailRes, error) {
+	return GetCommunityByID(id)
+}
+
+func (p *PostDAO) CommunityExists(id uint64) (bool, error) {
+	return CommunityExists(id)
 }
@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"bluebell_microservices/post-service/internal/model"
+
+	"github.com/go-redis/redis"
+)
+
+// commentEventTTL 评论事件去重记录的保留时间，需大于 Kafka 消息可能重复投递的时间窗口
+const commentEventTTL = 7 * 24 * time.Hour
+
+// commentsScript 修改帖子 hash 中的评论数（ARGV[2] 为 incr 时增减，为 set 时覆盖），结果小于 0 时置为 0，并同步评论数 ZSet：
+// 评论数 ZSet 只包含仍在列表中（时间 ZSet 中有该帖子）且评论数大于 0 的帖子；帖子 hash 不存在时不创建
+// KEYS[4] 为帖子所属社区的评论数 ZSet，可省略，与全站的评论数 ZSet 同样维护
+var commentsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+local n
+if ARGV[2] == "incr" then
+	n = redis.call("HINCRBY", KEYS[1], "comments", ARGV[1])
+else
+	n = tonumber(ARGV[1])
+end
+if n < 0 then
+	n = 0
+end
+redis.call("HSET", KEYS[1], "comments", n)
+local listed = n > 0 and redis.call("ZSCORE", KEYS[2], ARGV[3])
+for i = 3, #KEYS do
+	if listed then
+		redis.call("ZADD", KEYS[i], n, ARGV[3])
+	else
+		redis.call("ZREM", KEYS[i], ARGV[3])
+	end
+end
+return n
+`)
+
+// MarkCommentEvent 记录评论事件已处理，事件此前已处理过时返回 false
+func MarkCommentEvent(commentID int64, typ string) (bool, error) {
+	key := KeyCommentEventPrefix + typ + ":" + strconv.FormatInt(commentID, 10)
+	return client.SetNX(key, 1, commentEventTTL).Result()
+}
+
+// UnmarkCommentEvent 删除评论事件的处理记录，事件处理失败需要重新投递时使用
+func UnmarkCommentEvent(commentID int64, typ string) error {
+	key := KeyCommentEventPrefix + typ + ":" + strconv.FormatInt(commentID, 10)
+	return client.Del(key).Err()
+}
+
+// IncrPostComments 增减帖子评论数，不会减为负数
+func IncrPostComments(postID, delta int64) error {
+	return runCommentsScript(postID, delta, "incr")
+}
+
+// SetPostComments 校准帖子评论数；帖子 hash 不存在时不创建
+func SetPostComments(postID, count int64) error {
+	return runCommentsScript(postID, count, "set")
+}
+
+func runCommentsScript(postID, n int64, mode string) error {
+	id := strconv.FormatInt(postID, 10)
+	keys := []string{KeyPostInfoHashPrefix + id, KeyPostTimeZSet, KeyPostCommentsZSet}
+	cid, err := postCommunity(id)
+	if err != nil {
+		return err
+	}
+	if cid != "" {
+		keys = append(keys, communityKey(model.OrderComments, cid))
+	}
+	return commentsScript.Run(client, keys, n, mode, id).Err()
+}
+
+// GetPostCommentData 按 ids 的顺序返回各帖子的评论数，没有记录的为 0
+func GetPostCommentData(ids []string) ([]int64, error) {
+	pipeline := client.Pipeline()
+	cmds := make([]*redis.StringCmd, 0, len(ids))
+	for _, id := range ids {
+		cmds = append(cmds, pipeline.HGet(KeyPostInfoHashPrefix+id, "comments"))
+	}
+	if _, err := pipeline.Exec(); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	data := make([]int64, 0, len(ids))
+	for _, cmd := range cmds {
+		n, err := cmd.Int64()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		data = append(data, n)
+	}
+	return data, nil
+}
@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"strconv"
+	"strings"
+
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/post-service/internal/model"
+
+	"github.com/go-redis/redis"
+	"go.uber.org/zap"
+)
+
+// communityKey 社区内某种排序方式的 ZSet，未知的排序方式按时间
+func communityKey(order, communityID string) string {
+	if _, ok := orderKeys[order]; !ok {
+		order = model.OrderTime
+	}
+	return KeyCommunityFeedPrefix + order + ":" + communityID
+}
+
+// postCommunity 帖子所属的社区 ID，帖子 hash 中没有记录时返回空字符串
+func postCommunity(postID string) (string, error) {
+	cid, err := client.HGet(KeyPostInfoHashPrefix+postID, "community_id").Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return cid, err
+}
+
+// postCommunities 按 ids 的顺序返回各帖子所属的社区 ID，没有记录的为空字符串
+func postCommunities(ids []string) ([]string, error) {
+	pipeline := client.Pipeline()
+	cmds := make([]*redis.StringCmd, 0, len(ids))
+	for _, id := range ids {
+		cmds = append(cmds, pipeline.HGet(KeyPostInfoHashPrefix+id, "community_id"))
+	}
+	if _, err := pipeline.Exec(); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	communities := make([]string, 0, len(ids))
+	for _, cmd := range cmds {
+		cid, err := cmd.Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		communities = append(communities, cid)
+	}
+	return communities, nil
+}
+
+// MigrateCommunityFeeds 由社区集合与全站排序 ZSet 生成各社区的排序 ZSet，并在帖子 hash 中补充 community_id；
+// 通过 SETNX 标记只执行一次，失败时删除标记以便下次启动重试
+func MigrateCommunityFeeds() error {
+	first, err := client.SetNX(KeyCommunityFeedMigrated, 1, 0).Result()
+	if err != nil || !first {
+		return err
+	}
+	if err := migrateCommunityFeeds(); err != nil {
+		client.Del(KeyCommunityFeedMigrated)
+		return err
+	}
+	return nil
+}
+
+func migrateCommunityFeeds() error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(cursor, KeyCommunityPostSetPrefix+"*", 100).Result()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			cid := strings.TrimPrefix(key, KeyCommunityPostSetPrefix)
+			if _, err := strconv.ParseUint(cid, 10, 64); err != nil {
+				continue // 不是社区集合
+			}
+			if err := migrateCommunity(cid); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// migrateCommunity 生成一个社区的排序 ZSet
+func migrateCommunity(cid string) error {
+	setKey := KeyCommunityPostSetPrefix + cid
+	members, err := client.SMembers(setKey).Result()
+	if err != nil {
+		return err
+	}
+
+	pipeline := client.Pipeline()
+	exists := make([]*redis.IntCmd, 0, len(members))
+	for _, id := range members {
+		exists = append(exists, pipeline.Exists(KeyPostInfoHashPrefix+id))
+	}
+	if _, err := pipeline.Exec(); err != nil {
+		return err
+	}
+
+	pipeline = client.TxPipeline()
+	for _, order := range model.Orders {
+		pipeline.ZInterStore(communityKey(order, cid), redis.ZStore{
+			Weights: []float64{0, 1}, // 社区 set 的成员分数视为 1，只取排序 ZSet 的分数
+		}, setKey, orderKey(order))
+	}
+	for i, id := range members {
+		if exists[i].Val() > 0 { // 不为已过期的帖子创建 hash
+			pipeline.HSet(KeyPostInfoHashPrefix+id, "community_id", cid)
+		}
+	}
+	if _, err := pipeline.Exec(); err != nil {
+		return err
+	}
+	logger.Info("Migrated community feeds", zap.String("community_id", cid), zap.Int("posts", len(members)))
+	return nil
+}
@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"strconv"
+	"testing"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/post-service/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestRedis 连接到 miniredis，包级 client 被替换，使用它的测试不能并行
+func newTestRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Init(&config.Redis{Host: mr.Host(), Port: port}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+	return mr
+}
+
+// score 成员在 ZSet 中的分数，不存在时 ok 为 false
+func score(t *testing.T, key, member string) (float64, bool) {
+	t.Helper()
+	s, err := client.ZScore(key, member).Result()
+	if err != nil {
+		return 0, false
+	}
+	return s, true
+}
+
+func TestCommunityFeeds(t *testing.T) {
+	newTestRedis(t)
+	if err := CreatePost(10, 100, "hello", "world", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// 发帖同时写入全站及社区的时间、分数排序
+	for _, order := range []string{model.OrderTime, model.OrderScore} {
+		global, ok := score(t, orderKey(order), "10")
+		if !ok {
+			t.Fatalf("post missing from %s", orderKey(order))
+		}
+		if community, ok := score(t, communityKey(order, "1"), "10"); !ok || community != global {
+			t.Errorf("%s score in community = %v (present %v), want %v", order, community, ok, global)
+		}
+		if _, ok := score(t, communityKey(order, "2"), "10"); ok {
+			t.Errorf("post listed in community 2 by %s", order)
+		}
+	}
+
+	// 投票同时更新全站及社区的分数
+	before, _ := score(t, KeyPostScoreZSet, "10")
+	if err := CreatePostVote(10, 200, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{KeyPostScoreZSet, communityKey(model.OrderScore, "1")} {
+		if got, _ := score(t, key, "10"); got != before+VoteScore {
+			t.Errorf("%s score after vote = %v, want %v", key, got, before+VoteScore)
+		}
+	}
+
+	// 隐藏、删除后从全站及社区的排序中移除
+	if err := RemovePostFromFeeds(10, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, order := range model.Orders {
+		for _, key := range []string{orderKey(order), communityKey(order, "1")} {
+			if _, ok := score(t, key, "10"); ok {
+				t.Errorf("removed post still in %s", key)
+			}
+		}
+	}
+	if n, _ := CountPosts(model.OrderTime, 1); n != 0 {
+		t.Errorf("community post count after removal = %d, want 0", n)
+	}
+}
+
+func TestMigrateCommunityFeeds(t *testing.T) {
+	mr := newTestRedis(t)
+	// 旧版数据：只有社区集合及全站排序，帖子 2 的 hash 已过期
+	for id, s := range map[string]float64{"1": 100, "2": 200, "3": 300} {
+		mr.ZAdd(KeyPostTimeZSet, s, id)
+		mr.ZAdd(KeyPostScoreZSet, s+VoteScore, id)
+	}
+	mr.SAdd(KeyCommunityPostSetPrefix+"1", "1", "2")
+	mr.SAdd(KeyCommunityPostSetPrefix+"2", "3")
+	mr.HSet(KeyPostInfoHashPrefix+"1", "title", "hello")
+	mr.HSet(KeyPostInfoHashPrefix+"3", "title", "rust")
+
+	if err := MigrateCommunityFeeds(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		cid, id string
+		want    float64
+	}{
+		{"1", "1", 100}, {"1", "2", 200}, {"2", "3", 300},
+	} {
+		if got, ok := score(t, communityKey(model.OrderTime, tt.cid), tt.id); !ok || got != tt.want {
+			t.Errorf("community %s time score of %s = %v (present %v), want %v", tt.cid, tt.id, got, ok, tt.want)
+		}
+		if got, _ := score(t, communityKey(model.OrderScore, tt.cid), tt.id); got != tt.want+VoteScore {
+			t.Errorf("community %s score of %s = %v, want %v", tt.cid, tt.id, got, tt.want+VoteScore)
+		}
+	}
+	if cid := mr.HGet(KeyPostInfoHashPrefix+"1", "community_id"); cid != "1" {
+		t.Errorf("post 1 community_id = %q, want 1", cid)
+	}
+	if mr.Exists(KeyPostInfoHashPrefix + "2") {
+		t.Error("hash created for expired post 2")
+	}
+
+	// 只执行一次：之后加入社区集合的帖子不会再被迁移
+	mr.ZAdd(KeyPostTimeZSet, 400, "4")
+	mr.SAdd(KeyCommunityPostSetPrefix+"1", "4")
+	if err := MigrateCommunityFeeds(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := score(t, communityKey(model.OrderTime, "1"), "4"); ok {
+		t.Error("migration ran twice")
+	}
+}
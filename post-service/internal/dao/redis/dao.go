@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"time"
+
+	"bluebell_microservices/post-service/internal/model"
+)
+
+// VoteDAO 帖子投票数据访问对象，基于包级 client
+type VoteDAO struct{}
+
+// NewVoteDAO 创建新的 VoteDAO 实例
+func NewVoteDAO() *VoteDAO {
+	return &VoteDAO{}
+}
+
+func (d *VoteDAO) CreatePostVote(postID, userID, direction int64) error {
+	return CreatePostVote(postID, userID, direction)
+}
+
+func (d *VoteDAO) GetPostVoteNum(id int64) (int64, error) {
+	return GetPostVoteNum(id)
+}
+
+func (d *VoteDAO) GetPostVoteData(ids []string) ([]int64, error) {
+	return GetPostVoteData(ids)
+}
+
+func (d *VoteDAO) SetVoteStatus(postID, userID int64, status int64, expiration time.Duration) error {
+	return SetVoteStatus(postID, userID, status, expiration)
+}
+
+func (d *VoteDAO) AcquireLock(postID, userID int64, expiration time.Duration) (bool, error) {
+	return AcquireLock(postID, userID, expiration)
+}
+
+func (d *VoteDAO) ReleaseLock(postID, userID int64) error {
+	return ReleaseLock(postID, userID)
+}
+
+// RankingDAO 帖子排序索引（时间、分数、评论数、活跃度 ZSet 及社区集合）数据访问对象，基于包级 client
+type RankingDAO struct{}
+
+// NewRankingDAO 创建新的 RankingDAO 实例
+func NewRankingDAO() *RankingDAO {
+	return &RankingDAO{}
+}
+
+func (d *RankingDAO) CreatePost(postID, authorID uint64, title, content string, communityID uint64) error {
+	return CreatePost(postID, authorID, title, content, communityID)
+}
+
+func (d *RankingDAO) GetPostIDsInOrder(req *model.ParamPostList) ([]string, error) {
+	return GetPostIDsInOrder(req)
+}
+
+func (d *RankingDAO) GetCommunityPostIDsInOrder(p *model.ParamPostList) ([]string, error) {
+	return GetCommunityPostIDsInOrder(p)
+}
+
+func (d *RankingDAO) CountPosts(order string, communityID int64) (int64, error) {
+	return CountPosts(order, communityID)
+}
+
+func (d *RankingDAO) RemovePostFromFeeds(postID, communityID uint64) error {
+	return RemovePostFromFeeds(postID, communityID)
+}
+
+func (d *RankingDAO) AddTrendingActivity(postID int64, weight float64) error {
+	return AddTrendingActivity(postID, weight)
+}
+
+// CommentCountDAO 帖子评论数及评论事件去重数据访问对象，基于包级 client
+type CommentCountDAO struct{}
+
+// NewCommentCountDAO 创建新的 CommentCountDAO 实例
+func NewCommentCountDAO() *CommentCountDAO {
+	return &CommentCountDAO{}
+}
+
+func (d *CommentCountDAO) MarkCommentEvent(commentID int64, typ string) (bool, error) {
+	return MarkCommentEvent(commentID, typ)
+}
+
+func (d *CommentCountDAO) UnmarkCommentEvent(commentID int64, typ string) error {
+	return UnmarkCommentEvent(commentID, typ)
+}
+
+func (d *CommentCountDAO) IncrPostComments(postID, delta int64) error {
+	return IncrPostComments(postID, delta)
+}
+
+func (d *CommentCountDAO) SetPostComments(postID, count int64) error {
+	return SetPostComments(postID, count)
+}
+
+func (d *CommentCountDAO) GetPostCommentData(ids []string) ([]int64, error) {
+	return GetPostCommentData(ids)
+}
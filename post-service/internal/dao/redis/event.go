@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"bluebell_microservices/common/pkg/event"
+	"bluebell_microservices/common/pkg/logger"
+	"encoding/json"
+	"sync"
+
+	"github.com/go-redis/redis"
+	"go.uber.org/zap"
+)
+
+// PublishPostEvent 发布帖子实时事件
+func PublishPostEvent(ev *event.PostEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return client.Publish(event.PostChannel(ev.PostID), data).Err()
+}
+
+// SubscribePostEvents 订阅指定帖子的实时事件，调用方负责关闭返回的订阅
+func SubscribePostEvents(postID int64) (event.Subscription, error) {
+	pubsub := client.Subscribe(event.PostChannel(postID))
+	// 等待订阅确认，确保之后发布的事件不会丢失
+	if _, err := pubsub.Receive(); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+	s := &subscription{
+		pubsub: pubsub,
+		events: make(chan *event.PostEvent),
+		done:   make(chan struct{}),
+	}
+	go s.forward()
+	return s, nil
+}
+
+// subscription 将 Redis 消息解码为帖子事件
+type subscription struct {
+	pubsub *redis.PubSub
+	events chan *event.PostEvent
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (s *subscription) Events() <-chan *event.PostEvent {
+	return s.events
+}
+
+func (s *subscription) Close() error {
+	s.once.Do(func() { close(s.done) })
+	return s.pubsub.Close()
+}
+
+func (s *subscription) forward() {
+	defer close(s.events)
+	for msg := range s.pubsub.Channel() {
+		var ev event.PostEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+			logger.Warn("Failed to unmarshal post event", zap.String("payload", msg.Payload), zap.Error(err))
+			continue
+		}
+		select {
+		case s.events <- &ev:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// EventDAO 帖子实时事件的发布与订阅，基于包级 client
+type EventDAO struct{}
+
+// NewEventDAO 创建新的 EventDAO 实例
+func NewEventDAO() *EventDAO {
+	return &EventDAO{}
+}
+
+// PublishPostEvent 发布帖子实时事件
+func (d *EventDAO) PublishPostEvent(ev *event.PostEvent) error {
+	return PublishPostEvent(ev)
+}
+
+// SubscribePostEvents 订阅指定帖子的实时事件
+func (d *EventDAO) SubscribePostEvents(postID int64) (event.Subscription, error) {
+	return SubscribePostEvents(postID)
+}
@@ -3,10 +3,17 @@ package redis
 // redis key 注意使用命名空间的方式，方便查询和拆分
 const (
 	KeyPostInfoHashPrefix     = "bluebell-plus:post:"
-	KeyPostTimeZSet           = "bluebell-plus:post:time"  // zset;帖子及发帖时间定义
-	KeyPostScoreZSet          = "bluebell-plus:post:score" // zset;帖子及投票分数定义
+	KeyPostTimeZSet           = "bluebell-plus:post:time"         // zset;帖子及发帖时间定义
+	KeyPostScoreZSet          = "bluebell-plus:post:score"        // zset;帖子及投票分数定义
+	KeyPostCommentsZSet       = "bluebell-plus:post:comments"     // zset;帖子及评论数
+	KeyPostTrendingZSet       = "bluebell-plus:post:trending"     // zset;帖子及最近时间窗口内的活跃度，没有活跃度的帖子不在其中
+	KeyTrendingBucketPrefix   = "bluebell-plus:trending:bucket:"  // zset;一个时间桶内各帖子的活跃度，参数是桶的起始时间戳
+	KeyTrendingExpiredPrefix  = "bluebell-plus:trending:expired:" // string;已从活跃度中扣除的时间桶，参数是桶的起始时间戳
 	KeyPostVotedUpSetPrefix   = "bluebell-plus:post:voted:down:"
 	KeyPostVotedDownSetPrefix = "bluebell-plus:post:voted:up:"
-	KeyPostVotedZSetPrefix    = "bluebell-plus:post:voted:" // zSet;记录用户及投票类型;参数是post_id
-	KeyCommunityPostSetPrefix = "bluebell-plus:community:"  // set保存每个分区下帖子的id
+	KeyPostVotedZSetPrefix    = "bluebell-plus:post:voted:"              // zSet;记录用户及投票类型;参数是post_id
+	KeyCommunityPostSetPrefix = "bluebell-plus:community:"               // set保存每个分区下帖子的id
+	KeyCommunityFeedPrefix    = "bluebell-plus:feed:community:"          // zset;社区内帖子的排序，与全站排序 ZSet 同时写入，参数是排序方式:community_id
+	KeyCommunityFeedMigrated  = "bluebell-plus:migration:community-feed" // string;已由社区集合生成社区排序 ZSet
+	KeyCommentEventPrefix     = "bluebell-plus:comment:event:"           // string;已处理的评论事件，参数是事件类型:comment_id
 )
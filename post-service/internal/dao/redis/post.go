@@ -1,10 +1,9 @@
 package redis
 
 import (
+	"bluebell_microservices/common/pkg/errcode"
 	"bluebell_microservices/common/pkg/logger"
-	"bluebell_microservices/post-service/internal/dao/mysql"
 	"bluebell_microservices/post-service/internal/model"
-	"errors"
 	"fmt"
 	"math"
 	"strconv"
@@ -23,17 +22,30 @@ const (
 
 // 投票相关错误
 var (
-	ErrorVoteTimeExpire = errors.New("投票时间已过")
-	ErrVoteRepeated     = errors.New("不允许重复投票")
+	ErrorVoteTimeExpire = errcode.FailedPrecondition("VOTE_EXPIRED", "投票时间已过")
+	ErrVoteRepeated     = errcode.AlreadyExists("VOTE_REPEATED", "不允许重复投票")
 )
 
+// orderKeys 各排序方式对应的 ZSet
+var orderKeys = map[string]string{
+	model.OrderTime:     KeyPostTimeZSet,
+	model.OrderScore:    KeyPostScoreZSet,
+	model.OrderComments: KeyPostCommentsZSet,
+	model.OrderTrending: KeyPostTrendingZSet,
+}
+
+// orderKey 排序方式对应的 ZSet，排序方式由 logic 层校验，未知的排序方式按时间
+func orderKey(order string) string {
+	if key, ok := orderKeys[order]; ok {
+		return key
+	}
+	return KeyPostTimeZSet
+}
+
 func GetPostIDsInOrder(req *model.ParamPostList) ([]string, error) {
 	// 从redis获取id
 	// 1.根据用户请求中携带的order参数确定要查询的redis key
-	key := KeyPostTimeZSet             // 默认是时间
-	if req.Order == KeyPostScoreZSet { // 按照分数请求
-		key = KeyPostScoreZSet
-	}
+	key := orderKey(req.Order)
 
 	logger.Info("Getting post IDs from Redis",
 		zap.String("key", key),
@@ -42,21 +54,19 @@ func GetPostIDsInOrder(req *model.ParamPostList) ([]string, error) {
 		zap.String("search", req.Search),
 		zap.Int64("community_id", req.CommunityID))
 
-	// 2.如果有搜索关键词，直接从MySQL获取匹配的帖子ID
-	if req.Search != "" {
-		// 从MySQL中获取匹配的帖子ID
-		matchedIDs, err := mysql.GetPostIDsBySearch(req.Search, req.Page, req.Size, req.CommunityID)
-		if err != nil {
-			logger.Error("Failed to get matched post IDs from MySQL", zap.Error(err))
-			return nil, err
-		}
-		return matchedIDs, nil
-	}
-
-	// 3.如果没有搜索关键词，直接从Redis获取分页后的ID
+	// 2.从Redis获取分页后的ID，搜索由 logic 层交给 MySQL
 	return getIDsFormKey(key, req.Page, req.Size)
 }
 
+// CountPosts 排序 ZSet 中的帖子数，与 GetPostIDsInOrder、GetCommunityPostIDsInOrder 读取同一个 ZSet；communityID 为 0 时统计全站
+func CountPosts(order string, communityID int64) (int64, error) {
+	key := orderKey(order)
+	if communityID > 0 {
+		key = communityKey(order, strconv.FormatInt(communityID, 10))
+	}
+	return client.ZCard(key).Result()
+}
+
 // getIDsFormKey 按照分数从大到小的顺序查询指定数量的元素
 func getIDsFormKey(key string, page, size int64) ([]string, error) {
 	start := (page - 1) * size
@@ -110,50 +120,19 @@ func GetPostVoteData(ids []string) (data []int64, err error) {
 	return data, nil
 }
 
+// GetCommunityPostIDsInOrder 社区内的帖子 ID，读取写入时维护的社区排序 ZSet
 func GetCommunityPostIDsInOrder(p *model.ParamPostList) ([]string, error) {
 	// 1.根据用户请求中携带的order参数确定要查询的redis key
-	orderkey := KeyPostTimeZSet      // 默认是时间
-	if p.Order == model.OrderScore { // 按照分数请求
-		orderkey = KeyPostScoreZSet
-	}
-
-	// 社区的key
-	cKey := KeyCommunityPostSetPrefix + strconv.Itoa(int(p.CommunityID))
-
-	// 利用缓存key减少zinterstore执行的次数 缓存key
-	key := orderkey + strconv.Itoa(int(p.CommunityID))
-	if client.Exists(key).Val() < 1 {
-		// 不存在，需要计算
-		pipeline := client.Pipeline()
-		pipeline.ZInterStore(key, redis.ZStore{
-			Aggregate: "MAX", // 将两个zset函数聚合的时候 求最大值
-		}, cKey, orderkey) // zinterstore 计算
-		pipeline.Expire(key, 60*time.Second) // 设置超时时间
-		_, err := pipeline.Exec()
-		if err != nil {
-			return nil, err
-		}
-	}
+	key := communityKey(p.Order, strconv.FormatInt(p.CommunityID, 10))
 
-	// 2.如果有搜索关键词，直接从MySQL获取匹配的帖子ID
-	if p.Search != "" {
-		// 从MySQL中获取匹配的帖子ID
-		matchedIDs, err := mysql.GetPostIDsBySearch(p.Search, p.Page, p.Size, p.CommunityID)
-		if err != nil {
-			logger.Error("Failed to get matched post IDs from MySQL", zap.Error(err))
-			return nil, err
-		}
-		return matchedIDs, nil
-	}
-
-	// 3.如果没有搜索关键词，直接从Redis获取分页后的ID
+	// 2.从Redis获取分页后的ID
 	return getIDsFormKey(key, p.Page, p.Size)
 }
 
 func CreatePost(postID, authorID uint64, title, content string, communityID uint64) error {
 	now := float64(time.Now().Unix())
 	votedKey := KeyPostVotedZSetPrefix + strconv.Itoa(int(postID))
-	communityKey := KeyCommunityPostSetPrefix + strconv.Itoa(int(communityID))
+	cid := strconv.FormatUint(communityID, 10)
 
 	logger.Info("Creating post in Redis",
 		zap.Uint64("postID", postID),
@@ -161,13 +140,14 @@ func CreatePost(postID, authorID uint64, title, content string, communityID uint
 		zap.Uint64("communityID", communityID))
 
 	postInfo := map[string]interface{}{
-		"title":    title,
-		"content":  content,
-		"post_id":  postID,
-		"user_id":  authorID, // 修改键名，确保与其他地方一致
-		"time":     now,
-		"votes":    1,
-		"comments": 0,
+		"title":        title,
+		"content":      content,
+		"post_id":      postID,
+		"user_id":      authorID, // 修改键名，确保与其他地方一致
+		"time":         now,
+		"votes":        1,
+		"comments":     0,
+		"community_id": communityID, // 投票、评论时据此更新社区排序
 	}
 
 	// 事务操作
@@ -180,18 +160,22 @@ func CreatePost(postID, authorID uint64, title, content string, communityID uint
 	pipeline.Expire(votedKey, time.Second*OneMonthInSeconds*6) // 过期时间：6个月
 	// 文章 hash
 	pipeline.HMSet(KeyPostInfoHashPrefix+strconv.Itoa(int(postID)), postInfo)
-	// 添加到分数 ZSet
-	pipeline.ZAdd(KeyPostScoreZSet, redis.Z{
-		Score:  now + VoteScore,
-		Member: postID,
-	})
-	// 添加到时间 ZSet
-	pipeline.ZAdd(KeyPostTimeZSet, redis.Z{
-		Score:  now,
-		Member: postID,
-	})
+	// 添加到全站及社区的分数 ZSet
+	for _, key := range []string{KeyPostScoreZSet, communityKey(model.OrderScore, cid)} {
+		pipeline.ZAdd(key, redis.Z{
+			Score:  now + VoteScore,
+			Member: postID,
+		})
+	}
+	// 添加到全站及社区的时间 ZSet
+	for _, key := range []string{KeyPostTimeZSet, communityKey(model.OrderTime, cid)} {
+		pipeline.ZAdd(key, redis.Z{
+			Score:  now,
+			Member: postID,
+		})
+	}
 	// 添加到对应版块 把帖子添加到社区 set
-	pipeline.SAdd(communityKey, postID)
+	pipeline.SAdd(KeyCommunityPostSetPrefix+cid, postID)
 	_, err := pipeline.Exec()
 	if err != nil {
 		logger.Error("Failed to execute Redis pipeline",
@@ -221,6 +205,18 @@ func GetPostVoteNum(id int64) (int64, error) {
 	return voteNum, nil
 }
 
+// GetPostVoteCounts 获取缓存中帖子的赞成票、反对票数
+func GetPostVoteCounts(postID int64) (up, down int64, err error) {
+	key := KeyPostVotedZSetPrefix + strconv.FormatInt(postID, 10)
+	pipeline := client.Pipeline()
+	upCmd := pipeline.ZCount(key, "1", "1")
+	downCmd := pipeline.ZCount(key, "-1", "-1")
+	if _, err = pipeline.Exec(); err != nil {
+		return 0, 0, err
+	}
+	return upCmd.Val(), downCmd.Val(), nil
+}
+
 // SetVoteStatus 设置投票状态
 func SetVoteStatus(postID, userID int64, status int64, expiration time.Duration) error {
 	redisClient := Client()
@@ -261,14 +257,20 @@ func CreatePostVote(postID, userID, direction int64) error {
 	}
 	diffAbs := math.Abs(ov - v) // 计算两次投票的差值
 
+	// 帖子所属社区，用于同时更新社区的分数排序
+	cid, err := postCommunity(postIDStr)
+	if err != nil {
+		return err
+	}
+
 	// 4、使用事务进行投票更新
 	pipeline := client.TxPipeline()
 
-	// 4.1、更新帖子分数
+	// 4.1、更新帖子在全站及社区的分数
 	incrementScore := VoteScore * diffAbs * op // 计算分数变化
-	_, err := pipeline.ZIncrBy(KeyPostScoreZSet, incrementScore, postIDStr).Result()
-	if err != nil {
-		return err
+	pipeline.ZIncrBy(KeyPostScoreZSet, incrementScore, postIDStr)
+	if cid != "" {
+		pipeline.ZIncrBy(communityKey(model.OrderScore, cid), incrementScore, postIDStr)
 	}
 
 	// 4.2、记录用户为该帖子的投票数据
@@ -322,3 +324,17 @@ func ReleaseLock(postID, userID int64) error {
 	// 删除锁
 	return redisClient.Del(lockKey).Err()
 }
+
+// RemovePostFromFeeds 将帖子从全站、社区的各排序 ZSet 及社区集合中移除（帖子被隐藏时使用）
+func RemovePostFromFeeds(postID, communityID uint64) error {
+	postIDStr := strconv.FormatUint(postID, 10)
+	cid := strconv.FormatUint(communityID, 10)
+	pipeline := client.TxPipeline()
+	for _, order := range model.Orders {
+		pipeline.ZRem(orderKey(order), postIDStr)
+		pipeline.ZRem(communityKey(order, cid), postIDStr)
+	}
+	pipeline.SRem(KeyCommunityPostSetPrefix+cid, postIDStr)
+	_, err := pipeline.Exec()
+	return err
+}
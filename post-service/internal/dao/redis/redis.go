@@ -1,6 +1,8 @@
 package redis
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"bluebell_microservices/common/config"
@@ -45,3 +47,11 @@ func Close() {
 func Client() *redis.Client {
 	return client
 }
+
+// Ping 检查 Redis 连接是否可用，用于健康检查
+func Ping(ctx context.Context) error {
+	if client == nil {
+		return errors.New("redis not initialized")
+	}
+	return client.WithContext(ctx).Ping().Err()
+}
@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"bluebell_microservices/post-service/internal/model"
+
+	"github.com/go-redis/redis"
+)
+
+// 活跃度按小时分桶，趋势排序统计最近 24 个桶：每次活跃同时计入当前桶及活跃度 ZSet，桶滑出窗口后从活跃度 ZSet 中扣除
+const (
+	TrendingBucket        = time.Hour
+	TrendingWindowBuckets = 24
+	// trendingBucketTTL 桶在滑出窗口后再保留一个窗口的时间，扣除任务停止一段时间后仍能补扣
+	trendingBucketTTL = 2 * TrendingWindowBuckets * TrendingBucket
+)
+
+// addActivityScript 帖子仍在列表中时，活跃度计入活跃度 ZSet 及当前桶；KEYS[4] 为帖子所属社区的活跃度 ZSet，可省略
+var addActivityScript = redis.NewScript(`
+if not redis.call("ZSCORE", KEYS[1], ARGV[2]) then
+	return 0
+end
+redis.call("ZINCRBY", KEYS[2], ARGV[1], ARGV[2])
+redis.call("ZINCRBY", KEYS[3], ARGV[1], ARGV[2])
+redis.call("EXPIRE", KEYS[3], ARGV[3])
+if KEYS[4] then
+	redis.call("ZINCRBY", KEYS[4], ARGV[1], ARGV[2])
+end
+return 1
+`)
+
+// trendingBucketStart now 所在桶的起始时间戳
+func trendingBucketStart(now time.Time) int64 {
+	return now.Truncate(TrendingBucket).Unix()
+}
+
+// AddTrendingActivity 记录帖子的一次活跃（投票、评论），weight 为计入活跃度的分值；帖子已不在列表中时忽略
+func AddTrendingActivity(postID int64, weight float64) error {
+	bucketKey := KeyTrendingBucketPrefix + strconv.FormatInt(trendingBucketStart(time.Now()), 10)
+	keys := []string{KeyPostTimeZSet, KeyPostTrendingZSet, bucketKey}
+	cid, err := postCommunity(strconv.FormatInt(postID, 10))
+	if err != nil {
+		return err
+	}
+	if cid != "" {
+		keys = append(keys, communityKey(model.OrderTrending, cid))
+	}
+	return addActivityScript.Run(client, keys, weight, postID, int64(trendingBucketTTL/time.Second)).Err()
+}
+
+// ExpireTrendingBuckets 从活跃度 ZSet 中扣除已滑出窗口的桶，返回本次扣除的桶数
+// 每个桶通过 SETNX 标记只扣除一次，多个实例同时执行也不会重复扣除
+func ExpireTrendingBuckets(now time.Time) (int, error) {
+	oldest := trendingBucketStart(now) - int64(TrendingWindowBuckets*TrendingBucket/time.Second)
+	var n int
+	for i := 0; i < TrendingWindowBuckets; i++ {
+		bucket := strconv.FormatInt(oldest-int64(i)*int64(TrendingBucket/time.Second), 10)
+		bucketKey := KeyTrendingBucketPrefix + bucket
+		exists, err := client.Exists(bucketKey).Result()
+		if err != nil {
+			return n, err
+		}
+		if exists == 0 {
+			continue
+		}
+		first, err := client.SetNX(KeyTrendingExpiredPrefix+bucket, 1, trendingBucketTTL).Result()
+		if err != nil {
+			return n, err
+		}
+		if !first {
+			continue
+		}
+
+		if err := expireTrendingBucket(bucketKey); err != nil {
+			client.Del(KeyTrendingExpiredPrefix + bucket) // 下次重试扣除
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// expireTrendingBucket 从全站及各社区的活跃度 ZSet 中扣除一个桶并删除该桶
+func expireTrendingBucket(bucketKey string) error {
+	activity, err := client.ZRangeWithScores(bucketKey, 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(activity))
+	for _, z := range activity {
+		ids = append(ids, z.Member.(string))
+	}
+	communities, err := postCommunities(ids)
+	if err != nil {
+		return err
+	}
+
+	pipeline := client.TxPipeline()
+	pipeline.ZUnionStore(KeyPostTrendingZSet, redis.ZStore{Weights: []float64{1, -1}}, KeyPostTrendingZSet, bucketKey)
+	pipeline.ZRemRangeByScore(KeyPostTrendingZSet, "-inf", "0") // 窗口内没有活跃的帖子移出趋势排序
+	touched := make(map[string]bool)
+	for i, z := range activity {
+		if communities[i] == "" {
+			continue
+		}
+		key := communityKey(model.OrderTrending, communities[i])
+		pipeline.ZIncrBy(key, -z.Score, z.Member.(string))
+		touched[key] = true
+	}
+	for key := range touched {
+		pipeline.ZRemRangeByScore(key, "-inf", "0")
+	}
+	pipeline.Del(bucketKey)
+	_, err = pipeline.Exec()
+	return err
+}
@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/kafka"
+	"bluebell_microservices/common/pkg/logger"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// commentGroup 评论事件的消费者组，与投票消费者互不影响
+const commentGroup = "post-service-comment-counter"
+
+// CommentHandler 处理评论事件及校准评论数，由 logic.CommentCountLogic 实现
+type CommentHandler interface {
+	ApplyCommentEvent(ctx context.Context, msg kafka.CommentMessage) error
+	RepairCommentCounts(ctx context.Context) (int, error)
+}
+
+// CommentConsumer 消费 comment-service 的评论事件维护帖子评论数，并定期校准
+type CommentConsumer struct {
+	consumer       *kafka.Consumer
+	handler        CommentHandler
+	repairInterval time.Duration
+	cancel         context.CancelFunc
+}
+
+var commentConsumer *CommentConsumer
+
+// InitCommentConsumer 初始化评论事件消费者
+func InitCommentConsumer(config *config.Kafka, handler CommentHandler) error {
+	// 默认值见 config.setDefaults，comment_repair_interval 配置为 0 时使用 10 分钟
+	if config.CommentRepairInterval <= 0 {
+		config.CommentRepairInterval = 10 * time.Minute
+	}
+
+	kafkaConsumer, err := kafka.NewConsumer(kafka.KafkaConfig{
+		Brokers: config.Brokers,
+		Topic:   config.CommentTopic,
+		Group:   commentGroup,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create comment event consumer: %v", err)
+	}
+
+	commentConsumer = &CommentConsumer{
+		consumer:       kafkaConsumer,
+		handler:        handler,
+		repairInterval: config.CommentRepairInterval,
+	}
+	return nil
+}
+
+// GetCommentConsumer 获取评论事件消费者实例
+func GetCommentConsumer() *CommentConsumer {
+	return commentConsumer
+}
+
+// Start 启动消费者及定期校准
+func (c *CommentConsumer) Start(ctx context.Context) error {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	if err := c.consumer.ConsumeCommentMessages(c.handler.ApplyCommentEvent); err != nil {
+		logger.Error("Failed to start comment event consumer", zap.Error(err))
+		return err
+	}
+
+	go c.periodicallyRepair(ctx)
+	return nil
+}
+
+// periodicallyRepair 定期按 comment-service 的评论数校准，修正事件丢失造成的偏差
+func (c *CommentConsumer) periodicallyRepair(ctx context.Context) {
+	ticker := time.NewTicker(c.repairInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			fixed, err := c.handler.RepairCommentCounts(ctx)
+			commentRepairSeconds.Observe(time.Since(start).Seconds())
+			commentRepairFixed.Add(float64(fixed))
+			if err != nil {
+				logger.Error("Failed to repair post comment counts", zap.Int("fixed", fixed), zap.Error(err))
+				continue
+			}
+			logger.Info("Repaired post comment counts", zap.Int("fixed", fixed), zap.Duration("took", time.Since(start)))
+		}
+	}
+}
+
+// Close 停止校准并关闭消费者
+func (c *CommentConsumer) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return c.consumer.Close()
+}
@@ -4,33 +4,39 @@ import (
 	"bluebell_microservices/common/config"
 	"bluebell_microservices/common/pkg/kafka"
 	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/tracing"
 	"bluebell_microservices/post-service/internal/dao/mysql"
 	"bluebell_microservices/post-service/internal/dao/redis"
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 // Consumer Kafka消费者
 type Consumer struct {
-	consumer        *kafka.Consumer
-	batchSize       int
-	voteCounts      map[int64]int64
-	voteCountsMutex sync.Mutex
-	voteCountsFile  string
-	ctx             context.Context
-	cancel          context.CancelFunc
-	batch           []struct {
-		PostID    int64
-		UserID    int64
-		Direction int64
-	}
+	consumer      *kafka.Consumer
+	batchSize     int
+	driftInterval time.Duration
+	touched       map[int64]struct{} // 上次核对后有投票落库的帖子
+	touchedMutex  sync.Mutex
+	ctx           context.Context
+	cancel        context.CancelFunc
+	batchMutex    sync.Mutex // 保护 batch，消费回调与定时刷新并发访问
+	batch         []voteItem
+}
+
+// voteItem 待批量落库的投票，span 为消息所在的 trace，批量处理时作为链接
+type voteItem struct {
+	PostID    int64
+	UserID    int64
+	Direction int64
+	span      trace.SpanContext
 }
 
 var (
@@ -42,27 +48,16 @@ var (
 func Init(config *config.Kafka) error {
 	var initErr error
 	once.Do(func() {
-		// 设置默认值
-		if config.BatchSize == 0 {
-			config.BatchSize = 100
-		}
-		if config.VoteCountsFile == "" {
-			config.VoteCountsFile = filepath.Join("data", "vote_count.json")
-		}
-		if len(config.Brokers) == 0 {
-			config.Brokers = []string{"kafka:9092"}
-		}
-		if config.Topic == "" {
-			config.Topic = "post-votes"
+		// 默认值见 config.setDefaults，drift_interval 配置为 0 时使用 1 分钟
+		if config.DriftInterval <= 0 {
+			config.DriftInterval = time.Minute
 		}
 
-		// 确保目录存在
-		os.MkdirAll(filepath.Dir(config.VoteCountsFile), 0755)
-
 		// 初始化Kafka消费者
 		kafkaConfig := kafka.KafkaConfig{
-			Brokers: config.Brokers,
-			Topic:   config.Topic,
+			Brokers:  config.Brokers,
+			Topic:    config.Topic,
+			DLQTopic: config.DLQTopic,
 		}
 
 		kafkaConsumer, err := kafka.NewConsumer(kafkaConfig)
@@ -72,10 +67,10 @@ func Init(config *config.Kafka) error {
 		}
 
 		consumer = &Consumer{
-			consumer:       kafkaConsumer,
-			batchSize:      config.BatchSize,
-			voteCounts:     make(map[int64]int64),
-			voteCountsFile: config.VoteCountsFile,
+			consumer:      kafkaConsumer,
+			batchSize:     config.BatchSize,
+			driftInterval: config.DriftInterval,
+			touched:       make(map[int64]struct{}),
 		}
 	})
 
@@ -87,16 +82,60 @@ func GetConsumer() *Consumer {
 	return consumer
 }
 
-// processBatch 批量处理消息
-func (c *Consumer) processBatch(batch []struct {
-	PostID    int64
-	UserID    int64
-	Direction int64
-}) {
+// processBatch 批量处理消息，落库失败的投票转入死信队列
+func (c *Consumer) processBatch(batch []voteItem) {
 	if len(batch) == 0 {
 		return
 	}
 
+	// 一个批次对应多条消息的 trace，以链接的方式关联
+	links := make([]trace.Link, 0, len(batch))
+	for _, vote := range batch {
+		if vote.span.IsValid() {
+			links = append(links, trace.Link{SpanContext: vote.span})
+		}
+	}
+	ctx, span := tracing.Tracer().Start(context.Background(), "vote batch persist",
+		trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("batch_size", len(batch))))
+	defer span.End()
+
+	start := time.Now()
+	err := c.persistBatch(ctx, batch)
+	batchFlushSeconds.Observe(time.Since(start).Seconds())
+	batchSize.Observe(float64(len(batch)))
+
+	if err != nil {
+		batchResults.WithLabelValues(batchResultFailure).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Ctx(ctx).Error("Failed to persist vote batch", zap.Int("batch_size", len(batch)), zap.Error(err))
+		for _, vote := range batch {
+			c.consumer.DeadLetter(trace.ContextWithRemoteSpanContext(ctx, vote.span), kafka.VoteMessage{
+				PostID:    vote.PostID,
+				UserID:    vote.UserID,
+				Direction: vote.Direction,
+				Timestamp: time.Now().Unix(),
+			}, kafka.DLQReasonPersist)
+		}
+		return
+	}
+
+	batchResults.WithLabelValues(batchResultSuccess).Inc()
+	votesPersisted.Add(float64(len(batch)))
+
+	// 记录本批次涉及的帖子，供核对 Redis 与 MySQL 投票数
+	c.touchedMutex.Lock()
+	for _, vote := range batch {
+		c.touched[vote.PostID] = struct{}{}
+	}
+	c.touchedMutex.Unlock()
+
+	logger.Ctx(ctx).Info("Successfully processed batch", zap.Int("batch_size", len(batch)))
+}
+
+// persistBatch 在一个事务中写入一批投票，并将 Redis 中的投票状态置为已入库
+func (c *Consumer) persistBatch(ctx context.Context, batch []voteItem) error {
 	// 获取数据库连接
 	db := mysql.DB()
 	redisClient := redis.Client()
@@ -104,8 +143,7 @@ func (c *Consumer) processBatch(batch []struct {
 	// 开始数据库事务
 	tx, err := db.Begin()
 	if err != nil {
-		logger.Error("Failed to begin transaction", zap.Error(err))
-		return
+		return fmt.Errorf("begin transaction: %w", err)
 	}
 
 	// 准备SQL语句
@@ -115,62 +153,41 @@ func (c *Consumer) processBatch(batch []struct {
 		ON DUPLICATE KEY UPDATE vote_type = VALUES(vote_type)
 	`)
 	if err != nil {
-		logger.Error("Failed to prepare statement", zap.Error(err))
 		tx.Rollback()
-		return
+		return fmt.Errorf("prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
 	// 执行批量插入
 	for _, vote := range batch {
-		_, err := stmt.Exec(vote.PostID, vote.UserID, vote.Direction)
-		if err != nil {
-			logger.Error("Failed to insert vote",
-				zap.Int64("post_id", vote.PostID),
-				zap.Int64("user_id", vote.UserID),
-				zap.Int64("direction", vote.Direction),
-				zap.Error(err))
+		if _, err := stmt.Exec(vote.PostID, vote.UserID, vote.Direction); err != nil {
 			tx.Rollback()
-			return
-		}
-
-		// 更新Redis中的投票状态为已入库(1)
-		voteStatusKey := fmt.Sprintf("bluebell-plus:vote:status:%d:%d", vote.PostID, vote.UserID)
-		err = redisClient.Set(voteStatusKey, 1, 24*time.Hour).Err()
-		if err != nil {
-			logger.Error("Failed to update vote status",
-				zap.Int64("post_id", vote.PostID),
-				zap.Int64("user_id", vote.UserID),
-				zap.Error(err))
-			// 继续处理，不中断批量处理
+			return fmt.Errorf("insert vote post_id=%d user_id=%d: %w", vote.PostID, vote.UserID, err)
 		}
-
-		// 更新vote_counts
-		c.updateVoteCount(vote.PostID, vote.Direction)
 	}
 
 	// 提交事务
 	if err := tx.Commit(); err != nil {
-		logger.Error("Failed to commit transaction", zap.Error(err))
 		tx.Rollback()
-		return
+		return fmt.Errorf("commit transaction: %w", err)
 	}
 
-	logger.Info("Successfully processed batch", zap.Int("batch_size", len(batch)))
-}
-
-// updateVoteCount 更新投票计数
-func (c *Consumer) updateVoteCount(postID int64, direction int64) {
-	c.voteCountsMutex.Lock()
-	defer c.voteCountsMutex.Unlock()
-
-	// 更新投票计数
-	c.voteCounts[postID] += direction
+	// 更新Redis中的投票状态为已入库(1)，失败不影响已落库的数据
+	for _, vote := range batch {
+		voteStatusKey := fmt.Sprintf("bluebell-plus:vote:status:%d:%d", vote.PostID, vote.UserID)
+		if err := redisClient.Set(voteStatusKey, 1, 24*time.Hour).Err(); err != nil {
+			logger.Ctx(ctx).Error("Failed to update vote status",
+				zap.Int64("post_id", vote.PostID),
+				zap.Int64("user_id", vote.UserID),
+				zap.Error(err))
+		}
+	}
+	return nil
 }
 
-// periodicallySaveVoteCounts 定期保存vote_counts.json
-func (c *Consumer) periodicallySaveVoteCounts(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Minute)
+// periodicallyCheckDrift 定期核对 Redis 与 MySQL 中的投票数
+func (c *Consumer) periodicallyCheckDrift(ctx context.Context) {
+	ticker := time.NewTicker(c.driftInterval)
 	defer ticker.Stop()
 
 	for {
@@ -179,42 +196,87 @@ func (c *Consumer) periodicallySaveVoteCounts(ctx context.Context) {
 			// 上下文取消，退出
 			return
 		case <-ticker.C:
-			// 定时器触发，保存vote_counts.json
-			c.saveVoteCounts()
+			c.checkDrift()
 		}
 	}
 }
 
-// saveVoteCounts 保存vote_counts.json
-func (c *Consumer) saveVoteCounts() {
-	c.voteCountsMutex.Lock()
-	defer c.voteCountsMutex.Unlock()
+// checkDrift 核对上次核对后有投票落库的帖子，结果写入 drift 指标
+// 核对时仍在批次中未落库的投票也会计入差值，持续不为 0 才说明数据不一致
+func (c *Consumer) checkDrift() {
+	c.touchedMutex.Lock()
+	posts := c.touched
+	c.touched = make(map[int64]struct{})
+	c.touchedMutex.Unlock()
+
+	var mismatched, drift int64
+	for postID := range posts {
+		redisUp, redisDown, err := redis.GetPostVoteCounts(postID)
+		if err != nil {
+			logger.Error("Failed to get vote counts from Redis", zap.Int64("post_id", postID), zap.Error(err))
+			continue
+		}
+		mysqlUp, mysqlDown, err := mysql.GetPostVoteCounts(postID)
+		if err != nil {
+			logger.Error("Failed to get vote counts from MySQL", zap.Int64("post_id", postID), zap.Error(err))
+			continue
+		}
 
-	// 将vote_counts转换为JSON
-	jsonData, err := json.MarshalIndent(c.voteCounts, "", "  ")
-	if err != nil {
-		logger.Error("Failed to marshal vote counts", zap.Error(err))
-		return
+		diff := abs(redisUp-mysqlUp) + abs(redisDown-mysqlDown)
+		if diff != 0 {
+			mismatched++
+			drift += diff
+			logger.Warn("Vote counts drift between Redis and MySQL",
+				zap.Int64("post_id", postID),
+				zap.Int64("redis_up", redisUp),
+				zap.Int64("redis_down", redisDown),
+				zap.Int64("mysql_up", mysqlUp),
+				zap.Int64("mysql_down", mysqlDown))
+		}
 	}
 
-	// 写入文件
-	err = os.WriteFile(c.voteCountsFile, jsonData, 0644)
-	if err != nil {
-		logger.Error("Failed to write vote counts to file", zap.Error(err))
-		return
-	}
+	driftChecked.Set(float64(len(posts)))
+	driftPosts.Set(float64(mismatched))
+	driftVotes.Set(float64(drift))
+}
 
-	logger.Info("Successfully saved vote counts",
-		zap.String("file", c.voteCountsFile),
-		zap.Int("count", len(c.voteCounts)))
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
 }
 
-// Close 关闭消费者
+// Close 关闭消费者：停止拉取消息后将未满的批次落库，最后关闭死信生产者
 func (c *Consumer) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	var err error
 	if c.consumer != nil {
-		return c.consumer.Close()
+		err = c.consumer.Stop()
+	}
+
+	c.flushBatch()
+
+	if c.consumer != nil {
+		if closeErr := c.consumer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// flushBatch 处理并清空当前批次
+func (c *Consumer) flushBatch() {
+	c.batchMutex.Lock()
+	defer c.batchMutex.Unlock()
+
+	if len(c.batch) > 0 {
+		c.processBatch(c.batch)
+		c.batch = c.batch[:0] // 清空批量处理队列
 	}
-	return nil
 }
 
 // processMessages 处理消息
@@ -231,27 +293,26 @@ func (c *Consumer) processMessages(ctx context.Context) {
 			return
 		case <-ticker.C:
 			// 定时器触发，处理批量处理队列
-			if len(c.batch) > 0 {
-				c.processBatch(c.batch)
-				c.batch = c.batch[:0] // 清空批量处理队列
-			}
+			c.flushBatch()
 		}
 	}
 }
 
 // Start 启动消费者
 func (c *Consumer) Start(ctx context.Context) error {
+	c.ctx, c.cancel = context.WithCancel(ctx)
+
 	// 启动消息处理
-	err := c.consumer.ConsumeMessages(func(msg kafka.VoteMessage) error {
+	err := c.consumer.ConsumeMessages(func(ctx context.Context, msg kafka.VoteMessage) error {
+		c.batchMutex.Lock()
+		defer c.batchMutex.Unlock()
+
 		// 添加到批量处理队列
-		c.batch = append(c.batch, struct {
-			PostID    int64
-			UserID    int64
-			Direction int64
-		}{
+		c.batch = append(c.batch, voteItem{
 			PostID:    msg.PostID,
 			UserID:    msg.UserID,
 			Direction: msg.Direction,
+			span:      trace.SpanContextFromContext(ctx),
 		})
 
 		// 如果批量处理队列达到大小，处理它
@@ -269,7 +330,9 @@ func (c *Consumer) Start(ctx context.Context) error {
 	}
 
 	// 启动一个goroutine定期处理批量消息
-	go c.processMessages(ctx)
+	go c.processMessages(c.ctx)
+	// 定期核对 Redis 与 MySQL 投票数
+	go c.periodicallyCheckDrift(c.ctx)
 
 	return nil
 }
@@ -1,8 +1,10 @@
 package kafka
 
 import (
+	"bluebell_microservices/common/config"
 	"bluebell_microservices/common/pkg/kafka"
 	"bluebell_microservices/common/pkg/logger"
+	"context"
 	"fmt"
 
 	"go.uber.org/zap"
@@ -17,8 +19,8 @@ type Producer struct {
 func NewProducer() *Producer {
 	// 初始化Kafka生产者
 	kafkaConfig := kafka.KafkaConfig{
-		Brokers: []string{"kafka:9092"}, // 默认配置，实际应从配置文件读取
-		Topic:   "post-votes",
+		Brokers: config.Conf.Kafka.Brokers,
+		Topic:   config.Conf.Kafka.Topic,
 	}
 
 	producer, err := kafka.NewProducer(kafkaConfig)
@@ -33,11 +35,11 @@ func NewProducer() *Producer {
 }
 
 // SendVoteMessage 发送投票消息
-func (p *Producer) SendVoteMessage(message kafka.VoteMessage) error {
+func (p *Producer) SendVoteMessage(ctx context.Context, message kafka.VoteMessage) error {
 	if p.producer == nil {
 		return fmt.Errorf("kafka producer is not initialized")
 	}
-	return p.producer.SendVoteMessage(message)
+	return p.producer.SendVoteMessage(ctx, message)
 }
 
 // Close 关闭生产者
@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"bluebell_microservices/common/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 投票落库流水线指标
+var (
+	batchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "vote_batch",
+		Name:      "size",
+		Help:      "每批落库的投票数",
+		Buckets:   []float64{1, 5, 10, 25, 50, 100, 250, 500},
+	})
+
+	batchFlushSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "vote_batch",
+		Name:      "flush_seconds",
+		Help:      "每批投票落库耗时",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	batchResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "vote_batch",
+		Name:      "total",
+		Help:      "投票批次落库结果",
+	}, []string{"result"})
+
+	votesPersisted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "vote_batch",
+		Name:      "votes_persisted_total",
+		Help:      "成功落库的投票数",
+	})
+
+	driftPosts = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "vote",
+		Name:      "drift_posts",
+		Help:      "最近一次核对中 Redis 与 MySQL 投票数不一致的帖子数",
+	})
+
+	driftVotes = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "vote",
+		Name:      "drift_votes",
+		Help:      "最近一次核对中 Redis 与 MySQL 投票数差值的绝对值之和",
+	})
+
+	driftChecked = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "vote",
+		Name:      "drift_checked_posts",
+		Help:      "最近一次核对的帖子数",
+	})
+)
+
+// 评论数校准指标
+var (
+	commentRepairSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "comment_count",
+		Name:      "repair_seconds",
+		Help:      "每次校准帖子评论数的耗时",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300},
+	})
+
+	commentRepairFixed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "comment_count",
+		Name:      "repaired_posts_total",
+		Help:      "校准时评论数与 comment-service 不一致而被修正的帖子数",
+	})
+)
+
+// 批次结果
+const (
+	batchResultSuccess = "success"
+	batchResultFailure = "failure"
+)
@@ -0,0 +1,141 @@
+package logic
+
+import (
+	"context"
+	"strconv"
+
+	commonkafka "bluebell_microservices/common/pkg/kafka"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/post-service/internal/cache"
+	"bluebell_microservices/post-service/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// repairPageSize 校准评论数时每次处理的帖子数，与 comment-service 单次批量查询的上限一致
+const repairPageSize = 100
+
+// CommentCountLogic 根据 comment-service 的评论事件维护帖子评论数
+// Redis 帖子 hash 中的 comments 字段是列表及详情的读取来源，post 表的 comment_count 列是持久化副本；
+// 事件可能丢失或重复投递，重复事件按 comment_id 去重，丢失造成的偏差由 RepairCommentCounts 定期校准
+type CommentCountLogic struct {
+	postDao       PostStore
+	ranking       RankingIndex
+	commentCounts CommentCountIndex
+	comments      CommentCounter
+}
+
+// NewCommentCountLogic 创建 CommentCountLogic
+func NewCommentCountLogic(s PostStores) *CommentCountLogic {
+	return &CommentCountLogic{
+		postDao:       s.Posts,
+		ranking:       s.Ranking,
+		commentCounts: s.CommentCounts,
+		comments:      s.Comments,
+	}
+}
+
+// ApplyCommentEvent 按评论创建、删除事件增减帖子评论数，新评论计入趋势排序；同一事件只生效一次，未知类型的事件被忽略
+// Redis 更新失败时撤销去重记录并返回错误，由消费者重试或转入死信队列；MySQL 更新失败只记录日志，由校准修复
+func (l *CommentCountLogic) ApplyCommentEvent(ctx context.Context, msg commonkafka.CommentMessage) error {
+	var delta int64
+	switch msg.Type {
+	case commonkafka.CommentCreated:
+		delta = 1
+	case commonkafka.CommentDeleted:
+		delta = -1
+	default:
+		logger.Ctx(ctx).Warn("Unknown comment event type, ignored",
+			zap.String("type", msg.Type), zap.Int64("comment_id", msg.CommentID))
+		return nil
+	}
+
+	first, err := l.commentCounts.MarkCommentEvent(msg.CommentID, msg.Type)
+	if err != nil {
+		return err
+	}
+	if !first {
+		logger.Ctx(ctx).Info("Duplicate comment event, skipped",
+			zap.String("type", msg.Type), zap.Int64("comment_id", msg.CommentID))
+		return nil
+	}
+
+	if err := l.commentCounts.IncrPostComments(msg.PostID, delta); err != nil {
+		if unmarkErr := l.commentCounts.UnmarkCommentEvent(msg.CommentID, msg.Type); unmarkErr != nil {
+			logger.Ctx(ctx).Error("Failed to unmark comment event", zap.Int64("comment_id", msg.CommentID), zap.Error(unmarkErr))
+		}
+		return err
+	}
+	if err := l.postDao.IncrCommentCount(ctx, msg.PostID, delta); err != nil {
+		logger.Ctx(ctx).Warn("Failed to update post comment_count, left to repair",
+			zap.Int64("post_id", msg.PostID), zap.Error(err))
+	}
+	if delta > 0 {
+		if err := l.ranking.AddTrendingActivity(msg.PostID, TrendingCommentWeight); err != nil {
+			logger.Ctx(ctx).Warn("Failed to record trending activity", zap.Int64("post_id", msg.PostID), zap.Error(err))
+		}
+	}
+	cache.Invalidate(ctx, cache.NamePost, strconv.FormatInt(msg.PostID, 10))
+	return nil
+}
+
+// RepairCommentCounts 按发帖时间遍历全部帖子，以 comment-service 的评论数为准校准 Redis 与 MySQL，返回被修正的帖子数
+// 写入的是绝对值，多个实例同时执行也不会出错
+func (l *CommentCountLogic) RepairCommentCounts(ctx context.Context) (int, error) {
+	var fixed int
+	for page := int64(1); ; page++ {
+		if err := ctx.Err(); err != nil {
+			return fixed, err
+		}
+		ids, err := l.ranking.GetPostIDsInOrder(&model.ParamPostList{Page: page, Size: repairPageSize, Order: model.OrderTime})
+		if err != nil {
+			return fixed, err
+		}
+		if len(ids) == 0 {
+			return fixed, nil
+		}
+		n, err := l.repairPage(ctx, ids)
+		fixed += n
+		if err != nil {
+			return fixed, err
+		}
+	}
+}
+
+// repairPage 校准一页帖子的评论数
+func (l *CommentCountLogic) repairPage(ctx context.Context, ids []string) (int, error) {
+	postIDs := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		n, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		postIDs = append(postIDs, n)
+	}
+	want, err := l.comments.GetCommentCounts(ctx, postIDs)
+	if err != nil {
+		return 0, err
+	}
+	have, err := l.commentCounts.GetPostCommentData(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var fixed int
+	for idx, postID := range postIDs {
+		if have[idx] == want[postID] {
+			continue
+		}
+		logger.Ctx(ctx).Warn("Post comment count drifted, repairing",
+			zap.Uint64("post_id", postID), zap.Int64("cached", have[idx]), zap.Int64("actual", want[postID]))
+		if err := l.commentCounts.SetPostComments(int64(postID), want[postID]); err != nil {
+			return fixed, err
+		}
+		if err := l.postDao.SetCommentCount(ctx, int64(postID), want[postID]); err != nil {
+			return fixed, err
+		}
+		cache.Invalidate(ctx, cache.NamePost, strconv.FormatUint(postID, 10))
+		fixed++
+	}
+	return fixed, nil
+}
@@ -0,0 +1,136 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonkafka "bluebell_microservices/common/pkg/kafka"
+	"bluebell_microservices/post-service/internal/model"
+)
+
+func TestCommentCountLogic_ApplyCommentEvent(t *testing.T) {
+	created := func(commentID, postID int64) commonkafka.CommentMessage {
+		return commonkafka.CommentMessage{Type: commonkafka.CommentCreated, CommentID: commentID, PostID: postID}
+	}
+	deleted := func(commentID, postID int64) commonkafka.CommentMessage {
+		return commonkafka.CommentMessage{Type: commonkafka.CommentDeleted, CommentID: commentID, PostID: postID}
+	}
+
+	tests := []struct {
+		name   string
+		events []commonkafka.CommentMessage
+		want   int64 // 帖子 1 的评论数
+	}{
+		{
+			name:   "created",
+			events: []commonkafka.CommentMessage{created(10, 1), created(11, 1), created(12, 2)},
+			want:   2,
+		},
+		{
+			name:   "created then deleted",
+			events: []commonkafka.CommentMessage{created(10, 1), created(11, 1), deleted(10, 1)},
+			want:   1,
+		},
+		{
+			name:   "duplicate events applied once",
+			events: []commonkafka.CommentMessage{created(10, 1), created(10, 1), deleted(10, 1), deleted(10, 1)},
+			want:   0,
+		},
+		{
+			name:   "never negative",
+			events: []commonkafka.CommentMessage{deleted(10, 1), deleted(11, 1)},
+			want:   0,
+		},
+		{
+			name:   "unknown type ignored",
+			events: []commonkafka.CommentMessage{created(10, 1), {Type: "edited", CommentID: 10, PostID: 1}},
+			want:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestPostEnv(t)
+			l := NewCommentCountLogic(env.stores)
+			for _, ev := range tt.events {
+				if err := l.ApplyCommentEvent(context.Background(), ev); err != nil {
+					t.Fatalf("ApplyCommentEvent(%+v) error = %v", ev, err)
+				}
+			}
+
+			got, err := env.feed.GetPostCommentData([]string{"1"})
+			if err != nil {
+				t.Fatalf("GetPostCommentData() error = %v", err)
+			}
+			if got[0] != tt.want {
+				t.Errorf("redis comments = %d, want %d", got[0], tt.want)
+			}
+			if n := env.posts.CommentCount(1); n != tt.want {
+				t.Errorf("mysql comment_count = %d, want %d", n, tt.want)
+			}
+
+			detail, err := env.logic.GetPostById(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("GetPostById() error = %v", err)
+			}
+			if detail.CommentCount != tt.want {
+				t.Errorf("GetPostById().CommentCount = %d, want %d", detail.CommentCount, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentCountLogic_RepairCommentCounts(t *testing.T) {
+	env := newTestPostEnv(t)
+	l := NewCommentCountLogic(env.stores)
+	ctx := context.Background()
+
+	// 帖子 1 丢失了一条创建事件，帖子 3 丢失了删除事件
+	for _, ev := range []commonkafka.CommentMessage{
+		{Type: commonkafka.CommentCreated, CommentID: 10, PostID: 1},
+		{Type: commonkafka.CommentCreated, CommentID: 30, PostID: 3},
+	} {
+		if err := l.ApplyCommentEvent(ctx, ev); err != nil {
+			t.Fatalf("ApplyCommentEvent() error = %v", err)
+		}
+	}
+	env.comments.SetCount(1, 2)
+	env.comments.SetCount(3, 0)
+
+	fixed, err := l.RepairCommentCounts(ctx)
+	if err != nil {
+		t.Fatalf("RepairCommentCounts() error = %v", err)
+	}
+	if fixed != 2 {
+		t.Errorf("RepairCommentCounts() fixed = %d, want 2", fixed)
+	}
+
+	res, err := env.logic.GetPostListPre(ctx, &model.ParamPostList{Page: 1, Size: 10, Order: model.OrderTime})
+	if err != nil {
+		t.Fatalf("GetPostListPre() error = %v", err)
+	}
+	if len(res.List) != 3 {
+		t.Fatalf("GetPostListPre() returned %d posts, want 3", len(res.List))
+	}
+	want := map[uint64]int64{1: 2, 2: 0, 3: 0}
+	for _, d := range res.List {
+		if d.CommentCount != want[d.PostID] {
+			t.Errorf("post %d CommentCount = %d, want %d", d.PostID, d.CommentCount, want[d.PostID])
+		}
+		if n := env.posts.CommentCount(d.PostID); n != want[d.PostID] {
+			t.Errorf("post %d mysql comment_count = %d, want %d", d.PostID, n, want[d.PostID])
+		}
+	}
+
+	// 已一致时不再修改
+	if fixed, err = l.RepairCommentCounts(ctx); err != nil || fixed != 0 {
+		t.Errorf("second RepairCommentCounts() = %d, %v, want 0, nil", fixed, err)
+	}
+
+	// comment-service 不可用时返回错误
+	env.comments.Err = errors.New("unavailable")
+	if _, err := l.RepairCommentCounts(ctx); err == nil {
+		t.Error("RepairCommentCounts() error = nil, want error")
+	}
+}
@@ -0,0 +1,140 @@
+package logic
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/post-service/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// 草稿相关错误
+var (
+	ErrNotDraftAuthor = errcode.PermissionDenied("NOT_DRAFT_AUTHOR", "只有作者可以修改或发布草稿")
+	ErrPostNotDraft   = errcode.FailedPrecondition("POST_NOT_DRAFT", "帖子已发布")
+)
+
+// dueDraftBatch 定时发布每次最多处理的草稿数，其余的留给下一次
+const dueDraftBatch = 100
+
+// UpdateDraft 修改草稿的标题、内容、社区及定时发布时间，只有作者可以修改
+func (l *PostLogic) UpdateDraft(ctx context.Context, userID uint64, post *model.Post) error {
+	draft, err := l.getDraft(ctx, int64(post.PostID), userID)
+	if err != nil {
+		return err
+	}
+
+	post.AuthorId = draft.AuthorId
+	post.Status = model.PostStatusDraft
+	if err := l.validatePost(post); err != nil {
+		logger.Ctx(ctx).Warn("Invalid draft", zap.Error(err))
+		return err
+	}
+	if err := l.checkNotBanned(ctx, post); err != nil {
+		return err
+	}
+	ok, err := l.postDao.UpdateDraft(ctx, post)
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to update draft", zap.Uint64("post_id", post.PostID), zap.Error(err))
+		return err
+	}
+	if !ok {
+		// 查询之后被作者或定时任务发布
+		return ErrPostNotDraft
+	}
+	return nil
+}
+
+// PublishDraft 作者立即发布草稿
+func (l *PostLogic) PublishDraft(ctx context.Context, userID uint64, postID int64) error {
+	draft, err := l.getDraft(ctx, postID, userID)
+	if err != nil {
+		return err
+	}
+	if err := l.checkNotBanned(ctx, draft); err != nil {
+		return err
+	}
+	return l.publish(ctx, draft, time.Now())
+}
+
+// PublishDueDrafts 发布定时发布时间不晚于 now 的草稿，返回发布的帖子数；单个草稿发布失败不影响其他草稿
+// 作者已被禁止在社区发言的草稿取消定时，保留为草稿
+func (l *PostLogic) PublishDueDrafts(ctx context.Context, now time.Time) (int, error) {
+	drafts, err := l.postDao.GetDueDrafts(ctx, now, dueDraftBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var published int
+	var firstErr error
+	for _, draft := range drafts {
+		if err := ctx.Err(); err != nil {
+			return published, err
+		}
+		err := l.checkNotBanned(ctx, draft)
+		if errors.Is(err, ErrUserBanned) {
+			draft.PublishTime = nil
+			_, err = l.postDao.UpdateDraft(ctx, draft)
+		} else if err == nil {
+			err = l.publish(ctx, draft, now)
+			if err == nil {
+				published++
+			}
+		}
+		if err != nil && !errors.Is(err, ErrPostNotDraft) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return published, firstErr
+}
+
+// getDraft 查询 userID 的草稿
+func (l *PostLogic) getDraft(ctx context.Context, postID int64, userID uint64) (*model.Post, error) {
+	post, err := l.postDao.GetPostByID(postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPostNotAvailable
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("mysql.GetPostByID(postID) failed", zap.Int64("postID", postID), zap.Error(err))
+		return nil, err
+	}
+	if post.AuthorId != userID {
+		// 草稿不对外展示，他人的草稿与不存在的帖子一样处理
+		if post.Status == model.PostStatusDraft {
+			return nil, ErrPostNotAvailable
+		}
+		return nil, ErrNotDraftAuthor
+	}
+	if post.Status != model.PostStatusDraft {
+		return nil, ErrPostNotDraft
+	}
+	return post, nil
+}
+
+// publish 先将草稿加入帖子列表，再在 MySQL 中改为正常状态：加入列表失败时仍是草稿，可再次发布或由定时任务重试；
+// 状态修改前列表按状态过滤，不会提前展示。状态修改失败时从列表中移除
+// MySQL 中的状态修改只会成功一次，作者手动发布与定时发布或多个实例同时发布同一草稿时，其余返回 ErrPostNotDraft
+func (l *PostLogic) publish(ctx context.Context, draft *model.Post, now time.Time) error {
+	if err := l.indexPost(draft); err != nil {
+		return err
+	}
+	ok, err := l.postDao.PublishDraft(ctx, int64(draft.PostID), now)
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to publish draft", zap.Uint64("post_id", draft.PostID), zap.Error(err))
+		if rmErr := l.ranking.RemovePostFromFeeds(draft.PostID, draft.CommunityID); rmErr != nil {
+			logger.Ctx(ctx).Error("Failed to remove unpublished draft from feeds", zap.Uint64("post_id", draft.PostID), zap.Error(rmErr))
+		}
+		return err
+	}
+	if !ok {
+		// 已由其他一方发布并加入列表
+		return ErrPostNotDraft
+	}
+	logger.Ctx(ctx).Info("Draft published", zap.Uint64("post_id", draft.PostID), zap.Uint64("author_id", draft.AuthorId))
+	return nil
+}
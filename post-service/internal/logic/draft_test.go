@@ -0,0 +1,248 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bluebell_microservices/common/pkg/validate"
+	"bluebell_microservices/post-service/internal/dao/memory"
+	"bluebell_microservices/post-service/internal/model"
+)
+
+// createDraft 以作者 200 在社区 1 创建草稿 10，at 不为零值时定时发布
+func createDraft(t *testing.T, env *testPostEnv, at time.Time) {
+	t.Helper()
+	post := &model.Post{
+		PostID:      10,
+		AuthorId:    200,
+		CommunityID: 1,
+		Status:      model.PostStatusDraft,
+		Title:       "draft",
+		Content:     "work in progress",
+		CreateTime:  time.Now(),
+	}
+	if !at.IsZero() {
+		post.PublishTime = &at
+	}
+	if err := env.logic.CreatePost(context.Background(), post); err != nil {
+		t.Fatalf("CreatePost(draft) error = %v", err)
+	}
+}
+
+// listed 帖子是否出现在全站时间排序及搜索结果中
+func listed(t *testing.T, env *testPostEnv, postID uint64) (inFeed, inSearch bool) {
+	t.Helper()
+	for _, req := range []model.ParamPostList{
+		{Page: 1, Size: 10, Order: model.OrderTime},
+		{Page: 1, Size: 10, Search: "draft"},
+	} {
+		res, err := env.logic.GetPostListPre(context.Background(), &req)
+		if err != nil {
+			t.Fatalf("GetPostListPre(%+v) error = %v", req, err)
+		}
+		for _, id := range listIDs(res) {
+			if id == postID {
+				if req.Search == "" {
+					inFeed = true
+				} else {
+					inSearch = true
+				}
+			}
+		}
+	}
+	return inFeed, inSearch
+}
+
+func TestPostLogic_Draft(t *testing.T) {
+	env := newTestPostEnv(t)
+	ctx := context.Background()
+	createDraft(t, env, time.Time{})
+
+	// 草稿不进入列表、搜索结果，也不能查看详情
+	if inFeed, inSearch := listed(t, env, 10); inFeed || inSearch {
+		t.Fatalf("draft listed: feed = %v, search = %v", inFeed, inSearch)
+	}
+	if _, err := env.logic.GetPostById(ctx, 10); !errors.Is(err, ErrPostNotAvailable) {
+		t.Fatalf("GetPostById(draft) error = %v, want %v", err, ErrPostNotAvailable)
+	}
+
+	// 只有作者可以修改、发布
+	update := &model.Post{PostID: 10, CommunityID: 2, Title: "draft v2", Content: "done"}
+	if err := env.logic.UpdateDraft(ctx, 100, update); !errors.Is(err, ErrPostNotAvailable) {
+		t.Fatalf("UpdateDraft(other user) error = %v, want %v", err, ErrPostNotAvailable)
+	}
+	if err := env.logic.PublishDraft(ctx, 100, 10); !errors.Is(err, ErrPostNotAvailable) {
+		t.Fatalf("PublishDraft(other user) error = %v, want %v", err, ErrPostNotAvailable)
+	}
+	if err := env.logic.UpdateDraft(ctx, 200, update); err != nil {
+		t.Fatalf("UpdateDraft() error = %v", err)
+	}
+
+	if err := env.logic.PublishDraft(ctx, 200, 10); err != nil {
+		t.Fatalf("PublishDraft() error = %v", err)
+	}
+	if inFeed, inSearch := listed(t, env, 10); !inFeed || !inSearch {
+		t.Errorf("published draft listed: feed = %v, search = %v, want true", inFeed, inSearch)
+	}
+	detail, err := env.logic.GetPostById(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPostById() error = %v", err)
+	}
+	if detail.Title != "draft v2" || detail.Post.CommunityID != 2 {
+		t.Errorf("published post = %q in community %d, want %q in community 2", detail.Title, detail.Post.CommunityID, "draft v2")
+	}
+
+	// 已发布的帖子不能再修改或发布
+	if err := env.logic.PublishDraft(ctx, 200, 10); !errors.Is(err, ErrPostNotDraft) {
+		t.Errorf("second PublishDraft() error = %v, want %v", err, ErrPostNotDraft)
+	}
+	if err := env.logic.UpdateDraft(ctx, 200, update); !errors.Is(err, ErrPostNotDraft) {
+		t.Errorf("UpdateDraft(published) error = %v, want %v", err, ErrPostNotDraft)
+	}
+}
+
+// publishedAfterRead 查询帖子后草稿随即被发布，模拟作者修改草稿时定时任务同时发布
+type publishedAfterRead struct {
+	*memory.PostStore
+}
+
+func (s publishedAfterRead) GetPostByID(id int64) (*model.Post, error) {
+	post, err := s.PostStore.GetPostByID(id)
+	if err == nil {
+		_, err = s.PublishDraft(context.Background(), id, time.Now())
+	}
+	return post, err
+}
+
+func TestPostLogic_UpdateDraft_Published(t *testing.T) {
+	env := newTestPostEnv(t)
+	createDraft(t, env, time.Time{})
+	stores := env.stores
+	stores.Posts = publishedAfterRead{env.posts}
+
+	update := &model.Post{PostID: 10, CommunityID: 1, Title: "draft v2", Content: "done"}
+	if err := NewPostLogic(stores).UpdateDraft(context.Background(), 200, update); !errors.Is(err, ErrPostNotDraft) {
+		t.Fatalf("UpdateDraft() error = %v, want %v", err, ErrPostNotDraft)
+	}
+	post, err := env.posts.GetPostByID(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Title != "draft" {
+		t.Errorf("published post title = %q, want unchanged %q", post.Title, "draft")
+	}
+}
+
+func TestPostLogic_CreatePost_PublishTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int32
+		at     time.Time
+	}{
+		{name: "in the past", status: model.PostStatusDraft, at: time.Now().Add(-time.Minute)},
+		{name: "not a draft", status: model.PostStatusNormal, at: time.Now().Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestPostEnv(t)
+			at := tt.at
+			err := env.logic.CreatePost(context.Background(), &model.Post{
+				PostID: 10, AuthorId: 200, CommunityID: 1, Status: tt.status,
+				Title: "draft", Content: "content", PublishTime: &at,
+			})
+			errs, ok := validate.FromError(err)
+			if !ok || len(errs) != 1 || errs[0].Field != "publish_time" {
+				t.Fatalf("CreatePost() error = %v, want validation error on publish_time", err)
+			}
+		})
+	}
+}
+
+func TestPostLogic_PublishDueDrafts(t *testing.T) {
+	env := newTestPostEnv(t)
+	ctx := context.Background()
+	at := time.Now().Add(time.Hour)
+	createDraft(t, env, at)
+
+	// 未到定时发布时间
+	if n, err := env.logic.PublishDueDrafts(ctx, at.Add(-time.Minute)); err != nil || n != 0 {
+		t.Fatalf("PublishDueDrafts(before) = %d, %v, want 0, nil", n, err)
+	}
+	if inFeed, _ := listed(t, env, 10); inFeed {
+		t.Fatal("draft listed before its publish time")
+	}
+
+	if n, err := env.logic.PublishDueDrafts(ctx, at); err != nil || n != 1 {
+		t.Fatalf("PublishDueDrafts(due) = %d, %v, want 1, nil", n, err)
+	}
+	if inFeed, _ := listed(t, env, 10); !inFeed {
+		t.Error("scheduled draft not listed after publishing")
+	}
+
+	// 已发布的不再重复发布
+	if n, err := env.logic.PublishDueDrafts(ctx, at.Add(time.Minute)); err != nil || n != 0 {
+		t.Errorf("second PublishDueDrafts() = %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestPostLogic_PublishDueDrafts_Banned(t *testing.T) {
+	env := newTestPostEnv(t)
+	ctx := context.Background()
+	at := time.Now().Add(time.Hour)
+	createDraft(t, env, at)
+	env.moderation.Ban(1, 200)
+
+	if n, err := env.logic.PublishDueDrafts(ctx, at); err != nil || n != 0 {
+		t.Fatalf("PublishDueDrafts() = %d, %v, want 0, nil", n, err)
+	}
+	// 被封禁作者的草稿取消定时，保留为草稿
+	post, err := env.posts.GetPostByID(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Status != model.PostStatusDraft || post.PublishTime != nil {
+		t.Errorf("draft status = %d, publish time = %v, want draft without publish time", post.Status, post.PublishTime)
+	}
+	if inFeed, _ := listed(t, env, 10); inFeed {
+		t.Error("banned author's draft listed")
+	}
+}
+
+// unavailableIndex Redis 不可用时的帖子索引
+type unavailableIndex struct {
+	*memory.FeedStore
+}
+
+func (unavailableIndex) CreatePost(postID, authorID uint64, title, content string, communityID uint64) error {
+	return errors.New("redis unavailable")
+}
+
+func TestPostLogic_PublishDueDrafts_IndexFailed(t *testing.T) {
+	env := newTestPostEnv(t)
+	ctx := context.Background()
+	at := time.Now().Add(time.Hour)
+	createDraft(t, env, at)
+
+	stores := env.stores
+	stores.Ranking = unavailableIndex{env.feed}
+	if n, err := NewPostLogic(stores).PublishDueDrafts(ctx, at); err == nil || n != 0 {
+		t.Fatalf("PublishDueDrafts(redis down) = %d, %v, want 0 and an error", n, err)
+	}
+	// 加入列表失败时保留为草稿，下次定时任务重试
+	post, err := env.posts.GetPostByID(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Status != model.PostStatusDraft {
+		t.Fatalf("status after failed publish = %d, want draft", post.Status)
+	}
+
+	if n, err := env.logic.PublishDueDrafts(ctx, at); err != nil || n != 1 {
+		t.Fatalf("PublishDueDrafts(retry) = %d, %v, want 1, nil", n, err)
+	}
+	if inFeed, _ := listed(t, env, 10); !inFeed {
+		t.Error("draft not listed after retry")
+	}
+}
@@ -0,0 +1,265 @@
+package logic
+
+import (
+	"context"
+	"strconv"
+
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/rbac"
+	"bluebell_microservices/post-service/internal/cache"
+	"bluebell_microservices/post-service/internal/dao/mysql"
+	"bluebell_microservices/post-service/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// 举报与版主操作相关错误
+var (
+	ErrNotModerator     = errcode.PermissionDenied("NOT_MODERATOR", "不是该社区的版主")
+	ErrInvalidAction    = errcode.InvalidArgument("INVALID_MODERATION_ACTION", "无效的版主操作")
+	ErrTargetMismatch   = errcode.InvalidArgument("TARGET_MISMATCH", "操作对象不属于该社区或与举报不符")
+	ErrReportClosed     = mysql.ErrReportClosed
+	ErrPostNotAvailable = errcode.NotFound("POST_NOT_AVAILABLE", "帖子不存在或已被隐藏")
+)
+
+type ModerationLogic struct {
+	moderationDao ModerationStore
+	postDao       PostStore
+	ranking       RankingIndex
+}
+
+// NewModerationLogic 创建 ModerationLogic，使用 s 中的 Moderation、Posts 及 Ranking
+func NewModerationLogic(s PostStores) *ModerationLogic {
+	return &ModerationLogic{
+		moderationDao: s.Moderation,
+		postDao:       s.Posts,
+		ranking:       s.Ranking,
+	}
+}
+
+// ReportPost 举报帖子
+func (l *ModerationLogic) ReportPost(ctx context.Context, report *model.Report) error {
+	post, err := l.postDao.GetPostByID(int64(report.TargetID))
+	if err != nil || post.Status != model.PostStatusNormal {
+		logger.Ctx(ctx).Warn("Report target post not available", zap.Uint64("post_id", report.TargetID), zap.Error(err))
+		return ErrPostNotAvailable
+	}
+
+	report.TargetType = model.ReportTargetPost
+	report.PostID = post.PostID
+	report.CommunityID = post.CommunityID
+	report.TargetAuthorID = post.AuthorId
+	return l.createReport(ctx, report)
+}
+
+// ReportComment 举报评论，report.PostID 与 report.TargetAuthorID 由调用方从评论服务获取
+func (l *ModerationLogic) ReportComment(ctx context.Context, report *model.Report) error {
+	post, err := l.postDao.GetPostByID(int64(report.PostID))
+	if err != nil {
+		logger.Ctx(ctx).Warn("Report target post not available", zap.Uint64("post_id", report.PostID), zap.Error(err))
+		return ErrPostNotAvailable
+	}
+
+	report.TargetType = model.ReportTargetComment
+	report.CommunityID = post.CommunityID
+	return l.createReport(ctx, report)
+}
+
+func (l *ModerationLogic) createReport(ctx context.Context, report *model.Report) error {
+	report.Status = model.ReportStatusOpen
+	if err := l.moderationDao.CreateReport(ctx, report); err != nil {
+		logger.Ctx(ctx).Error("Failed to create report", zap.Uint64("report_id", report.ReportID), zap.Error(err))
+		return err
+	}
+	logger.Ctx(ctx).Info("Report created",
+		zap.Uint64("report_id", report.ReportID),
+		zap.String("target_type", report.TargetType),
+		zap.Uint64("target_id", report.TargetID),
+		zap.Uint64("community_id", report.CommunityID))
+	return nil
+}
+
+// GetReport 查询单个举报
+func (l *ModerationLogic) GetReport(ctx context.Context, reportID uint64) (*model.Report, error) {
+	return l.moderationDao.GetReportByID(ctx, reportID)
+}
+
+// ListReports 查询社区的举报队列
+func (l *ModerationLogic) ListReports(ctx context.Context, communityID uint64, status string, page, size int64) ([]*model.Report, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	return l.moderationDao.ListReports(ctx, communityID, status, page, size)
+}
+
+// CheckModerator 校验用户能否管理该社区：全站版主和管理员可管理所有社区，其余用户需是社区版主
+func (l *ModerationLogic) CheckModerator(ctx context.Context, userID uint64, role string, communityID uint64) error {
+	if rbac.HasPermission(role, rbac.PermModerateAll) {
+		return nil
+	}
+	isModerator, err := l.moderationDao.IsModerator(ctx, communityID, userID)
+	if err != nil {
+		return err
+	}
+	if !isModerator {
+		return ErrNotModerator
+	}
+	return nil
+}
+
+// GetCommunityRole 查询用户在社区中的身份，communityID 为 0 时根据 postID 确定社区
+func (l *ModerationLogic) GetCommunityRole(ctx context.Context, userID, communityID, postID uint64) (*model.CommunityRole, error) {
+	if communityID == 0 {
+		post, err := l.postDao.GetPostByID(int64(postID))
+		if err != nil {
+			return nil, ErrPostNotAvailable
+		}
+		communityID = post.CommunityID
+	}
+
+	isModerator, err := l.moderationDao.IsModerator(ctx, communityID, userID)
+	if err != nil {
+		return nil, err
+	}
+	isBanned, err := l.moderationDao.IsBanned(ctx, communityID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CommunityRole{
+		CommunityID: communityID,
+		IsModerator: isModerator,
+		IsBanned:    isBanned,
+	}, nil
+}
+
+// Moderate 执行版主操作，并在同一事务中更新关联举报和写入审计日志；p.ValidateOnly 时只做校验
+func (l *ModerationLogic) Moderate(ctx context.Context, p *model.ParamModerate) error {
+	logger.Ctx(ctx).Info("Moderate attempt",
+		zap.Uint64("moderator_id", p.ModeratorID),
+		zap.Uint64("community_id", p.CommunityID),
+		zap.String("action", p.Action),
+		zap.Uint64("report_id", p.ReportID))
+
+	// 1、校验版主身份
+	if err := l.CheckModerator(ctx, p.ModeratorID, p.ModeratorRole, p.CommunityID); err != nil {
+		return err
+	}
+
+	// 2、关联举报时，从举报中补全操作对象，指定的对象须与举报一致
+	if p.ReportID != 0 {
+		report, err := l.moderationDao.GetReportByID(ctx, p.ReportID)
+		if err != nil {
+			return err
+		}
+		if report.CommunityID != p.CommunityID {
+			return ErrTargetMismatch
+		}
+		if report.Status != model.ReportStatusOpen {
+			return ErrReportClosed
+		}
+		if err := fillFromReport(p, report); err != nil {
+			return err
+		}
+	}
+
+	// 3、校验操作对象
+	log := &model.ModerationLog{
+		ModeratorID: p.ModeratorID,
+		CommunityID: p.CommunityID,
+		Action:      p.Action,
+		ReportID:    p.ReportID,
+		Reason:      p.Reason,
+	}
+	switch p.Action {
+	case model.ModerationHidePost:
+		if err := l.checkPostInCommunity(p.PostID, p.CommunityID); err != nil {
+			return err
+		}
+		log.TargetType, log.TargetID = model.ReportTargetPost, p.PostID
+	case model.ModerationRemoveComment:
+		if p.CommentID == 0 {
+			return ErrInvalidAction
+		}
+		if err := l.checkPostInCommunity(p.PostID, p.CommunityID); err != nil {
+			return err
+		}
+		log.TargetType, log.TargetID = model.ReportTargetComment, p.CommentID
+	case model.ModerationBanUser:
+		if p.UserID == 0 {
+			return ErrInvalidAction
+		}
+		log.TargetType, log.TargetID = "user", p.UserID
+	case model.ModerationDismiss:
+		if p.ReportID == 0 {
+			return ErrInvalidAction
+		}
+		log.TargetType, log.TargetID = "report", p.ReportID
+	default:
+		return ErrInvalidAction
+	}
+	if p.ValidateOnly {
+		return nil
+	}
+
+	// 4、事务内执行操作、更新举报并写审计日志
+	reportStatus := model.ReportStatusActioned
+	if p.Action == model.ModerationDismiss {
+		reportStatus = model.ReportStatusDismissed
+	}
+	if err := l.moderationDao.Apply(ctx, p, log, reportStatus); err != nil {
+		logger.Ctx(ctx).Error("Failed to apply moderation action", zap.String("action", p.Action), zap.Error(err))
+		return err
+	}
+
+	// 5、隐藏的帖子从 Redis 排序集合中移除，不再出现在列表中，详情缓存同时失效
+	if p.Action == model.ModerationHidePost {
+		if err := l.ranking.RemovePostFromFeeds(p.PostID, p.CommunityID); err != nil {
+			logger.Ctx(ctx).Error("Failed to remove hidden post from feeds", zap.Uint64("post_id", p.PostID), zap.Error(err))
+		}
+		cache.Invalidate(ctx, cache.NamePost, strconv.FormatUint(p.PostID, 10))
+	}
+
+	logger.Ctx(ctx).Info("Moderate successful",
+		zap.Uint64("moderator_id", p.ModeratorID),
+		zap.String("action", p.Action),
+		zap.String("target_type", log.TargetType),
+		zap.Uint64("target_id", log.TargetID))
+	return nil
+}
+
+// checkPostInCommunity 校验帖子属于该社区
+func (l *ModerationLogic) checkPostInCommunity(postID, communityID uint64) error {
+	if postID == 0 {
+		return ErrInvalidAction
+	}
+	post, err := l.postDao.GetPostByID(int64(postID))
+	if err != nil {
+		return ErrPostNotAvailable
+	}
+	if post.CommunityID != communityID {
+		return ErrTargetMismatch
+	}
+	return nil
+}
+
+// fillFromReport 操作参数未指定对象时使用举报中的对象；指定的对象与举报不一致时返回 ErrTargetMismatch，
+// 避免处理了其他对象却将举报标记为已处理
+func fillFromReport(p *model.ParamModerate, report *model.Report) error {
+	var commentID uint64
+	if report.TargetType == model.ReportTargetComment {
+		commentID = report.TargetID
+	}
+	if (p.PostID != 0 && p.PostID != report.PostID) ||
+		(p.CommentID != 0 && p.CommentID != commentID) ||
+		(p.UserID != 0 && p.UserID != report.TargetAuthorID) {
+		return ErrTargetMismatch
+	}
+	p.PostID = report.PostID
+	p.CommentID = commentID
+	p.UserID = report.TargetAuthorID
+	return nil
+}
@@ -0,0 +1,97 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bluebell_microservices/common/pkg/rbac"
+	"bluebell_microservices/post-service/internal/model"
+)
+
+func TestModerationLogic_Moderate_Report(t *testing.T) {
+	tests := []struct {
+		name      string
+		param     model.ParamModerate
+		wantErr   error
+		wantPost  uint64 // 操作成功后应被隐藏的帖子
+		wantLogID uint64 // 审计日志中的操作对象
+	}{
+		{
+			name:    "other comment",
+			param:   model.ParamModerate{Action: model.ModerationRemoveComment, ReportID: 50, PostID: 1, CommentID: 8},
+			wantErr: ErrTargetMismatch,
+		},
+		{
+			name:    "comment on a post report",
+			param:   model.ParamModerate{Action: model.ModerationRemoveComment, ReportID: 51, PostID: 1, CommentID: 7},
+			wantErr: ErrTargetMismatch,
+		},
+		{
+			name:    "other post",
+			param:   model.ParamModerate{Action: model.ModerationHidePost, ReportID: 51, PostID: 2},
+			wantErr: ErrTargetMismatch,
+		},
+		{
+			name:    "other user",
+			param:   model.ParamModerate{Action: model.ModerationBanUser, ReportID: 50, UserID: 100},
+			wantErr: ErrTargetMismatch,
+		},
+		{
+			name:      "reported comment",
+			param:     model.ParamModerate{Action: model.ModerationRemoveComment, ReportID: 50, PostID: 1, CommentID: 7},
+			wantLogID: 7,
+		},
+		{
+			name:      "target from report",
+			param:     model.ParamModerate{Action: model.ModerationHidePost, ReportID: 51},
+			wantPost:  1,
+			wantLogID: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestPostEnv(t)
+			ctx := context.Background()
+			l := NewModerationLogic(env.stores)
+			env.moderation.AddModerator(1, 300)
+			// 举报 50：帖子 1 下作者 200 的评论 7；举报 51：作者 100 的帖子 1
+			if err := l.ReportComment(ctx, &model.Report{ReportID: 50, TargetID: 7, PostID: 1, TargetAuthorID: 200, ReporterID: 100}); err != nil {
+				t.Fatal(err)
+			}
+			if err := l.ReportPost(ctx, &model.Report{ReportID: 51, TargetID: 1, ReporterID: 200}); err != nil {
+				t.Fatal(err)
+			}
+
+			p := tt.param
+			p.ModeratorID, p.ModeratorRole, p.CommunityID = 300, rbac.RoleUser, 1
+			if err := l.Moderate(ctx, &p); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Moderate() error = %v, want %v", err, tt.wantErr)
+			}
+
+			report, err := l.GetReport(ctx, tt.param.ReportID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logs := env.moderation.Logs()
+			if tt.wantErr != nil {
+				// 不一致时举报保持待处理，不写审计日志
+				if report.Status != model.ReportStatusOpen || len(logs) != 0 {
+					t.Errorf("after mismatch: report status = %q, logs = %d, want open and none", report.Status, len(logs))
+				}
+				return
+			}
+			if report.Status != model.ReportStatusActioned {
+				t.Errorf("report status = %q, want %q", report.Status, model.ReportStatusActioned)
+			}
+			if len(logs) != 1 || logs[0].TargetID != tt.wantLogID {
+				t.Errorf("logs = %+v, want one log on target %d", logs, tt.wantLogID)
+			}
+			if tt.wantPost != 0 {
+				if post, _ := env.posts.GetPostByID(int64(tt.wantPost)); post.Status != model.PostStatusHidden {
+					t.Errorf("post %d status = %d, want hidden", tt.wantPost, post.Status)
+				}
+			}
+		})
+	}
+}
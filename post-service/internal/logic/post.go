@@ -2,20 +2,45 @@ package logic
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"strings"
+	"fmt"
+	"strconv"
 	"time"
 
+	"bluebell_microservices/common/pkg/errcode"
+	"bluebell_microservices/common/pkg/event"
 	commonkafka "bluebell_microservices/common/pkg/kafka"
 	"bluebell_microservices/common/pkg/logger" // 导入公共包
-	"bluebell_microservices/post-service/internal/dao/mysql"
-	postredis "bluebell_microservices/post-service/internal/dao/redis"
-	postkafka "bluebell_microservices/post-service/internal/kafka"
+	"bluebell_microservices/common/pkg/validate"
+	"bluebell_microservices/post-service/internal/cache"
 	"bluebell_microservices/post-service/internal/model"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 )
 
+// ErrUserBanned 用户已被禁止在该社区发言
+var ErrUserBanned = errcode.PermissionDenied("USER_BANNED_IN_COMMUNITY", "用户已被禁止在该社区发言")
+
+// ErrVoteInProgress 同一用户对同一帖子的投票正在处理
+var ErrVoteInProgress = errcode.New(codes.Aborted, "VOTE_IN_PROGRESS", "投票处理中，请稍后再试")
+
+// MaxBatchPosts GetPostsByIDs 一次最多查询的帖子数
+const MaxBatchPosts = 100
+
+// ErrTooManyPostIDs 批量查询的帖子数超过上限
+var ErrTooManyPostIDs = errcode.InvalidArgument("TOO_MANY_POST_IDS", fmt.Sprintf("一次最多查询 %d 个帖子", MaxBatchPosts))
+
+// 趋势排序中各类活跃计入的分值
+const (
+	TrendingVoteWeight    = 1 // 一次赞成票
+	TrendingCommentWeight = 2 // 一条评论
+)
+
+// processingPostLog 帖子列表每条帖子都会打印，每秒输出前 10 条，之后每 100 条输出一条
+var processingPostLog = logger.NewSampler(time.Second, 10, 100)
+
 /*
 依赖注入：
 依赖注入的核心思想是将组件的依赖关系外部化（例如通过构造函数注入），而不是在组件内部直接创建依赖的实例。这样做有几个好处：
@@ -27,34 +52,64 @@ import (
 可扩展性：如果将来你想替换 PostDAO 的实现，只需要修改构造函数或 DI 配置，而不需要修改业务逻辑。
 */
 type PostLogic struct {
-	postDao       *mysql.PostDAO
-	kafkaProducer *postkafka.Producer
+	postDao       PostStore
+	userDao       UserStore
+	voteDao       VoteStore
+	ranking       RankingIndex
+	commentCounts CommentCountIndex
+	moderationDao ModerationStore
+	events        EventBus
+	kafkaProducer EventProducer
 }
 
-func NewPostLogic() (*PostLogic, error) {
-	kafkaProducer := postkafka.NewProducer()
-	if kafkaProducer == nil {
-		logger.Error("Failed to initialize Kafka producer")
-		return nil, errors.New("failed to initialize Kafka producer")
-	}
-
+// NewPostLogic 创建 PostLogic，线上各项存储由 cmd/server 使用 MySQL、Redis、Kafka 的实现组装
+func NewPostLogic(s PostStores) *PostLogic {
 	return &PostLogic{
-		postDao:       mysql.NewPostDAO(),
-		kafkaProducer: kafkaProducer,
-	}, nil
+		postDao:       s.Posts,
+		userDao:       s.Users,
+		voteDao:       s.Votes,
+		ranking:       s.Ranking,
+		commentCounts: s.CommentCounts,
+		moderationDao: s.Moderation,
+		events:        s.Events,
+		kafkaProducer: s.Producer,
+	}
 }
 
 func (l *PostLogic) CreatePost(ctx context.Context, post *model.Post) error {
-	logger.Info("CreatePost attempt", zap.Any("post", post))
+	logger.Ctx(ctx).Info("CreatePost attempt", zap.Any("post", post))
 
-	// 2、创建帖子 保存到数据库
+	// 1、校验标题、内容及社区
+	if err := l.validatePost(post); err != nil {
+		logger.Ctx(ctx).Warn("Invalid post", zap.Error(err))
+		return err
+	}
+
+	// 2、被封禁的用户不能在该社区发帖
+	if err := l.checkNotBanned(ctx, post); err != nil {
+		return err
+	}
+
+	// 3、创建帖子 保存到数据库；草稿发布时才写入redis
+	if post.Status != model.PostStatusDraft {
+		post.Status = model.PostStatusNormal
+	}
 	if err := l.postDao.CreatePost(ctx, post); err != nil {
 		zap.L().Error("mysql.CreatePost(&post) failed", zap.Error(err))
 		return err
 	}
+	if post.Status == model.PostStatusDraft {
+		logger.Ctx(ctx).Info("Draft saved", zap.Uint64("post_id", post.PostID), zap.Timep("publish_time", post.PublishTime))
+		return nil
+	}
 
-	// 3、redis存储帖子信息
-	if err := postredis.CreatePost(
+	// 4、redis存储帖子信息
+	return l.indexPost(post)
+}
+
+// indexPost 将帖子加入 Redis 的帖子列表
+func (l *PostLogic) indexPost(post *model.Post) error {
+	if err := l.ranking.CreatePost(
 		post.PostID,
 		post.AuthorId,
 		post.Title,
@@ -64,21 +119,36 @@ func (l *PostLogic) CreatePost(ctx context.Context, post *model.Post) error {
 		return err
 	}
 	return nil
+}
 
+// checkNotBanned 作者被禁止在帖子所属社区发言时返回 ErrUserBanned
+func (l *PostLogic) checkNotBanned(ctx context.Context, post *model.Post) error {
+	banned, err := l.moderationDao.IsBanned(ctx, post.CommunityID, post.AuthorId)
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to check community ban", zap.Error(err))
+		return err
+	}
+	if banned {
+		logger.Ctx(ctx).Warn("Banned user tried to post",
+			zap.Uint64("author_id", post.AuthorId),
+			zap.Uint64("community_id", post.CommunityID))
+		return ErrUserBanned
+	}
+	return nil
 }
 
-func (l *PostLogic) GetPostList2(req *model.ParamPostList) (*model.ApiPostDetailRes, error) {
-	logger.Info("GetPostList attempt",
+func (l *PostLogic) GetPostList2(ctx context.Context, req *model.ParamPostList) (*model.ApiPostDetailRes, error) {
+	logger.Ctx(ctx).Info("GetPostList attempt",
 		zap.String("Order", req.Order),
 		zap.Int64("Page", req.Page),
 		zap.Int64("Size", req.Size),
 		zap.Int("CommunityID", int(req.CommunityID)),
 		zap.String("Search", req.Search))
 
-	// 从mysql获取总页数
-	total, err := mysql.GetPostTotalCount(req.Search, req.CommunityID)
+	// 1、获取当前页的帖子ID及总数
+	ids, total, err := l.postIDs(req)
 	if err != nil {
-		logger.Warn("GetPostTotalCount failed", zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to get post IDs", zap.Error(err))
 		return nil, err
 	}
 
@@ -89,58 +159,41 @@ func (l *PostLogic) GetPostList2(req *model.ParamPostList) (*model.ApiPostDetail
 	// 初始化空列表，避免返回null
 	resp.List = make([]*model.ApiPostDetail, 0)
 
-	// 1、如果有搜索关键词，直接从MySQL获取匹配的帖子ID
-	var ids []string
-	if req.Search != "" {
-		ids, err = mysql.GetPostIDsBySearch(req.Search, req.Page, req.Size, req.CommunityID)
-	} else {
-		// 如果没有搜索关键词，从Redis获取排序后的ID
-		ids, err = postredis.GetPostIDsInOrder(req)
-	}
-
-	if err != nil {
-		logger.Error("Failed to get post IDs", zap.Error(err))
-		return &resp, nil
-	}
-
 	if len(ids) == 0 {
-		logger.Info("No posts found")
+		logger.Ctx(ctx).Info("No posts found")
 		return &resp, nil
 	}
 
-	// 2、提前查询好每篇帖子的投票数
-	voteData, err := postredis.GetPostVoteData(ids)
+	// 2、根据id去数据库查询帖子详细信息，跳过已隐藏的帖子（从排序集合移除失败时可能仍在其中）
+	found, err := l.postDao.GetPostListByIDs(ids)
 	if err != nil {
-		logger.Warn("redis.GetPostVoteData(ids) failed", zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to get posts from MySQL", zap.Error(err))
 		return nil, err
 	}
+	posts, normalIDs := normalPosts(found)
+	resp.Page.Total -= l.removeStale(ctx, found, len(ids)-len(posts))
+	ids = normalIDs
 
-	// 3、根据id去数据库查询帖子详细信息
-	posts, err := mysql.GetPostListByIDs(ids)
+	// 3、按过滤后的帖子顺序查询投票数
+	voteData, err := l.voteDao.GetPostVoteData(ids)
 	if err != nil {
-		logger.Error("Failed to get posts from MySQL", zap.Error(err))
+		logger.Ctx(ctx).Warn("redis.GetPostVoteData(ids) failed", zap.Error(err))
 		return nil, err
 	}
 
-	// 4、组合数据
+	// 4、批量查询作者信息及评论数后组合数据
+	authors := l.authorNames(ctx, posts)
+	comments := l.commentNums(ctx, posts)
 	for idx, post := range posts {
-		logger.Info("Processing post",
+		processingPostLog.Ctx(ctx).Info("Processing post",
 			zap.Uint64("post_id", post.PostID),
 			zap.Uint64("author_id", post.AuthorId),
 			zap.Uint64("community_id", post.CommunityID))
 
-		// 根据作者id查询作者信息
-		user, err := mysql.GetUserByID(post.AuthorId)
-		if err != nil {
-			logger.Error("mysql.GetUserByID() failed",
-				zap.Uint64("author_id", post.AuthorId),
-				zap.Error(err))
-			continue // 跳过这条数据，继续处理下一条
-		}
 		// 根据社区id查询社区详细信息
-		community, err := mysql.GetCommunityByID(post.CommunityID)
+		community, err := l.getCommunity(ctx, post.CommunityID)
 		if err != nil {
-			logger.Error("mysql.GetCommunityByID() failed",
+			logger.Ctx(ctx).Error("mysql.GetCommunityByID() failed",
 				zap.Uint64("community_id", post.CommunityID),
 				zap.Error(err))
 			continue // 跳过这条数据，继续处理下一条
@@ -150,7 +203,8 @@ func (l *PostLogic) GetPostList2(req *model.ParamPostList) (*model.ApiPostDetail
 			VoteNum:            voteData[idx],
 			Post:               post,
 			CommunityDetailRes: community,
-			AuthorName:         user.UserName,
+			AuthorName:         authors[post.AuthorId],
+			CommentCount:       comments[post.PostID],
 		}
 		resp.List = append(resp.List, postDetail)
 	}
@@ -158,37 +212,34 @@ func (l *PostLogic) GetPostList2(req *model.ParamPostList) (*model.ApiPostDetail
 }
 
 // GetCommunityPostList 根据社区id去查询帖子列表
-func (l *PostLogic) GetCommunityPostList(p *model.ParamPostList) (*model.ApiPostDetailRes, error) {
+func (l *PostLogic) GetCommunityPostList(ctx context.Context, p *model.ParamPostList) (*model.ApiPostDetailRes, error) {
 	var res model.ApiPostDetailRes
-	// 从mysql获取该社区下帖子列表总数
-	total, err := mysql.GetCommunityPostTotalCount(uint64(p.CommunityID))
+	// 1、获取当前页的帖子ID及该社区下的总数
+	ids, total, err := l.postIDs(p)
 	if err != nil {
-		logger.Error("GetCommunityPostTotalCount failed", zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to get community post IDs", zap.Error(err))
 		return nil, err
 	}
 	res.Page.Total = total
-	// 1、根据参数中的排序规则去redis查询id列表
-	ids, err := postredis.GetCommunityPostIDsInOrder(p)
-	if err != nil {
-		logger.Error("GetCommunityPostIDsInOrder failed", zap.Error(err))
-		return nil, err
-	}
 	if len(ids) == 0 {
-		logger.Info("No posts found in Redis")
+		logger.Ctx(ctx).Info("No posts found in Redis")
 		return &res, nil
 	}
 	zap.L().Debug("GetPostList2", zap.Any("ids", ids))
-	// 2、提前查询好每篇帖子的投票数
-	voteData, err := postredis.GetPostVoteData(ids)
+	// 2、根据id去数据库查询帖子详细信息，跳过已隐藏的帖子
+	// 返回的数据还要按照我给定的id的顺序返回  order by FIND_IN_SET(post_id, ?)
+	found, err := l.postDao.GetPostListByIDs(ids)
 	if err != nil {
-		logger.Error("GetPostVoteData failed", zap.Error(err))
+		logger.Ctx(ctx).Error("GetPostListByIDs failed", zap.Error(err))
 		return nil, err
 	}
-	// 3、根据id去数据库查询帖子详细信息
-	// 返回的数据还要按照我给定的id的顺序返回  order by FIND_IN_SET(post_id, ?)
-	posts, err := mysql.GetPostListByIDs(ids)
+	posts, normalIDs := normalPosts(found)
+	res.Page.Total -= l.removeStale(ctx, found, len(ids)-len(posts))
+	ids = normalIDs
+	// 3、按过滤后的帖子顺序查询投票数
+	voteData, err := l.voteDao.GetPostVoteData(ids)
 	if err != nil {
-		logger.Error("GetPostListByIDs failed", zap.Error(err))
+		logger.Ctx(ctx).Error("GetPostVoteData failed", zap.Error(err))
 		return nil, err
 	}
 	res.Page.Page = p.Page
@@ -196,48 +247,53 @@ func (l *PostLogic) GetCommunityPostList(p *model.ParamPostList) (*model.ApiPost
 	res.List = make([]*model.ApiPostDetail, 0, len(posts))
 	// 4、根据社区id查询社区详细信息
 	// 为了减少数据库的查询次数，这里将社区信息提前查询出来
-	community, err := mysql.GetCommunityByID(uint64(p.CommunityID))
+	community, err := l.getCommunity(ctx, uint64(p.CommunityID))
 	if err != nil {
-		logger.Error("mysql.GetCommunityByID() failed",
+		logger.Ctx(ctx).Error("mysql.GetCommunityByID() failed",
 			zap.Uint64("community_id", uint64(p.CommunityID)),
 			zap.Error(err))
 		community = nil
 	}
+	authors := l.authorNames(ctx, posts)
+	comments := l.commentNums(ctx, posts)
 	for idx, post := range posts {
-		// 过滤掉不属于该社区的帖子
-		if post.CommunityID != uint64(p.CommunityID) {
-			continue
-		}
-
-		// 如果有搜索关键词，检查帖子是否匹配
-		if p.Search != "" {
-			matched := strings.Contains(strings.ToLower(post.Title), strings.ToLower(p.Search)) ||
-				strings.Contains(strings.ToLower(post.Content), strings.ToLower(p.Search))
-			if !matched {
-				continue
-			}
-		}
-
-		// 根据作者id查询作者信息
-		user, err := mysql.GetUserByID(post.AuthorId)
-		if err != nil {
-			logger.Error("mysql.GetUserByID() failed",
-				zap.Uint64("postID", post.AuthorId),
-				zap.Error(err))
-			user = nil
-		}
 		// 接口数据拼接
 		postDetail := &model.ApiPostDetail{
 			VoteNum:            voteData[idx],
 			Post:               post,
 			CommunityDetailRes: community,
-			AuthorName:         user.UserName,
+			AuthorName:         authors[post.AuthorId],
+			CommentCount:       comments[post.PostID],
 		}
 		res.List = append(res.List, postDetail)
 	}
 	return &res, nil
 }
 
+// postIDs 当前页的帖子ID及总数，二者来自同一数据源、使用同一过滤条件：
+// 有搜索关键词时取 MySQL 的搜索结果及命中数，否则取 Redis 排序 ZSet 的分页及 ZCARD
+func (l *PostLogic) postIDs(p *model.ParamPostList) ([]string, int64, error) {
+	if p.Search != "" {
+		return l.postDao.SearchPosts(p.Search, p.Page, p.Size, p.CommunityID)
+	}
+
+	var ids []string
+	var err error
+	if p.CommunityID > 0 {
+		ids, err = l.ranking.GetCommunityPostIDsInOrder(p)
+	} else {
+		ids, err = l.ranking.GetPostIDsInOrder(p)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := l.ranking.CountPosts(p.Order, p.CommunityID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return ids, total, nil
+}
+
 func (l *PostLogic) GetPostListPre(ctx context.Context, req *model.ParamPostList) (*model.ApiPostDetailRes, error) {
 	params := &model.ParamPostList{
 		Page:        req.Page,
@@ -247,81 +303,271 @@ func (l *PostLogic) GetPostListPre(ctx context.Context, req *model.ParamPostList
 		Search:      req.Search,
 	}
 
-	logger.Info("GetPostListPre called",
+	logger.Ctx(ctx).Info("GetPostListPre called",
 		zap.String("search", params.Search),
 		zap.Int64("page", params.Page),
 		zap.Int64("size", params.Size),
 		zap.String("order", params.Order),
 		zap.Int64("community_id", params.CommunityID))
 
+	// 为空时按时间排序，其余取值必须是支持的排序方式
+	if params.Order == "" {
+		params.Order = model.OrderTime
+	}
+	v := validate.New()
+	if !v.OneOf("order", params.Order, model.Orders...) {
+		logger.Ctx(ctx).Warn("Invalid post list order", zap.String("order", params.Order))
+		return nil, v.Err()
+	}
+
 	// 根据请求参数的不同,执行不同的业务逻辑
 	if params.CommunityID == 0 {
 		// 查询所有帖子
-		return l.GetPostList2(params)
+		return l.GetPostList2(ctx, params)
 	} else {
 		// 查询指定社区的帖子
-		return l.GetCommunityPostList(params)
+		return l.GetCommunityPostList(ctx, params)
 	}
 }
 
+// GetPostById 帖子详情，优先读取缓存；帖子被隐藏、有新的投票或评论时缓存失效
 func (l *PostLogic) GetPostById(ctx context.Context, id int64) (*model.ApiPostDetail, error) {
+	entry, err := cache.GetOrLoad(ctx, cache.NamePost, strconv.FormatInt(id, 10), func() (*postDetailEntry, error) {
+		detail, err := l.loadPostDetail(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return newPostDetailEntry(detail), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry.detail(), nil
+}
+
+// loadPostDetail 从 MySQL、Redis 查询帖子详情
+func (l *PostLogic) loadPostDetail(ctx context.Context, id int64) (*model.ApiPostDetail, error) {
 	// 查询帖子信息
 	post, err := l.postDao.GetPostByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPostNotAvailable
+	}
 	if err != nil {
-		logger.Error("mysql.GetPostByID(postID) failed",
+		logger.Ctx(ctx).Error("mysql.GetPostByID(postID) failed",
 			zap.Int64("postID", id),
 			zap.Error(err))
 		return nil, err
 	}
+	// 被版主隐藏的帖子及草稿不对外展示
+	if post.Status != model.PostStatusNormal {
+		return nil, ErrPostNotAvailable
+	}
 
 	// 根据作者id查询作者信息
-	user, err := mysql.GetUserByID(post.AuthorId)
-	if err != nil {
-		logger.Error("mysql.GetUserByID() failed",
-			zap.Uint64("postID", post.AuthorId),
-			zap.Error(err))
-		return nil, err
-	}
+	authors := l.authorNames(ctx, []*model.Post{post})
 	// 根据社区id查询社区详细信息
-	community, err := mysql.GetCommunityByID(post.CommunityID)
+	community, err := l.getCommunity(ctx, post.CommunityID)
 	if err != nil {
-		logger.Error("mysql.GetCommunityByID() failed",
+		logger.Ctx(ctx).Error("mysql.GetCommunityByID() failed",
 			zap.Uint64("community_id", post.CommunityID),
 			zap.Error(err))
 		return nil, err
 	}
 	// 根据帖子id查询帖子的投票数
-	voteNum, err := postredis.GetPostVoteNum(id)
+	voteNum, err := l.voteDao.GetPostVoteNum(id)
 	if err != nil {
-		logger.Error("redis.GetPostVoteNum failed", zap.Error(err))
+		logger.Ctx(ctx).Error("redis.GetPostVoteNum failed", zap.Error(err))
 		return nil, err
 	}
 
+	comments := l.commentNums(ctx, []*model.Post{post})
+
 	// 接口数据拼接
 	data := &model.ApiPostDetail{
 		Post:               post,
 		CommunityDetailRes: community,
-		AuthorName:         user.UserName,
+		AuthorName:         authors[post.AuthorId],
 		VoteNum:            voteNum,
+		CommentCount:       comments[post.PostID],
 	}
 	return data, nil
 
 }
 
+// GetPostsByIDs 批量查询帖子详情，按 ids 的顺序返回；重复的 ID 只返回一次，不存在、被隐藏的帖子及草稿不返回
+func (l *PostLogic) GetPostsByIDs(ctx context.Context, ids []int64) ([]*model.ApiPostDetail, error) {
+	seen := make(map[int64]bool, len(ids))
+	strIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			strIDs = append(strIDs, strconv.FormatInt(id, 10))
+		}
+	}
+	if len(strIDs) > MaxBatchPosts {
+		return nil, ErrTooManyPostIDs
+	}
+	if len(strIDs) == 0 {
+		return []*model.ApiPostDetail{}, nil
+	}
+
+	found, err := l.postDao.GetPostListByIDs(strIDs)
+	if err != nil {
+		logger.Ctx(ctx).Error("GetPostListByIDs failed", zap.Int("count", len(strIDs)), zap.Error(err))
+		return nil, err
+	}
+	posts, foundIDs := normalPosts(found)
+	if len(posts) == 0 {
+		return []*model.ApiPostDetail{}, nil
+	}
+
+	voteData, err := l.voteDao.GetPostVoteData(foundIDs)
+	if err != nil {
+		logger.Ctx(ctx).Error("GetPostVoteData failed", zap.Error(err))
+		return nil, err
+	}
+	authors := l.authorNames(ctx, posts)
+	comments := l.commentNums(ctx, posts)
+	list := make([]*model.ApiPostDetail, 0, len(posts))
+	for idx, post := range posts {
+		community, err := l.getCommunity(ctx, post.CommunityID)
+		if err != nil {
+			logger.Ctx(ctx).Error("mysql.GetCommunityByID() failed",
+				zap.Uint64("community_id", post.CommunityID),
+				zap.Error(err))
+			continue
+		}
+		list = append(list, &model.ApiPostDetail{
+			VoteNum:            voteData[idx],
+			Post:               post,
+			CommunityDetailRes: community,
+			AuthorName:         authors[post.AuthorId],
+			CommentCount:       comments[post.PostID],
+		})
+	}
+	return list, nil
+}
+
+// normalPosts 过滤掉已隐藏、未发布的帖子，返回剩余帖子及按相同顺序排列的帖子ID，供查询投票数时与帖子一一对应
+func normalPosts(found []*model.Post) ([]*model.Post, []string) {
+	posts := make([]*model.Post, 0, len(found))
+	ids := make([]string, 0, len(found))
+	for _, post := range found {
+		if post.Status != model.PostStatusNormal {
+			continue
+		}
+		posts = append(posts, post)
+		ids = append(ids, strconv.FormatUint(post.PostID, 10))
+	}
+	return posts, ids
+}
+
+// removeStale 将当前页中已隐藏的帖子从排序集合中移除，之后的分页及总数不再包含它们；
+// skipped 为本页被过滤掉的帖子数（含已隐藏及已不存在的），原样返回用于修正本次的总数
+// 草稿在发布时先加入排序集合再修改状态，不在此移除
+func (l *PostLogic) removeStale(ctx context.Context, found []*model.Post, skipped int) int64 {
+	for _, post := range found {
+		if post.Status != model.PostStatusHidden {
+			continue
+		}
+		if err := l.ranking.RemovePostFromFeeds(post.PostID, post.CommunityID); err != nil {
+			logger.Ctx(ctx).Warn("Failed to remove hidden post from feeds", zap.Uint64("post_id", post.PostID), zap.Error(err))
+		}
+	}
+	return int64(skipped)
+}
+
+// authorNames 通过 user-service 批量查询帖子作者的用户名；查询失败时作者名为空，不影响帖子展示
+func (l *PostLogic) authorNames(ctx context.Context, posts []*model.Post) map[uint64]string {
+	ids := make([]uint64, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.AuthorId)
+	}
+	users, err := l.userDao.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		logger.Ctx(ctx).Warn("Failed to get post authors, author names omitted", zap.Int("count", len(ids)), zap.Error(err))
+		return nil
+	}
+	names := make(map[uint64]string, len(users))
+	for id, u := range users {
+		names[id] = u.UserName
+	}
+	return names
+}
+
+// commentNums 帖子的评论数，读取 Redis 中由评论事件维护的计数；查询失败时评论数为 0，不影响帖子展示
+func (l *PostLogic) commentNums(ctx context.Context, posts []*model.Post) map[uint64]int64 {
+	ids := make([]string, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, strconv.FormatUint(post.PostID, 10))
+	}
+	data, err := l.commentCounts.GetPostCommentData(ids)
+	if err != nil {
+		logger.Ctx(ctx).Warn("Failed to get post comment counts, counts omitted", zap.Int("count", len(ids)), zap.Error(err))
+		return nil
+	}
+	nums := make(map[uint64]int64, len(posts))
+	for idx, post := range posts {
+		nums[post.PostID] = data[idx]
+	}
+	return nums
+}
+
+// getCommunity 社区信息，优先读取缓存
+func (l *PostLogic) getCommunity(ctx context.Context, id uint64) (*model.CommunityDetailRes, error) {
+	return cache.GetOrLoad(ctx, cache.NameCommunity, strconv.FormatUint(id, 10), func() (*model.CommunityDetailRes, error) {
+		return l.postDao.GetCommunityByID(id)
+	})
+}
+
+// postDetailEntry 帖子详情的缓存结构，Post 的时间字段在 JSON 中被忽略，需单独保存
+type postDetailEntry struct {
+	Post         *model.Post               `json:"post"`
+	CreateTime   time.Time                 `json:"create_time"`
+	UpdateTime   time.Time                 `json:"update_time"`
+	Community    *model.CommunityDetailRes `json:"community"`
+	AuthorName   string                    `json:"author_name"`
+	VoteNum      int64                     `json:"vote_num"`
+	CommentCount int64                     `json:"comment_count"`
+}
+
+func newPostDetailEntry(d *model.ApiPostDetail) *postDetailEntry {
+	return &postDetailEntry{
+		Post:         d.Post,
+		CreateTime:   d.Post.CreateTime,
+		UpdateTime:   d.Post.UpdateTime,
+		Community:    d.CommunityDetailRes,
+		AuthorName:   d.AuthorName,
+		VoteNum:      d.VoteNum,
+		CommentCount: d.CommentCount,
+	}
+}
+
+func (e *postDetailEntry) detail() *model.ApiPostDetail {
+	e.Post.CreateTime, e.Post.UpdateTime = e.CreateTime, e.UpdateTime
+	return &model.ApiPostDetail{
+		Post:               e.Post,
+		CommunityDetailRes: e.Community,
+		AuthorName:         e.AuthorName,
+		VoteNum:            e.VoteNum,
+		CommentCount:       e.CommentCount,
+	}
+}
+
 func (l *PostLogic) Vote(ctx context.Context, postID int64, direction int64, userID int64) error {
 	if l.kafkaProducer == nil {
 		return errors.New("kafka producer not available")
 	}
-	logger.Info("Vote called",
+	logger.Ctx(ctx).Info("Vote called",
 		zap.Int64("post_id", postID),
 		zap.Int64("direction", direction),
 		zap.Int64("user_id", userID))
 
 	//锁的作用范围是整个Vote方法
 	// 获取分布式锁，锁的过期时间设置为10秒
-	lockAcquired, err := postredis.AcquireLock(postID, userID, 10*time.Second)
+	lockAcquired, err := l.voteDao.AcquireLock(postID, userID, 10*time.Second)
 	if err != nil {
-		logger.Error("Failed to acquire lock",
+		logger.Ctx(ctx).Error("Failed to acquire lock",
 			zap.Int64("post_id", postID),
 			zap.Int64("user_id", userID),
 			zap.Error(err))
@@ -329,17 +575,17 @@ func (l *PostLogic) Vote(ctx context.Context, postID int64, direction int64, use
 	}
 
 	if !lockAcquired {
-		logger.Warn("Failed to acquire lock, another vote operation is in progress",
+		logger.Ctx(ctx).Warn("Failed to acquire lock, another vote operation is in progress",
 			zap.Int64("post_id", postID),
 			zap.Int64("user_id", userID))
-		return errors.New("another vote operation is in progress")
+		return ErrVoteInProgress
 	}
 
 	// 确保在函数结束时释放锁
 	defer func() {
-		err := postredis.ReleaseLock(postID, userID)
+		err := l.voteDao.ReleaseLock(postID, userID)
 		if err != nil {
-			logger.Error("Failed to release lock",
+			logger.Ctx(ctx).Error("Failed to release lock",
 				zap.Int64("post_id", postID),
 				zap.Int64("user_id", userID),
 				zap.Error(err))
@@ -347,19 +593,32 @@ func (l *PostLogic) Vote(ctx context.Context, postID int64, direction int64, use
 	}()
 
 	// 1、使用Redis事务记录投票状态和数据
-	err = postredis.CreatePostVote(postID, userID, direction)
+	err = l.voteDao.CreatePostVote(postID, userID, direction)
 	if err != nil {
-		logger.Error("Failed to write vote to Redis cache",
+		logger.Ctx(ctx).Error("Failed to write vote to Redis cache",
 			zap.Int64("post_id", postID),
 			zap.Int64("user_id", userID),
 			zap.Error(err))
 		return err
 	}
 
+	// 投票数已变化，帖子详情缓存失效
+	cache.Invalidate(ctx, cache.NamePost, strconv.FormatInt(postID, 10))
+
+	// 赞成票计入趋势排序，失败不影响投票结果
+	if direction == 1 {
+		if err := l.ranking.AddTrendingActivity(postID, TrendingVoteWeight); err != nil {
+			logger.Ctx(ctx).Warn("Failed to record trending activity", zap.Int64("post_id", postID), zap.Error(err))
+		}
+	}
+
+	// 推送最新投票数给订阅方，失败不影响投票结果
+	l.publishVoteEvent(ctx, postID)
+
 	// 2、设置投票状态为未入库(0)
-	err = postredis.SetVoteStatus(postID, userID, 0, 24*time.Hour)
+	err = l.voteDao.SetVoteStatus(postID, userID, 0, 24*time.Hour)
 	if err != nil {
-		logger.Error("Failed to set vote status",
+		logger.Ctx(ctx).Error("Failed to set vote status",
 			zap.Int64("post_id", postID),
 			zap.Int64("user_id", userID),
 			zap.Error(err))
@@ -375,9 +634,9 @@ func (l *PostLogic) Vote(ctx context.Context, postID int64, direction int64, use
 	}
 
 	// 4、发送到Kafka
-	err = l.kafkaProducer.SendVoteMessage(voteMsg)
+	err = l.kafkaProducer.SendVoteMessage(ctx, voteMsg)
 	if err != nil {
-		logger.Error("Failed to send vote message to Kafka",
+		logger.Ctx(ctx).Error("Failed to send vote message to Kafka",
 			zap.Int64("post_id", postID),
 			zap.Int64("user_id", userID),
 			zap.Error(err))
@@ -386,3 +645,54 @@ func (l *PostLogic) Vote(ctx context.Context, postID int64, direction int64, use
 
 	return nil
 }
+
+// publishVoteEvent 发布帖子最新的投票数
+func (l *PostLogic) publishVoteEvent(ctx context.Context, postID int64) {
+	voteNum, err := l.voteDao.GetPostVoteNum(postID)
+	if err != nil {
+		logger.Ctx(ctx).Warn("Failed to get vote number for event", zap.Int64("post_id", postID), zap.Error(err))
+		return
+	}
+	ev := &event.PostEvent{
+		Type:    event.TypeVote,
+		PostID:  postID,
+		VoteNum: voteNum,
+	}
+	if err := l.events.PublishPostEvent(ev); err != nil {
+		logger.Ctx(ctx).Warn("Failed to publish vote event", zap.Int64("post_id", postID), zap.Error(err))
+	}
+}
+
+// SubscribePost 订阅帖子实时事件，先推送当前投票数，之后持续转发新事件直到 ctx 结束
+func (l *PostLogic) SubscribePost(ctx context.Context, postID int64, send func(*event.PostEvent) error) error {
+	sub, err := l.events.SubscribePostEvents(postID)
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to subscribe post events", zap.Int64("post_id", postID), zap.Error(err))
+		return err
+	}
+	defer sub.Close()
+
+	// 订阅建立后再读取快照，避免错过两者之间的投票
+	voteNum, err := l.voteDao.GetPostVoteNum(postID)
+	if err != nil {
+		return err
+	}
+	if err := send(&event.PostEvent{Type: event.TypeVote, PostID: postID, VoteNum: voteNum}); err != nil {
+		return err
+	}
+
+	ch := sub.Events()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
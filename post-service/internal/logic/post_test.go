@@ -0,0 +1,615 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/event"
+	commonkafka "bluebell_microservices/common/pkg/kafka"
+	"bluebell_microservices/common/pkg/validate"
+	"bluebell_microservices/post-service/internal/dao/memory"
+	postredis "bluebell_microservices/post-service/internal/dao/redis"
+	"bluebell_microservices/post-service/internal/model"
+)
+
+// testPostEnv 使用内存存储的 PostLogic 及其依赖
+type testPostEnv struct {
+	logic      *PostLogic
+	posts      *memory.PostStore
+	users      *memory.UserStore
+	feed       *memory.FeedStore
+	moderation *memory.ModerationStore
+	events     *memory.EventBus
+	producer   *memory.Producer
+	comments   *memory.CommentCounter
+	stores     PostStores
+}
+
+// newTestPostEnv 预置社区 1、2，作者 100、200，以及帖子：
+// 社区 1 下的帖子 1（3 小时前）、帖子 2（2 小时前），社区 2 下的帖子 3（1 小时前）
+func newTestPostEnv(t *testing.T) *testPostEnv {
+	t.Helper()
+	posts := memory.NewPostStore()
+	env := &testPostEnv{
+		posts:      posts,
+		users:      memory.NewUserStore(),
+		feed:       memory.NewFeedStore(),
+		moderation: memory.NewModerationStore(posts),
+		events:     memory.NewEventBus(),
+		producer:   memory.NewProducer(),
+		comments:   memory.NewCommentCounter(),
+	}
+	env.stores = PostStores{
+		Posts:         env.posts,
+		Users:         env.users,
+		Votes:         env.feed,
+		Ranking:       env.feed,
+		CommentCounts: env.feed,
+		Comments:      env.comments,
+		Moderation:    env.moderation,
+		Events:        env.events,
+		Producer:      env.producer,
+	}
+	env.logic = NewPostLogic(env.stores)
+
+	env.posts.AddCommunity(&model.CommunityDetailRes{CommunityID: 1, CommunityName: "go"})
+	env.posts.AddCommunity(&model.CommunityDetailRes{CommunityID: 2, CommunityName: "rust"})
+	env.users.AddUser(&model.User{UserID: 100, UserName: "alice"})
+	env.users.AddUser(&model.User{UserID: 200, UserName: "bob"})
+
+	now := time.Now()
+	for _, p := range []struct {
+		id, community uint64
+		title         string
+		age           time.Duration
+	}{
+		{1, 1, "hello go", 3 * time.Hour},
+		{2, 1, "generics", 2 * time.Hour},
+		{3, 2, "hello rust", time.Hour},
+	} {
+		post := &model.Post{
+			PostID:      p.id,
+			AuthorId:    100,
+			CommunityID: p.community,
+			Status:      model.PostStatusNormal,
+			Title:       p.title,
+			Content:     "content of " + p.title,
+			CreateTime:  now.Add(-p.age),
+		}
+		if err := env.logic.CreatePost(context.Background(), post); err != nil {
+			t.Fatalf("CreatePost(%d) error = %v", p.id, err)
+		}
+		env.feed.SetPostTime(p.id, post.CreateTime)
+	}
+	return env
+}
+
+// listIDs 帖子列表中的帖子id
+func listIDs(res *model.ApiPostDetailRes) []uint64 {
+	ids := make([]uint64, 0, len(res.List))
+	for _, d := range res.List {
+		ids = append(ids, d.PostID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPostLogic_CreatePost(t *testing.T) {
+	tests := []struct {
+		name       string
+		post       model.Post
+		banned     bool
+		wantErr    error
+		wantFields []string // 期望的校验错误字段
+	}{
+		{
+			name: "ok",
+			post: model.Post{CommunityID: 1, Title: "new", Content: "body"},
+		},
+		{
+			name:       "missing title and community",
+			post:       model.Post{Content: "body"},
+			wantFields: []string{"title", "community_id"},
+		},
+		{
+			name:       "unknown community",
+			post:       model.Post{CommunityID: 9, Title: "new", Content: "body"},
+			wantFields: []string{"community_id"},
+		},
+		{
+			name:       "title too long",
+			post:       model.Post{CommunityID: 1, Title: string(make([]rune, MaxPostTitleLen+1)), Content: "body"},
+			wantFields: []string{"title"},
+		},
+		{
+			name:    "banned author",
+			post:    model.Post{CommunityID: 1, Title: "new", Content: "body"},
+			banned:  true,
+			wantErr: ErrUserBanned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestPostEnv(t)
+			if tt.banned {
+				env.moderation.Ban(1, 200)
+			}
+
+			post := tt.post
+			post.PostID = 10
+			post.AuthorId = 200
+			post.CreateTime = time.Now()
+			err := env.logic.CreatePost(context.Background(), &post)
+
+			if tt.wantFields != nil {
+				var errs validate.Errors
+				if !errors.As(err, &errs) {
+					t.Fatalf("CreatePost() error = %v, want validation error", err)
+				}
+				got := make(map[string]bool)
+				for _, fe := range errs {
+					got[fe.Field] = true
+				}
+				for _, f := range tt.wantFields {
+					if !got[f] {
+						t.Errorf("missing field error %q in %v", f, errs)
+					}
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreatePost() error = %v, want %v", err, tt.wantErr)
+			}
+
+			_, getErr := env.posts.GetPostByID(10)
+			if saved := getErr == nil; saved != (err == nil) {
+				t.Fatalf("post saved = %v, want %v", saved, err == nil)
+			}
+			if err != nil {
+				return
+			}
+			// 作者默认投赞成票，且出现在时间排序的第一位
+			if n, _ := env.feed.GetPostVoteNum(10); n != 1 {
+				t.Errorf("vote num = %d, want 1", n)
+			}
+			ids, _ := env.feed.GetPostIDsInOrder(&model.ParamPostList{Page: 1, Size: 1, Order: model.OrderTime})
+			if len(ids) != 1 || ids[0] != "10" {
+				t.Errorf("latest post ids = %v, want [10]", ids)
+			}
+		})
+	}
+}
+
+func TestPostLogic_CreatePost_BlockedWordsReload(t *testing.T) {
+	old := config.Get()
+	t.Cleanup(func() { config.Set(old) })
+	env := newTestPostEnv(t)
+
+	// 屏蔽词在每次校验时读取，配置更新后无需重建 PostLogic
+	tests := []struct {
+		name       string
+		words      []string
+		wantFields []string
+	}{
+		{name: "no blocked words", words: nil},
+		{name: "title blocked", words: []string{"Spam"}, wantFields: []string{"title"}},
+		{name: "word removed", words: []string{"other"}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Set(&config.Config{Validation: &config.Validation{BlockedWords: tt.words}})
+
+			post := model.Post{PostID: uint64(10 + i), AuthorId: 200, CommunityID: 1, Title: "spam here", Content: "body", CreateTime: time.Now()}
+			err := env.logic.CreatePost(context.Background(), &post)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("CreatePost() error = %v", err)
+				}
+				return
+			}
+			errs, ok := validate.FromError(err)
+			if !ok || len(errs) != len(tt.wantFields) || errs[0].Field != tt.wantFields[0] || errs[0].Reason != validate.ReasonBlocked {
+				t.Fatalf("CreatePost() error = %v, want blocked %v", err, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestPostLogic_GetPostListPre(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       model.ParamPostList
+		upVotes   map[int64]int // 帖子 -> 其他用户的赞成票数
+		votes     map[int64]int // 帖子 -> 通过 Vote 投的赞成票数，计入趋势排序
+		comments  map[int64]int // 帖子 -> 评论数
+		hide      uint64        // 只在 MySQL 中隐藏、仍留在排序集合中的帖子
+		wantIDs   []uint64
+		wantVotes map[uint64]int64 // 含作者发帖时的一票
+		wantTotal int64
+		wantErr   string // 期望的校验错误字段
+	}{
+		{
+			name:      "all by time",
+			req:       model.ParamPostList{Page: 1, Size: 10, Order: model.OrderTime},
+			wantIDs:   []uint64{3, 2, 1},
+			wantTotal: 3,
+		},
+		{
+			name:      "all by score",
+			req:       model.ParamPostList{Page: 1, Size: 10, Order: model.OrderScore},
+			upVotes:   map[int64]int{1: 20}, // 20 票约 2.4 小时，超过与帖子 3 的发布时间差
+			wantIDs:   []uint64{1, 3, 2},
+			wantTotal: 3,
+		},
+		{
+			name:      "empty order by time",
+			req:       model.ParamPostList{Page: 1, Size: 10},
+			wantIDs:   []uint64{3, 2, 1},
+			wantTotal: 3,
+		},
+		{
+			name:      "most commented",
+			req:       model.ParamPostList{Page: 1, Size: 10, Order: model.OrderComments},
+			comments:  map[int64]int{1: 1, 3: 2},
+			wantIDs:   []uint64{3, 1},
+			wantTotal: 2, // 总数与列表一致，只统计有评论的帖子
+		},
+		{
+			name:      "trending",
+			req:       model.ParamPostList{Page: 1, Size: 10, Order: model.OrderTrending},
+			votes:     map[int64]int{2: 3},
+			comments:  map[int64]int{1: 1}, // 一条评论计 2 分，少于 3 张赞成票
+			wantIDs:   []uint64{2, 1},
+			wantTotal: 2,
+		},
+		{
+			name:      "community most commented",
+			req:       model.ParamPostList{Page: 1, Size: 10, CommunityID: 1, Order: model.OrderComments},
+			comments:  map[int64]int{1: 2, 2: 1, 3: 5},
+			wantIDs:   []uint64{1, 2},
+			wantTotal: 2,
+		},
+		{
+			name:      "community trending",
+			req:       model.ParamPostList{Page: 1, Size: 10, CommunityID: 1, Order: model.OrderTrending},
+			votes:     map[int64]int{2: 1},
+			comments:  map[int64]int{3: 5},
+			wantIDs:   []uint64{2},
+			wantTotal: 1,
+		},
+		{
+			name:      "hidden post left in feed",
+			req:       model.ParamPostList{Page: 1, Size: 10, Order: model.OrderTime},
+			upVotes:   map[int64]int{1: 2, 3: 1},
+			hide:      2,
+			wantIDs:   []uint64{3, 1},
+			wantTotal: 2,
+			wantVotes: map[uint64]int64{3: 2, 1: 3},
+		},
+		{
+			name:      "hidden post left in community feed",
+			req:       model.ParamPostList{Page: 1, Size: 10, CommunityID: 1, Order: model.OrderTime},
+			upVotes:   map[int64]int{1: 1},
+			hide:      2,
+			wantIDs:   []uint64{1},
+			wantTotal: 1,
+			wantVotes: map[uint64]int64{1: 2},
+		},
+		{
+			name:    "invalid order",
+			req:     model.ParamPostList{Page: 1, Size: 10, Order: "hot"},
+			wantErr: "order",
+		},
+		{
+			name:      "second page",
+			req:       model.ParamPostList{Page: 2, Size: 2, Order: model.OrderTime},
+			wantIDs:   []uint64{1},
+			wantTotal: 3,
+		},
+		{
+			name:      "search",
+			req:       model.ParamPostList{Page: 1, Size: 10, Search: "hello"},
+			wantIDs:   []uint64{3, 1},
+			wantTotal: 2,
+		},
+		{
+			name:      "community",
+			req:       model.ParamPostList{Page: 1, Size: 10, CommunityID: 1, Order: model.OrderTime},
+			wantIDs:   []uint64{2, 1},
+			wantTotal: 2,
+		},
+		{
+			name:      "community second page",
+			req:       model.ParamPostList{Page: 2, Size: 1, CommunityID: 1, Order: model.OrderTime},
+			wantIDs:   []uint64{1},
+			wantTotal: 2,
+		},
+		{
+			name:      "community search",
+			req:       model.ParamPostList{Page: 1, Size: 10, CommunityID: 1, Search: "generics"},
+			wantIDs:   []uint64{2},
+			wantTotal: 1, // 搜索命中数，而不是社区帖子总数
+		},
+		{
+			name:      "empty community",
+			req:       model.ParamPostList{Page: 1, Size: 10, CommunityID: 9},
+			wantIDs:   []uint64{},
+			wantTotal: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestPostEnv(t)
+			for postID, n := range tt.upVotes {
+				for i := 0; i < n; i++ {
+					if err := env.feed.CreatePostVote(postID, int64(1000+i), 1); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			for postID, n := range tt.votes {
+				for i := 0; i < n; i++ {
+					if err := env.logic.Vote(context.Background(), postID, 1, int64(2000+i)); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			comments := NewCommentCountLogic(env.stores)
+			for postID, n := range tt.comments {
+				for i := 0; i < n; i++ {
+					ev := commonkafka.CommentMessage{Type: commonkafka.CommentCreated, CommentID: postID*100 + int64(i), PostID: postID}
+					if err := comments.ApplyCommentEvent(context.Background(), ev); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			if tt.hide != 0 {
+				env.posts.SetPostStatus(tt.hide, model.PostStatusHidden)
+			}
+
+			req := tt.req
+			res, err := env.logic.GetPostListPre(context.Background(), &req)
+			if tt.wantErr != "" {
+				errs, ok := validate.FromError(err)
+				if !ok || len(errs) != 1 || errs[0].Field != tt.wantErr {
+					t.Fatalf("GetPostListPre() error = %v, want validation error on %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPostListPre() error = %v", err)
+			}
+			if got := listIDs(res); !equalIDs(got, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", got, tt.wantIDs)
+			}
+			if res.Page.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", res.Page.Total, tt.wantTotal)
+			}
+			// 残留的隐藏帖子在列表时从排序集合中移除，之后的总数同样准确
+			if tt.hide != 0 {
+				if n, _ := env.feed.CountPosts(tt.req.Order, tt.req.CommunityID); n != tt.wantTotal {
+					t.Errorf("feed count after listing = %d, want %d", n, tt.wantTotal)
+				}
+			}
+			for _, d := range res.List {
+				if d.AuthorName != "alice" || d.CommunityDetailRes == nil {
+					t.Errorf("post %d not joined with author and community: %+v", d.PostID, d)
+				}
+				if want, ok := tt.wantVotes[d.PostID]; ok && d.VoteNum != want {
+					t.Errorf("post %d votes = %d, want %d", d.PostID, d.VoteNum, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPostLogic_GetPostById(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      int64
+		hide    bool
+		wantErr error
+	}{
+		{name: "ok", id: 2},
+		{name: "not found", id: 99, wantErr: ErrPostNotAvailable},
+		{name: "hidden", id: 2, hide: true, wantErr: ErrPostNotAvailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestPostEnv(t)
+			if tt.hide {
+				env.posts.SetPostStatus(uint64(tt.id), model.PostStatusHidden)
+			}
+			detail, err := env.logic.GetPostById(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetPostById() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if detail.PostID != uint64(tt.id) || detail.AuthorName != "alice" || detail.CommunityName != "go" || detail.VoteNum != 1 {
+				t.Errorf("GetPostById() = %+v", detail)
+			}
+		})
+	}
+}
+
+func TestPostLogic_GetPostsByIDs(t *testing.T) {
+	tooMany := make([]int64, MaxBatchPosts+1)
+	for i := range tooMany {
+		tooMany[i] = int64(i + 1)
+	}
+	tests := []struct {
+		name    string
+		ids     []int64
+		hide    uint64
+		wantIDs []uint64
+		wantErr error
+	}{
+		{name: "keeps request order", ids: []int64{3, 1, 2}, wantIDs: []uint64{3, 1, 2}},
+		{name: "duplicates and unknown", ids: []int64{2, 2, 99, 0}, wantIDs: []uint64{2}},
+		{name: "hidden skipped", ids: []int64{1, 2}, hide: 1, wantIDs: []uint64{2}},
+		{name: "empty", ids: nil, wantIDs: []uint64{}},
+		{name: "too many", ids: tooMany, wantErr: ErrTooManyPostIDs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestPostEnv(t)
+			if tt.hide != 0 {
+				env.posts.SetPostStatus(tt.hide, model.PostStatusHidden)
+			}
+			list, err := env.logic.GetPostsByIDs(context.Background(), tt.ids)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetPostsByIDs() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := listIDs(&model.ApiPostDetailRes{List: list})
+			if !equalIDs(got, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", got, tt.wantIDs)
+			}
+			for _, d := range list {
+				if d.AuthorName != "alice" || d.CommunityDetailRes == nil || d.VoteNum != 1 {
+					t.Errorf("post %d not joined with author, community and votes: %+v", d.PostID, d)
+				}
+			}
+		})
+	}
+}
+
+func TestPostLogic_Vote(t *testing.T) {
+	tests := []struct {
+		name        string
+		postID      int64
+		direction   int64
+		setup       func(env *testPostEnv)
+		wantErr     error
+		wantVoteNum int64
+	}{
+		{name: "up vote", postID: 1, direction: 1, wantVoteNum: 2},
+		{name: "down vote", postID: 1, direction: -1, wantVoteNum: 1},
+		{
+			name: "repeated", postID: 1, direction: 1,
+			setup:   func(env *testPostEnv) { env.feed.CreatePostVote(1, 200, 1) },
+			wantErr: postredis.ErrVoteRepeated,
+		},
+		{
+			name: "cancel", postID: 1, direction: 0,
+			setup:       func(env *testPostEnv) { env.feed.CreatePostVote(1, 200, 1) },
+			wantVoteNum: 1,
+		},
+		{
+			name: "expired", postID: 1, direction: 1,
+			setup:   func(env *testPostEnv) { env.feed.SetPostTime(1, time.Now().Add(-8*24*time.Hour)) },
+			wantErr: postredis.ErrorVoteTimeExpire,
+		},
+		{
+			name: "in progress", postID: 1, direction: 1,
+			setup:   func(env *testPostEnv) { env.feed.AcquireLock(1, 200, time.Minute) },
+			wantErr: ErrVoteInProgress,
+		},
+		{
+			name: "kafka unavailable", postID: 1, direction: 1,
+			setup:   func(env *testPostEnv) { env.producer.Err = errors.New("kafka down") },
+			wantErr: errors.New("kafka down"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestPostEnv(t)
+			if tt.setup != nil {
+				tt.setup(env)
+			}
+			before := env.feed.Score(uint64(tt.postID))
+
+			err := env.logic.Vote(context.Background(), tt.postID, tt.direction, 200)
+			if tt.wantErr != nil {
+				if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+					t.Fatalf("Vote() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Vote() error = %v", err)
+			}
+
+			if n, _ := env.feed.GetPostVoteNum(tt.postID); n != tt.wantVoteNum {
+				t.Errorf("vote num = %d, want %d", n, tt.wantVoteNum)
+			}
+			if tt.direction != 0 {
+				if got, want := env.feed.Score(uint64(tt.postID))-before, postredis.VoteScore*float64(tt.direction); got != want {
+					t.Errorf("score change = %v, want %v", got, want)
+				}
+			}
+			if status, ok := env.feed.VoteStatus(tt.postID, 200); !ok || status != 0 {
+				t.Errorf("vote status = %d, %v, want 0", status, ok)
+			}
+			msgs := env.producer.Messages()
+			if len(msgs) != 1 || msgs[0].PostID != tt.postID || msgs[0].Direction != tt.direction {
+				t.Errorf("kafka messages = %+v", msgs)
+			}
+			published := env.events.Published()
+			if len(published) != 1 || published[0].Type != event.TypeVote || published[0].VoteNum != tt.wantVoteNum {
+				t.Errorf("published events = %+v", published)
+			}
+			// 锁已释放
+			if ok, _ := env.feed.AcquireLock(tt.postID, 200, time.Minute); !ok {
+				t.Errorf("vote lock not released")
+			}
+		})
+	}
+}
+
+func TestPostLogic_SubscribePost(t *testing.T) {
+	env := newTestPostEnv(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan *event.PostEvent, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- env.logic.SubscribePost(ctx, 1, func(ev *event.PostEvent) error {
+			received <- ev
+			return nil
+		})
+	}()
+
+	// 第一条为当前投票数快照
+	snapshot := <-received
+	if snapshot.Type != event.TypeVote || snapshot.VoteNum != 1 {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+
+	if err := env.logic.Vote(context.Background(), 1, 1, 200); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-received:
+		if ev.VoteNum != 2 {
+			t.Errorf("event = %+v, want vote num 2", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("vote event not forwarded")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("SubscribePost() error = %v", err)
+	}
+	if n := env.events.Subscribers(1); n != 0 {
+		t.Errorf("subscribers after cancel = %d, want 0", n)
+	}
+}
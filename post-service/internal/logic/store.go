@@ -0,0 +1,122 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"bluebell_microservices/common/pkg/event"
+	commonkafka "bluebell_microservices/common/pkg/kafka"
+	"bluebell_microservices/post-service/internal/model"
+)
+
+// 以下接口由 dao/mysql、dao/redis、kafka、client 包中的类型实现，dao/memory 提供测试用的内存实现
+
+// PostStore 帖子及社区存储
+type PostStore interface {
+	CreatePost(ctx context.Context, post *model.Post) error
+	// GetPostByID 帖子不存在时返回 sql.ErrNoRows
+	GetPostByID(id int64) (*model.Post, error)
+	// GetPostListByIDs 按 ids 的顺序返回帖子
+	GetPostListByIDs(ids []string) ([]*model.Post, error)
+	// SearchPosts 标题或内容匹配 search 的帖子，按创建时间倒序分页，同时返回匹配的总数；communityID 为 0 时不限社区
+	SearchPosts(search string, page, size int64, communityID int64) ([]string, int64, error)
+	GetCommunityByID(id uint64) (*model.CommunityDetailRes, error)
+	CommunityExists(id uint64) (bool, error)
+	// UpdateDraft 修改草稿的标题、内容、社区及定时发布时间；帖子已不是草稿时不修改并返回 false，内容未变化时返回 true
+	UpdateDraft(ctx context.Context, post *model.Post) (bool, error)
+	// PublishDraft 将草稿改为正常状态，发帖时间记为 publishTime；帖子已不是草稿时返回 false
+	PublishDraft(ctx context.Context, postID int64, publishTime time.Time) (bool, error)
+	// GetDueDrafts 定时发布时间不晚于 now 的草稿，按定时发布时间先后最多返回 limit 条
+	GetDueDrafts(ctx context.Context, now time.Time, limit int) ([]*model.Post, error)
+	// IncrCommentCount 增减 post 表中的评论数，不会减为负数
+	IncrCommentCount(ctx context.Context, postID, delta int64) error
+	SetCommentCount(ctx context.Context, postID, count int64) error
+}
+
+// UserStore 作者信息，由 user-service 提供
+type UserStore interface {
+	// GetUsersByIDs 批量查询作者，key 为用户 ID，不存在的用户不在结果中
+	GetUsersByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.User, error)
+}
+
+// VoteStore 投票记录及投票锁
+type VoteStore interface {
+	// CreatePostVote 记录投票并更新帖子分数，超过投票期限或重复投票时返回 redis 包中对应的错误
+	CreatePostVote(postID, userID, direction int64) error
+	// GetPostVoteNum 帖子的赞成票数
+	GetPostVoteNum(id int64) (int64, error)
+	// GetPostVoteData 按 ids 的顺序返回各帖子的赞成票数
+	GetPostVoteData(ids []string) ([]int64, error)
+	SetVoteStatus(postID, userID int64, status int64, expiration time.Duration) error
+	AcquireLock(postID, userID int64, expiration time.Duration) (bool, error)
+	ReleaseLock(postID, userID int64) error
+}
+
+// RankingIndex 帖子按时间、分数、评论数及活跃度排序的索引
+type RankingIndex interface {
+	// CreatePost 将新帖子加入索引，作者默认投赞成票
+	CreatePost(postID, authorID uint64, title, content string, communityID uint64) error
+	GetPostIDsInOrder(req *model.ParamPostList) ([]string, error)
+	GetCommunityPostIDsInOrder(p *model.ParamPostList) ([]string, error)
+	// CountPosts 按 order 排序的列表中的帖子数，communityID 为 0 时统计全站
+	CountPosts(order string, communityID int64) (int64, error)
+	RemovePostFromFeeds(postID, communityID uint64) error
+	// AddTrendingActivity 记录帖子的一次活跃，计入趋势排序；帖子已不在列表中时忽略
+	AddTrendingActivity(postID int64, weight float64) error
+}
+
+// CommentCountIndex 帖子评论数缓存（列表及详情的读取来源）及评论事件去重
+type CommentCountIndex interface {
+	// MarkCommentEvent 记录评论事件已处理，事件此前已处理过时返回 false
+	MarkCommentEvent(commentID int64, typ string) (bool, error)
+	UnmarkCommentEvent(commentID int64, typ string) error
+	// IncrPostComments 增减帖子评论数，不会减为负数
+	IncrPostComments(postID, delta int64) error
+	SetPostComments(postID, count int64) error
+	// GetPostCommentData 按 ids 的顺序返回各帖子的评论数
+	GetPostCommentData(ids []string) ([]int64, error)
+}
+
+// CommentCounter 评论数的权威来源，由 comment-service 提供
+type CommentCounter interface {
+	// GetCommentCounts 各帖子未删除的评论数，没有评论的帖子为 0
+	GetCommentCounts(ctx context.Context, postIDs []uint64) (map[uint64]int64, error)
+}
+
+// ModerationStore 举报、版主及社区封禁
+type ModerationStore interface {
+	CreateReport(ctx context.Context, report *model.Report) error
+	// GetReportByID 举报不存在时返回 mysql.ErrReportNotFound
+	GetReportByID(ctx context.Context, reportID uint64) (*model.Report, error)
+	ListReports(ctx context.Context, communityID uint64, status string, page, size int64) ([]*model.Report, int64, error)
+	IsModerator(ctx context.Context, communityID, userID uint64) (bool, error)
+	IsBanned(ctx context.Context, communityID, userID uint64) (bool, error)
+	// Apply 在同一事务中执行版主操作、将关联举报改为 reportStatus 并写入审计日志，
+	// 举报已被并发处理时回滚并返回 mysql.ErrReportClosed
+	Apply(ctx context.Context, p *model.ParamModerate, log *model.ModerationLog, reportStatus string) error
+}
+
+// EventBus 帖子实时事件的发布与订阅
+type EventBus interface {
+	PublishPostEvent(ev *event.PostEvent) error
+	// SubscribePostEvents 返回时订阅已生效，之后发布的事件不会丢失
+	SubscribePostEvents(postID int64) (event.Subscription, error)
+}
+
+// EventProducer 投票消息生产者，消息异步落库
+type EventProducer interface {
+	SendVoteMessage(ctx context.Context, message commonkafka.VoteMessage) error
+}
+
+// PostStores PostLogic 依赖的存储
+type PostStores struct {
+	Posts         PostStore
+	Users         UserStore
+	Votes         VoteStore
+	Ranking       RankingIndex
+	CommentCounts CommentCountIndex
+	Comments      CommentCounter
+	Moderation    ModerationStore
+	Events        EventBus
+	Producer      EventProducer
+}
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"time"
+
+	"bluebell_microservices/common/config"
+	"bluebell_microservices/common/pkg/validate"
+	"bluebell_microservices/post-service/internal/model"
+)
+
+// 与 post 表列定义保持一致
+const (
+	MaxPostTitleLen   = 128  // title varchar(128)
+	MaxPostContentLen = 8192 // content varchar(8192)
+)
+
+// validatePost 校验帖子标题、内容长度、屏蔽词、社区是否存在及定时发布时间
+func (l *PostLogic) validatePost(post *model.Post) error {
+	v := validate.New()
+	// 每次读取最新配置，屏蔽词修改后无需重启
+	wordFilter := validate.NewWordFilter(config.Get().BlockedWords())
+
+	if v.Required("title", post.Title) && v.MaxChars("title", post.Title, MaxPostTitleLen) {
+		v.NoBlockedWords("title", post.Title, wordFilter)
+	}
+	if v.Required("content", post.Content) && v.MaxChars("content", post.Content, MaxPostContentLen) {
+		v.NoBlockedWords("content", post.Content, wordFilter)
+	}
+
+	if post.CommunityID == 0 {
+		v.Add("community_id", validate.ReasonRequired, "不能为空")
+	} else {
+		exists, err := l.postDao.CommunityExists(post.CommunityID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			v.Add("community_id", validate.ReasonNotFound, "社区不存在")
+		}
+	}
+
+	// 只有草稿可以定时发布，且发布时间需在将来
+	if post.PublishTime != nil {
+		if post.Status != model.PostStatusDraft {
+			v.Add("publish_time", validate.ReasonInvalid, "只有草稿可以定时发布")
+		} else if !post.PublishTime.After(time.Now()) {
+			v.Add("publish_time", validate.ReasonInvalid, "定时发布时间需晚于当前时间")
+		}
+	}
+
+	return v.Err()
+}
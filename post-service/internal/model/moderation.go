@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// 帖子状态
+const (
+	PostStatusHidden = 0 // 已被版主隐藏
+	PostStatusNormal = 1 // 正常
+	PostStatusDraft  = 2 // 草稿，发布前不进入帖子列表
+)
+
+// 举报对象类型
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+)
+
+// 举报处理状态
+const (
+	ReportStatusOpen      = "open"      // 待处理
+	ReportStatusActioned  = "actioned"  // 已处理
+	ReportStatusDismissed = "dismissed" // 已驳回
+)
+
+// 版主操作
+const (
+	ModerationHidePost      = "hide_post"
+	ModerationRemoveComment = "remove_comment"
+	ModerationBanUser       = "ban_user"
+	ModerationDismiss       = "dismiss"
+)
+
+// Report 用户举报
+type Report struct {
+	ReportID       uint64    `json:"report_id,string" db:"report_id"`
+	TargetType     string    `json:"target_type" db:"target_type"`
+	TargetID       uint64    `json:"target_id,string" db:"target_id"`
+	PostID         uint64    `json:"post_id,string" db:"post_id"`
+	CommunityID    uint64    `json:"community_id" db:"community_id"`
+	TargetAuthorID uint64    `json:"target_author_id,string" db:"target_author_id"`
+	ReporterID     uint64    `json:"reporter_id,string" db:"reporter_id"`
+	Reason         string    `json:"reason" db:"reason"`
+	Status         string    `json:"status" db:"status"`
+	HandlerID      uint64    `json:"handler_id,string" db:"handler_id"`
+	CreateTime     time.Time `json:"-" db:"create_time"`
+	UpdateTime     time.Time `json:"-" db:"update_time"`
+}
+
+// ModerationLog 版主操作审计日志
+type ModerationLog struct {
+	ModeratorID uint64 `db:"moderator_id"`
+	CommunityID uint64 `db:"community_id"`
+	Action      string `db:"action"`
+	TargetType  string `db:"target_type"`
+	TargetID    uint64 `db:"target_id"`
+	ReportID    uint64 `db:"report_id"`
+	Reason      string `db:"reason"`
+}
+
+// ParamModerate 版主操作参数
+type ParamModerate struct {
+	ModeratorID   uint64
+	ModeratorRole string // 操作人的全站角色
+	CommunityID   uint64
+	Action        string
+	PostID        uint64 // hide_post / remove_comment 所属帖子
+	CommentID     uint64 // remove_comment 目标评论
+	UserID        uint64 // ban_user 目标用户
+	ReportID      uint64 // 关联举报（可选）
+	Reason        string
+	ValidateOnly  bool // 只校验操作能否执行，不执行
+}
+
+// CommunityRole 用户在社区中的身份
+type CommunityRole struct {
+	CommunityID uint64
+	IsModerator bool
+	IsBanned    bool
+}
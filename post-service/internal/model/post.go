@@ -2,11 +2,17 @@ package model
 
 import "time"
 
+// 帖子列表的排序方式
 const (
-	OrderTime  = "time"
-	OrderScore = "score"
+	OrderTime     = "time"     // 发帖时间
+	OrderScore    = "score"    // 投票分数
+	OrderComments = "comments" // 评论数
+	OrderTrending = "trending" // 最近一段时间内的投票及评论活跃度
 )
 
+// Orders 支持的全部排序方式
+var Orders = []string{OrderTime, OrderScore, OrderComments, OrderTrending}
+
 // ParamPostList 获取帖子列表query 参数
 type ParamPostList struct {
 	Search      string `json:"search" form:"search"`               // 关键字搜索
@@ -33,6 +39,8 @@ type Post struct {
 	Content     string    `json:"content" db:"content" binding:"required"`
 	CreateTime  time.Time `json:"-" db:"create_time"`
 	UpdateTime  time.Time `json:"-" db:"update_time"`
+	// PublishTime 草稿的定时发布时间，为空时需作者手动发布
+	PublishTime *time.Time `json:"publish_time,omitempty" db:"publish_time"`
 }
 
 // CommunityDetailRes 社区详情model
@@ -54,7 +62,8 @@ type ApiPostDetail struct {
 	*Post                                  // 嵌入帖子结构体
 	*CommunityDetailRes `json:"community"` // 嵌入社区信息
 	AuthorName          string             `json:"author_name"`
-	VoteNum             int64              `json:"vote_num"` // 投票数量
+	VoteNum             int64              `json:"vote_num"`      // 投票数量
+	CommentCount        int64              `json:"comment_count"` // 评论数量
 	//CommunityName string `json:"community_name"`
 }
 
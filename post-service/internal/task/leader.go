@@ -0,0 +1,115 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"bluebell_microservices/common/pkg/logger"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	"go.uber.org/zap"
+)
+
+const (
+	// electionPrefix etcd 中选举使用的 key 前缀，参数是选举名
+	electionPrefix = "/bluebell/election/"
+	// leaderTTL leader 与 etcd 失联后其他实例接替前等待的秒数
+	leaderTTL = 10
+	// campaignRetry 与 etcd 的会话出错后重新参加选举前的等待时间
+	campaignRetry = 5 * time.Second
+)
+
+// Leader 通过 etcd 选主，同名选举的多个实例中同时只有一个是 leader；leader 退出或与 etcd 失联后由其他实例接替
+// 失联的 leader 要在会话过期后才得知，交接期间可能有两个实例同时认为自己是 leader，任务需能容忍偶尔的重复执行
+type Leader struct {
+	cli    *clientv3.Client
+	name   string
+	id     string
+	leader atomic.Bool
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewLeader 创建名为 name 的选举，实例以 主机名-进程号 标识
+func NewLeader(cli *clientv3.Client, name string) *Leader {
+	host, _ := os.Hostname()
+	return &Leader{cli: cli, name: name, id: fmt.Sprintf("%s-%d", host, os.Getpid())}
+}
+
+// Start 在后台参加选举，失去 leader 身份后重新参加
+func (l *Leader) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		for {
+			if err := l.campaign(ctx); err != nil && ctx.Err() == nil {
+				logger.Warn("Leader election interrupted, retrying",
+					zap.String("election", l.name), zap.String("id", l.id), zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(campaignRetry):
+			}
+		}
+	}()
+}
+
+// campaign 参加一轮选举，成为 leader 后保持到会话失效或 ctx 取消
+func (l *Leader) campaign(ctx context.Context) error {
+	session, err := concurrency.NewSession(l.cli, concurrency.WithTTL(leaderTTL), concurrency.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	election := concurrency.NewElection(session, electionPrefix+l.name)
+	if err := election.Campaign(ctx, l.id); err != nil {
+		return err
+	}
+	l.leader.Store(true)
+	defer l.leader.Store(false)
+	logger.Info("Became leader", zap.String("election", l.name), zap.String("id", l.id))
+
+	select {
+	case <-ctx.Done():
+		// 主动让出，其他实例无需等待会话过期即可接替
+		resignCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		return election.Resign(resignCtx)
+	case <-session.Done():
+		return errors.New("etcd session expired")
+	}
+}
+
+// IsLeader 当前实例是否为 leader
+func (l *Leader) IsLeader() bool {
+	return l.leader.Load()
+}
+
+// Run 包装任务的执行函数，只在当前实例是 leader 时执行
+func (l *Leader) Run(run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if !l.IsLeader() {
+			return nil
+		}
+		return run(ctx)
+	}
+}
+
+// Stop 退出选举，当前实例是 leader 时让出
+func (l *Leader) Stop() error {
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.wg.Wait()
+	return nil
+}
@@ -0,0 +1,57 @@
+// Package task post-service 的后台定时任务
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"bluebell_microservices/common/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Task 按固定间隔执行的后台任务，执行失败只记录日志，下个间隔继续
+type Task struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// New 创建任务，run 收到的 ctx 在 Stop 时取消
+func New(name string, interval time.Duration, run func(ctx context.Context) error) *Task {
+	return &Task{name: name, interval: interval, run: run}
+}
+
+// Start 启动任务，首次执行在一个间隔之后
+func (t *Task) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := t.run(ctx); err != nil && ctx.Err() == nil {
+					logger.Error("Background task failed", zap.String("task", t.name), zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止任务并等待正在进行的执行结束
+func (t *Task) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
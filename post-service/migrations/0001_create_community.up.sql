@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `community` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `community_id` int UNSIGNED NOT NULL,
+    `community_name` varchar(128) NOT NULL,
+    `introduction` varchar(256) NOT NULL,
+    `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_community_id` (`community_id`),
+    UNIQUE KEY `idx_community_name` (`community_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- 初始社区，已存在时跳过
+INSERT IGNORE INTO `community` (`community_id`, `community_name`, `introduction`) VALUES
+    (1, 'Go', 'Golang'),
+    (2, 'Leetcode', '刷题刷题刷题'),
+    (3, 'Java', 'springboot'),
+    (4, 'LOL', '欢迎来到英雄联盟!');
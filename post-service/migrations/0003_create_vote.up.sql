@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `vote` (
+    `id` bigint NOT NULL AUTO_INCREMENT,
+    `post_id` bigint NOT NULL COMMENT '帖子id',
+    `user_id` bigint NOT NULL COMMENT '用户id',
+    `vote_type` tinyint NOT NULL COMMENT '投票类型：1-赞成，-1-反对',
+    `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_post_user` (`post_id`, `user_id`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
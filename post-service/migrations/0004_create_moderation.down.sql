@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `moderation_log`;
+DROP TABLE IF EXISTS `community_ban`;
+DROP TABLE IF EXISTS `community_moderator`;
+DROP TABLE IF EXISTS `report`;
@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS `report` (
+    `id` bigint NOT NULL AUTO_INCREMENT,
+    `report_id` bigint NOT NULL COMMENT '举报id',
+    `target_type` varchar(16) NOT NULL COMMENT '举报对象类型：post、comment',
+    `target_id` bigint NOT NULL COMMENT '举报对象id',
+    `post_id` bigint NOT NULL COMMENT '所属帖子id',
+    `community_id` bigint NOT NULL COMMENT '所属社区id',
+    `target_author_id` bigint NOT NULL DEFAULT 0 COMMENT '举报对象作者id',
+    `reporter_id` bigint NOT NULL COMMENT '举报人id',
+    `reason` varchar(512) NOT NULL DEFAULT '' COMMENT '举报原因',
+    `status` varchar(16) NOT NULL DEFAULT 'open' COMMENT '状态：open、actioned、dismissed',
+    `handler_id` bigint NOT NULL DEFAULT 0 COMMENT '处理人id',
+    `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_report_id` (`report_id`),
+    KEY `idx_community_status` (`community_id`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS `community_moderator` (
+    `id` bigint NOT NULL AUTO_INCREMENT,
+    `community_id` bigint NOT NULL COMMENT '社区id',
+    `user_id` bigint NOT NULL COMMENT '版主用户id',
+    `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_community_user` (`community_id`, `user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS `community_ban` (
+    `id` bigint NOT NULL AUTO_INCREMENT,
+    `community_id` bigint NOT NULL COMMENT '社区id',
+    `user_id` bigint NOT NULL COMMENT '被封禁用户id',
+    `moderator_id` bigint NOT NULL COMMENT '操作版主id',
+    `reason` varchar(512) NOT NULL DEFAULT '' COMMENT '封禁原因',
+    `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_community_user` (`community_id`, `user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS `moderation_log` (
+    `id` bigint NOT NULL AUTO_INCREMENT,
+    `moderator_id` bigint NOT NULL COMMENT '操作版主id',
+    `community_id` bigint NOT NULL COMMENT '社区id',
+    `action` varchar(32) NOT NULL COMMENT '操作：hide_post、remove_comment、ban_user、dismiss',
+    `target_type` varchar(16) NOT NULL COMMENT '操作对象类型',
+    `target_id` bigint NOT NULL COMMENT '操作对象id',
+    `report_id` bigint NOT NULL DEFAULT 0 COMMENT '关联举报id',
+    `reason` varchar(512) NOT NULL DEFAULT '' COMMENT '操作原因',
+    `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_community_id` (`community_id`),
+    KEY `idx_moderator_id` (`moderator_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
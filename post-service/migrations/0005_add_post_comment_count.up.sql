@@ -0,0 +1 @@
+ALTER TABLE `post` ADD COLUMN `comment_count` int NOT NULL DEFAULT 0 COMMENT '评论数（由评论事件维护，定期与 comment-service 校准）' AFTER `status`;
@@ -0,0 +1,4 @@
+ALTER TABLE `post`
+    MODIFY COLUMN `status` tinyint NOT NULL DEFAULT 1 COMMENT '帖子状态：1-正常，0-已隐藏，2-草稿',
+    ADD COLUMN `publish_time` timestamp NULL DEFAULT NULL COMMENT '草稿的定时发布时间，为空时需作者手动发布' AFTER `status`,
+    ADD KEY `idx_status_publish_time` (`status`, `publish_time`);
@@ -0,0 +1,9 @@
+// Package migrations post-service 的数据库迁移，只包含本服务拥有的表，由 common/pkg/migrate 执行
+package migrations
+
+import "embed"
+
+// FS 全部迁移文件
+//
+//go:embed *.sql
+var FS embed.FS
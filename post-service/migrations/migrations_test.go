@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"testing"
+
+	"bluebell_microservices/common/pkg/migrate"
+)
+
+func TestMigrationsLoad(t *testing.T) {
+	migrations, err := migrate.Load(FS)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s: versions should be consecutive from 1", m.Version, m.Name)
+		}
+	}
+}
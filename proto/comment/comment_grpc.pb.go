@@ -19,8 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CommentService_CreateComment_FullMethodName  = "/comment.CommentService/CreateComment"
-	CommentService_GetCommentList_FullMethodName = "/comment.CommentService/GetCommentList"
+	CommentService_CreateComment_FullMethodName    = "/comment.CommentService/CreateComment"
+	CommentService_GetCommentList_FullMethodName   = "/comment.CommentService/GetCommentList"
+	CommentService_GetComment_FullMethodName       = "/comment.CommentService/GetComment"
+	CommentService_GetCommentCounts_FullMethodName = "/comment.CommentService/GetCommentCounts"
+	CommentService_RemoveComment_FullMethodName    = "/comment.CommentService/RemoveComment"
 )
 
 // CommentServiceClient is the client API for CommentService service.
@@ -33,6 +36,12 @@ type CommentServiceClient interface {
 	CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*CreateCommentResponse, error)
 	// 获取评论列表
 	GetCommentList(ctx context.Context, in *GetCommentListRequest, opts ...grpc.CallOption) (*GetCommentListResponse, error)
+	// 获取单条评论
+	GetComment(ctx context.Context, in *GetCommentRequest, opts ...grpc.CallOption) (*GetCommentResponse, error)
+	// 批量查询帖子的评论数
+	GetCommentCounts(ctx context.Context, in *GetCommentCountsRequest, opts ...grpc.CallOption) (*GetCommentCountsResponse, error)
+	// 删除评论（版主操作，软删除）
+	RemoveComment(ctx context.Context, in *RemoveCommentRequest, opts ...grpc.CallOption) (*RemoveCommentResponse, error)
 }
 
 type commentServiceClient struct {
@@ -63,6 +72,36 @@ func (c *commentServiceClient) GetCommentList(ctx context.Context, in *GetCommen
 	return out, nil
 }
 
+func (c *commentServiceClient) GetComment(ctx context.Context, in *GetCommentRequest, opts ...grpc.CallOption) (*GetCommentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCommentResponse)
+	err := c.cc.Invoke(ctx, CommentService_GetComment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commentServiceClient) GetCommentCounts(ctx context.Context, in *GetCommentCountsRequest, opts ...grpc.CallOption) (*GetCommentCountsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCommentCountsResponse)
+	err := c.cc.Invoke(ctx, CommentService_GetCommentCounts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commentServiceClient) RemoveComment(ctx context.Context, in *RemoveCommentRequest, opts ...grpc.CallOption) (*RemoveCommentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveCommentResponse)
+	err := c.cc.Invoke(ctx, CommentService_RemoveComment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CommentServiceServer is the server API for CommentService service.
 // All implementations must embed UnimplementedCommentServiceServer
 // for forward compatibility.
@@ -73,6 +112,12 @@ type CommentServiceServer interface {
 	CreateComment(context.Context, *CreateCommentRequest) (*CreateCommentResponse, error)
 	// 获取评论列表
 	GetCommentList(context.Context, *GetCommentListRequest) (*GetCommentListResponse, error)
+	// 获取单条评论
+	GetComment(context.Context, *GetCommentRequest) (*GetCommentResponse, error)
+	// 批量查询帖子的评论数
+	GetCommentCounts(context.Context, *GetCommentCountsRequest) (*GetCommentCountsResponse, error)
+	// 删除评论（版主操作，软删除）
+	RemoveComment(context.Context, *RemoveCommentRequest) (*RemoveCommentResponse, error)
 	mustEmbedUnimplementedCommentServiceServer()
 }
 
@@ -89,6 +134,15 @@ func (UnimplementedCommentServiceServer) CreateComment(context.Context, *CreateC
 func (UnimplementedCommentServiceServer) GetCommentList(context.Context, *GetCommentListRequest) (*GetCommentListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCommentList not implemented")
 }
+func (UnimplementedCommentServiceServer) GetComment(context.Context, *GetCommentRequest) (*GetCommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetComment not implemented")
+}
+func (UnimplementedCommentServiceServer) GetCommentCounts(context.Context, *GetCommentCountsRequest) (*GetCommentCountsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommentCounts not implemented")
+}
+func (UnimplementedCommentServiceServer) RemoveComment(context.Context, *RemoveCommentRequest) (*RemoveCommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveComment not implemented")
+}
 func (UnimplementedCommentServiceServer) mustEmbedUnimplementedCommentServiceServer() {}
 func (UnimplementedCommentServiceServer) testEmbeddedByValue()                        {}
 
@@ -146,6 +200,60 @@ func _CommentService_GetCommentList_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CommentService_GetComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentServiceServer).GetComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommentService_GetComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentServiceServer).GetComment(ctx, req.(*GetCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommentService_GetCommentCounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCommentCountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentServiceServer).GetCommentCounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommentService_GetCommentCounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentServiceServer).GetCommentCounts(ctx, req.(*GetCommentCountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommentService_RemoveComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentServiceServer).RemoveComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommentService_RemoveComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentServiceServer).RemoveComment(ctx, req.(*RemoveCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CommentService_ServiceDesc is the grpc.ServiceDesc for CommentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -161,6 +269,18 @@ var CommentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCommentList",
 			Handler:    _CommentService_GetCommentList_Handler,
 		},
+		{
+			MethodName: "GetComment",
+			Handler:    _CommentService_GetComment_Handler,
+		},
+		{
+			MethodName: "GetCommentCounts",
+			Handler:    _CommentService_GetCommentCounts_Handler,
+		},
+		{
+			MethodName: "RemoveComment",
+			Handler:    _CommentService_RemoveComment_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/comment/comment.proto",
@@ -19,11 +19,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PostService_CreatePost_FullMethodName  = "/post.PostService/CreatePost"
-	PostService_GetPostList_FullMethodName = "/post.PostService/GetPostList"
-	PostService_GetPostById_FullMethodName = "/post.PostService/GetPostById"
-	PostService_SearchPosts_FullMethodName = "/post.PostService/SearchPosts"
-	PostService_Vote_FullMethodName        = "/post.PostService/Vote"
+	PostService_CreatePost_FullMethodName       = "/post.PostService/CreatePost"
+	PostService_UpdateDraft_FullMethodName      = "/post.PostService/UpdateDraft"
+	PostService_PublishDraft_FullMethodName     = "/post.PostService/PublishDraft"
+	PostService_GetPostList_FullMethodName      = "/post.PostService/GetPostList"
+	PostService_GetPostById_FullMethodName      = "/post.PostService/GetPostById"
+	PostService_GetPostsByIDs_FullMethodName    = "/post.PostService/GetPostsByIDs"
+	PostService_SearchPosts_FullMethodName      = "/post.PostService/SearchPosts"
+	PostService_Vote_FullMethodName             = "/post.PostService/Vote"
+	PostService_SubscribePost_FullMethodName    = "/post.PostService/SubscribePost"
+	PostService_ReportPost_FullMethodName       = "/post.PostService/ReportPost"
+	PostService_ReportComment_FullMethodName    = "/post.PostService/ReportComment"
+	PostService_ListReports_FullMethodName      = "/post.PostService/ListReports"
+	PostService_GetReport_FullMethodName        = "/post.PostService/GetReport"
+	PostService_Moderate_FullMethodName         = "/post.PostService/Moderate"
+	PostService_GetCommunityRole_FullMethodName = "/post.PostService/GetCommunityRole"
 )
 
 // PostServiceClient is the client API for PostService service.
@@ -32,16 +42,36 @@ const (
 //
 // PostService 定义帖子服务
 type PostServiceClient interface {
-	// 创建帖子
+	// 创建帖子（draft 为 true 或设置了定时发布时间时保存为草稿）
 	CreatePost(ctx context.Context, in *CreatePostRequest, opts ...grpc.CallOption) (*CreatePostResponse, error)
+	// 修改草稿
+	UpdateDraft(ctx context.Context, in *UpdateDraftRequest, opts ...grpc.CallOption) (*UpdateDraftResponse, error)
+	// 立即发布草稿
+	PublishDraft(ctx context.Context, in *PublishDraftRequest, opts ...grpc.CallOption) (*PublishDraftResponse, error)
 	// 获取帖子列表（支持按社区 ID、时间或分数排序）
 	GetPostList(ctx context.Context, in *GetPostListRequest, opts ...grpc.CallOption) (*GetPostListResponse, error)
 	// 根据帖子 ID 获取详情
 	GetPostById(ctx context.Context, in *GetPostByIdRequest, opts ...grpc.CallOption) (*GetPostByIdResponse, error)
+	// 按帖子 ID 批量获取详情，用于其他服务或 BFF 组装列表
+	GetPostsByIDs(ctx context.Context, in *GetPostsByIDsRequest, opts ...grpc.CallOption) (*GetPostsByIDsResponse, error)
 	// 搜索帖子
 	SearchPosts(ctx context.Context, in *SearchPostsRequest, opts ...grpc.CallOption) (*SearchPostsResponse, error)
 	// 投票
 	Vote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*VoteResponse, error)
+	// 订阅帖子实时事件（新评论、投票总数）
+	SubscribePost(ctx context.Context, in *SubscribePostRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PostEvent], error)
+	// 举报帖子
+	ReportPost(ctx context.Context, in *ReportPostRequest, opts ...grpc.CallOption) (*ReportResponse, error)
+	// 举报评论
+	ReportComment(ctx context.Context, in *ReportCommentRequest, opts ...grpc.CallOption) (*ReportResponse, error)
+	// 查询社区举报队列
+	ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ListReportsResponse, error)
+	// 查询单个举报
+	GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (*GetReportResponse, error)
+	// 版主操作（隐藏帖子、删除评论、封禁用户、驳回举报）
+	Moderate(ctx context.Context, in *ModerateRequest, opts ...grpc.CallOption) (*ModerateResponse, error)
+	// 查询用户在社区中的身份（是否版主、是否被封禁）
+	GetCommunityRole(ctx context.Context, in *GetCommunityRoleRequest, opts ...grpc.CallOption) (*GetCommunityRoleResponse, error)
 }
 
 type postServiceClient struct {
@@ -62,6 +92,26 @@ func (c *postServiceClient) CreatePost(ctx context.Context, in *CreatePostReques
 	return out, nil
 }
 
+func (c *postServiceClient) UpdateDraft(ctx context.Context, in *UpdateDraftRequest, opts ...grpc.CallOption) (*UpdateDraftResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateDraftResponse)
+	err := c.cc.Invoke(ctx, PostService_UpdateDraft_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) PublishDraft(ctx context.Context, in *PublishDraftRequest, opts ...grpc.CallOption) (*PublishDraftResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishDraftResponse)
+	err := c.cc.Invoke(ctx, PostService_PublishDraft_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *postServiceClient) GetPostList(ctx context.Context, in *GetPostListRequest, opts ...grpc.CallOption) (*GetPostListResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetPostListResponse)
@@ -82,6 +132,16 @@ func (c *postServiceClient) GetPostById(ctx context.Context, in *GetPostByIdRequ
 	return out, nil
 }
 
+func (c *postServiceClient) GetPostsByIDs(ctx context.Context, in *GetPostsByIDsRequest, opts ...grpc.CallOption) (*GetPostsByIDsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPostsByIDsResponse)
+	err := c.cc.Invoke(ctx, PostService_GetPostsByIDs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *postServiceClient) SearchPosts(ctx context.Context, in *SearchPostsRequest, opts ...grpc.CallOption) (*SearchPostsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SearchPostsResponse)
@@ -102,22 +162,121 @@ func (c *postServiceClient) Vote(ctx context.Context, in *VoteRequest, opts ...g
 	return out, nil
 }
 
+func (c *postServiceClient) SubscribePost(ctx context.Context, in *SubscribePostRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PostEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PostService_ServiceDesc.Streams[0], PostService_SubscribePost_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribePostRequest, PostEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PostService_SubscribePostClient = grpc.ServerStreamingClient[PostEvent]
+
+func (c *postServiceClient) ReportPost(ctx context.Context, in *ReportPostRequest, opts ...grpc.CallOption) (*ReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportResponse)
+	err := c.cc.Invoke(ctx, PostService_ReportPost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) ReportComment(ctx context.Context, in *ReportCommentRequest, opts ...grpc.CallOption) (*ReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportResponse)
+	err := c.cc.Invoke(ctx, PostService_ReportComment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ListReportsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReportsResponse)
+	err := c.cc.Invoke(ctx, PostService_ListReports_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (*GetReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetReportResponse)
+	err := c.cc.Invoke(ctx, PostService_GetReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) Moderate(ctx context.Context, in *ModerateRequest, opts ...grpc.CallOption) (*ModerateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModerateResponse)
+	err := c.cc.Invoke(ctx, PostService_Moderate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) GetCommunityRole(ctx context.Context, in *GetCommunityRoleRequest, opts ...grpc.CallOption) (*GetCommunityRoleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCommunityRoleResponse)
+	err := c.cc.Invoke(ctx, PostService_GetCommunityRole_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PostServiceServer is the server API for PostService service.
 // All implementations must embed UnimplementedPostServiceServer
 // for forward compatibility.
 //
 // PostService 定义帖子服务
 type PostServiceServer interface {
-	// 创建帖子
+	// 创建帖子（draft 为 true 或设置了定时发布时间时保存为草稿）
 	CreatePost(context.Context, *CreatePostRequest) (*CreatePostResponse, error)
+	// 修改草稿
+	UpdateDraft(context.Context, *UpdateDraftRequest) (*UpdateDraftResponse, error)
+	// 立即发布草稿
+	PublishDraft(context.Context, *PublishDraftRequest) (*PublishDraftResponse, error)
 	// 获取帖子列表（支持按社区 ID、时间或分数排序）
 	GetPostList(context.Context, *GetPostListRequest) (*GetPostListResponse, error)
 	// 根据帖子 ID 获取详情
 	GetPostById(context.Context, *GetPostByIdRequest) (*GetPostByIdResponse, error)
+	// 按帖子 ID 批量获取详情，用于其他服务或 BFF 组装列表
+	GetPostsByIDs(context.Context, *GetPostsByIDsRequest) (*GetPostsByIDsResponse, error)
 	// 搜索帖子
 	SearchPosts(context.Context, *SearchPostsRequest) (*SearchPostsResponse, error)
 	// 投票
 	Vote(context.Context, *VoteRequest) (*VoteResponse, error)
+	// 订阅帖子实时事件（新评论、投票总数）
+	SubscribePost(*SubscribePostRequest, grpc.ServerStreamingServer[PostEvent]) error
+	// 举报帖子
+	ReportPost(context.Context, *ReportPostRequest) (*ReportResponse, error)
+	// 举报评论
+	ReportComment(context.Context, *ReportCommentRequest) (*ReportResponse, error)
+	// 查询社区举报队列
+	ListReports(context.Context, *ListReportsRequest) (*ListReportsResponse, error)
+	// 查询单个举报
+	GetReport(context.Context, *GetReportRequest) (*GetReportResponse, error)
+	// 版主操作（隐藏帖子、删除评论、封禁用户、驳回举报）
+	Moderate(context.Context, *ModerateRequest) (*ModerateResponse, error)
+	// 查询用户在社区中的身份（是否版主、是否被封禁）
+	GetCommunityRole(context.Context, *GetCommunityRoleRequest) (*GetCommunityRoleResponse, error)
 	mustEmbedUnimplementedPostServiceServer()
 }
 
@@ -131,18 +290,48 @@ type UnimplementedPostServiceServer struct{}
 func (UnimplementedPostServiceServer) CreatePost(context.Context, *CreatePostRequest) (*CreatePostResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreatePost not implemented")
 }
+func (UnimplementedPostServiceServer) UpdateDraft(context.Context, *UpdateDraftRequest) (*UpdateDraftResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateDraft not implemented")
+}
+func (UnimplementedPostServiceServer) PublishDraft(context.Context, *PublishDraftRequest) (*PublishDraftResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishDraft not implemented")
+}
 func (UnimplementedPostServiceServer) GetPostList(context.Context, *GetPostListRequest) (*GetPostListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPostList not implemented")
 }
 func (UnimplementedPostServiceServer) GetPostById(context.Context, *GetPostByIdRequest) (*GetPostByIdResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPostById not implemented")
 }
+func (UnimplementedPostServiceServer) GetPostsByIDs(context.Context, *GetPostsByIDsRequest) (*GetPostsByIDsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPostsByIDs not implemented")
+}
 func (UnimplementedPostServiceServer) SearchPosts(context.Context, *SearchPostsRequest) (*SearchPostsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchPosts not implemented")
 }
 func (UnimplementedPostServiceServer) Vote(context.Context, *VoteRequest) (*VoteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Vote not implemented")
 }
+func (UnimplementedPostServiceServer) SubscribePost(*SubscribePostRequest, grpc.ServerStreamingServer[PostEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePost not implemented")
+}
+func (UnimplementedPostServiceServer) ReportPost(context.Context, *ReportPostRequest) (*ReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportPost not implemented")
+}
+func (UnimplementedPostServiceServer) ReportComment(context.Context, *ReportCommentRequest) (*ReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportComment not implemented")
+}
+func (UnimplementedPostServiceServer) ListReports(context.Context, *ListReportsRequest) (*ListReportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReports not implemented")
+}
+func (UnimplementedPostServiceServer) GetReport(context.Context, *GetReportRequest) (*GetReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReport not implemented")
+}
+func (UnimplementedPostServiceServer) Moderate(context.Context, *ModerateRequest) (*ModerateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Moderate not implemented")
+}
+func (UnimplementedPostServiceServer) GetCommunityRole(context.Context, *GetCommunityRoleRequest) (*GetCommunityRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommunityRole not implemented")
+}
 func (UnimplementedPostServiceServer) mustEmbedUnimplementedPostServiceServer() {}
 func (UnimplementedPostServiceServer) testEmbeddedByValue()                     {}
 
@@ -182,6 +371,42 @@ func _PostService_CreatePost_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostService_UpdateDraft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDraftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).UpdateDraft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_UpdateDraft_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).UpdateDraft(ctx, req.(*UpdateDraftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_PublishDraft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishDraftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).PublishDraft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_PublishDraft_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).PublishDraft(ctx, req.(*PublishDraftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PostService_GetPostList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetPostListRequest)
 	if err := dec(in); err != nil {
@@ -218,6 +443,24 @@ func _PostService_GetPostById_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostService_GetPostsByIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPostsByIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).GetPostsByIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_GetPostsByIDs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).GetPostsByIDs(ctx, req.(*GetPostsByIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PostService_SearchPosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SearchPostsRequest)
 	if err := dec(in); err != nil {
@@ -254,6 +497,125 @@ func _PostService_Vote_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostService_SubscribePost_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribePostRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PostServiceServer).SubscribePost(m, &grpc.GenericServerStream[SubscribePostRequest, PostEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PostService_SubscribePostServer = grpc.ServerStreamingServer[PostEvent]
+
+func _PostService_ReportPost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportPostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).ReportPost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_ReportPost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).ReportPost(ctx, req.(*ReportPostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_ReportComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).ReportComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_ReportComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).ReportComment(ctx, req.(*ReportCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_ListReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).ListReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_ListReports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).ListReports(ctx, req.(*ListReportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_GetReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).GetReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_GetReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).GetReport(ctx, req.(*GetReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_Moderate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).Moderate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_Moderate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).Moderate(ctx, req.(*ModerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_GetCommunityRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCommunityRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).GetCommunityRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_GetCommunityRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).GetCommunityRole(ctx, req.(*GetCommunityRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PostService_ServiceDesc is the grpc.ServiceDesc for PostService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -265,6 +627,14 @@ var PostService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreatePost",
 			Handler:    _PostService_CreatePost_Handler,
 		},
+		{
+			MethodName: "UpdateDraft",
+			Handler:    _PostService_UpdateDraft_Handler,
+		},
+		{
+			MethodName: "PublishDraft",
+			Handler:    _PostService_PublishDraft_Handler,
+		},
 		{
 			MethodName: "GetPostList",
 			Handler:    _PostService_GetPostList_Handler,
@@ -273,6 +643,10 @@ var PostService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPostById",
 			Handler:    _PostService_GetPostById_Handler,
 		},
+		{
+			MethodName: "GetPostsByIDs",
+			Handler:    _PostService_GetPostsByIDs_Handler,
+		},
 		{
 			MethodName: "SearchPosts",
 			Handler:    _PostService_SearchPosts_Handler,
@@ -281,7 +655,37 @@ var PostService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Vote",
 			Handler:    _PostService_Vote_Handler,
 		},
+		{
+			MethodName: "ReportPost",
+			Handler:    _PostService_ReportPost_Handler,
+		},
+		{
+			MethodName: "ReportComment",
+			Handler:    _PostService_ReportComment_Handler,
+		},
+		{
+			MethodName: "ListReports",
+			Handler:    _PostService_ListReports_Handler,
+		},
+		{
+			MethodName: "GetReport",
+			Handler:    _PostService_GetReport_Handler,
+		},
+		{
+			MethodName: "Moderate",
+			Handler:    _PostService_Moderate_Handler,
+		},
+		{
+			MethodName: "GetCommunityRole",
+			Handler:    _PostService_GetCommunityRole_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribePost",
+			Handler:       _PostService_SubscribePost_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/post/post.proto",
 }
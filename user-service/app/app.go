@@ -0,0 +1,45 @@
+// Package app 组装 user-service 的 gRPC 服务，供 cmd/server 及端到端测试在进程内启动
+package app
+
+import (
+	"bluebell_microservices/common/pkg/rbac"
+	pb "bluebell_microservices/proto/user"
+	"bluebell_microservices/user-service/internal/controller"
+	"bluebell_microservices/user-service/internal/dao/memory"
+	"bluebell_microservices/user-service/internal/dao/mysql"
+	"bluebell_microservices/user-service/internal/logic"
+
+	"google.golang.org/grpc"
+)
+
+// ServerOptions user-service 自身的 gRPC 选项
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(rbac.UnaryServerInterceptor(controller.AccessRules)), // 敏感 RPC 鉴权
+	}
+}
+
+// Register 注册使用 MySQL 存储的 UserService，需先初始化 mysql
+func Register(s *grpc.Server) {
+	pb.RegisterUserServiceServer(s, controller.NewUserController(logic.NewUserLogic(mysql.NewUserDAO())))
+}
+
+// Memory 使用内存存储的 user-service
+type Memory struct {
+	users *memory.UserStore
+}
+
+// NewMemory 创建没有用户的内存版 user-service
+func NewMemory() *Memory {
+	return &Memory{users: memory.NewUserStore()}
+}
+
+// Register 注册 UserService
+func (m *Memory) Register(s *grpc.Server) {
+	pb.RegisterUserServiceServer(s, controller.NewUserController(logic.NewUserLogic(m.users)))
+}
+
+// SetRole 直接修改用户角色，用于准备管理员等测试数据
+func (m *Memory) SetRole(userID uint64, role string) error {
+	return m.users.UpdateRole(userID, role)
+}
@@ -6,118 +6,93 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"net"
-	"time"
+	"os"
 
 	"bluebell_microservices/common/config"
-	"bluebell_microservices/common/pkg/logger"
-	"bluebell_microservices/common/pkg/snowflake"
-	pb "bluebell_microservices/proto/user"
-	"bluebell_microservices/user-service/internal/controller"
+	"bluebell_microservices/common/pkg/metrics"
+	"bluebell_microservices/common/pkg/migrate"
+	"bluebell_microservices/common/pkg/rbac"
+	"bluebell_microservices/common/pkg/server"
+	"bluebell_microservices/user-service/app"
 	"bluebell_microservices/user-service/internal/dao/mysql"
-
-	clientv3 "go.etcd.io/etcd/client/v3"
-	"go.uber.org/zap"
-	"google.golang.org/grpc"
-
-	// 添加这行
-	"google.golang.org/grpc/reflection"
+	"bluebell_microservices/user-service/migrations"
 )
 
-type UserServer struct {
-	pb.UnimplementedUserServiceServer
-}
-
 func main() {
 	flag.Parse()
-
-	// 初始化日志
-	if err := logger.Init("info", "user-service.log"); err != nil {
-		log.Fatalf("Failed to init logger: %v", err)
-	}
-	defer logger.Logger.Sync() // 确保日志在程序退出时写入
-
-	// 初始化配置
-	config.InitConfig()
-
-	// 初始化雪花算法
-	if err := snowflake.Init(1); err != nil {
-		log.Fatalf("init snowflake failed, err:%v\n", err)
+	if flag.Arg(0) == "migrate" {
+		runMigrate(flag.Args()[1:])
+		return
 	}
-
-	// 初始化数据库连接
-	if err := mysql.Init(config.Conf.MySQL); err != nil {
-		log.Fatalf("init mysql failed, err:%v\n", err)
+	if flag.Arg(0) == "set-role" {
+		runSetRole(flag.Args()[1:])
+		return
 	}
-	defer mysql.Close()
 
-	// 初始化 etcd 客户端
-	etcdEndpoints := []string{"etcd-container:2379"}
-	cli, err := clientv3.New(clientv3.Config{
-		Endpoints:   etcdEndpoints,
-		DialTimeout: 5 * time.Second,
-	})
+	// 初始化日志、配置及雪花算法
+	srv, err := server.New("user", app.ServerOptions()...)
 	if err != nil {
-		log.Fatalf("连接 etcd 失败: %v", err)
-	}
-	defer cli.Close() // 服务退出时关闭
-
-	// 服务注册
-	if err := registerService(cli, "user", "user-service:8081"); err != nil {
-		logger.Error("Failed to register service", zap.Error(err))
-		log.Fatalf("failed to register service: %v", err)
+		log.Fatalf("init user service failed, err:%v\n", err)
 	}
 
-	// 监听端口
-	lis, err := net.Listen("tcp", ":8081")
-	if err != nil {
-		logger.Error("Failed to listen", zap.Error(err))
-		log.Fatalf("failed to listen: %v", err)
+	// 初始化数据库连接
+	if err := srv.Use(server.Component{
+		Name: "mysql",
+		Init: func(conf *config.Config) error {
+			if err := mysql.Init(conf.MySQL); err != nil {
+				return err
+			}
+			// 按需执行迁移，表结构版本与代码不一致时拒绝启动
+			if err := migrate.Ensure(context.Background(), mysql.DB(), "user", migrations.FS, conf.MySQL.AutoMigrate); err != nil {
+				return err
+			}
+			return metrics.RegisterDBStats("user", mysql.DB())
+		},
+		Close: func() error { mysql.Close(); return nil },
+		Check: mysql.Ping,
+	}); err != nil {
+		srv.Close()
+		log.Fatalf("init user service failed, err:%v\n", err)
 	}
-	defer lis.Close()
-
-	// 创建 gRPC 服务器
-	s := grpc.NewServer()
 
 	// 注册微服务
-	pb.RegisterUserServiceServer(s, controller.NewUserController()) // 在这里，实现proto文件中定义的接口 UnimplementedUserServiceServer
+	app.Register(srv.GRPC())
 
-	// 注册反射服务
-	reflection.Register(s) // 添加这行
-
-	logger.Info("User service running", zap.String("addr", ":8081"))
-	if err := s.Serve(lis); err != nil {
+	if err := srv.Run(); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
 
-func registerService(cli *clientv3.Client, serviceName, address string) error {
-	// 创建租约
-	leaseResp, err := cli.Grant(context.Background(), 10)
-	if err != nil {
-		return fmt.Errorf("创建租约失败: %v", err)
+// initMySQL 子命令只需要 MySQL 配置，不启动服务
+func initMySQL() {
+	if err := config.InitConfig("user"); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := mysql.Init(config.Conf.MySQL); err != nil {
+		log.Fatalf("init mysql failed, err:%v\n", err)
+	}
+}
 
-	// 注册服务
-	key := fmt.Sprintf("/services/%s", serviceName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	_, err = cli.Put(ctx, key, address, clientv3.WithLease(leaseResp.ID))
-	cancel()
-	if err != nil {
-		return fmt.Errorf("注册服务失败: %v", err)
+// runMigrate 执行 migrate 子命令后退出
+func runMigrate(args []string) {
+	initMySQL()
+	defer mysql.Close()
+
+	if err := migrate.Command(context.Background(), mysql.DB(), "user", migrations.FS, args, os.Stdout); err != nil {
+		log.Fatalf("migrate failed: %v", err)
 	}
-	fmt.Printf("服务 %s 注册成功，地址: %s\n", serviceName, address)
+}
 
-	// 续约
-	keepAliveChan, err := cli.KeepAlive(context.Background(), leaseResp.ID)
-	if err != nil {
-		return fmt.Errorf("续约失败: %v", err)
+// runSetRole 执行 set-role <用户名> <角色> 子命令后退出，用于在没有管理员时指定第一个管理员
+func runSetRole(args []string) {
+	if len(args) != 2 || !rbac.Valid(args[1]) {
+		log.Fatalf("usage: set-role <username> <user|moderator|admin|banned>")
 	}
-	go func() {
-		for range keepAliveChan {
-		}
-		fmt.Println("续约结束，租约已失效")
-	}()
+	initMySQL()
+	defer mysql.Close()
 
-	return nil
+	if err := mysql.NewUserDAO().UpdateRoleByUsername(args[0], args[1]); err != nil {
+		log.Fatalf("set role failed: %v", err)
+	}
+	fmt.Printf("user %s is now %s\n", args[0], args[1])
 }
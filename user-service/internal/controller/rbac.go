@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"bluebell_microservices/common/pkg/rbac"
+	pb "bluebell_microservices/proto/user"
+)
+
+// AccessRules user-service 中需要鉴权的 RPC
+var AccessRules = map[string]rbac.Rule{
+	pb.UserService_SetUserRole_FullMethodName: {
+		Permission: rbac.PermUserManage,
+		Subject:    func(req interface{}) uint64 { return req.(*pb.SetUserRoleRequest).OperatorId },
+	},
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 
+	"bluebell_microservices/common/pkg/errcode"
 	"bluebell_microservices/common/pkg/logger"
 	pb "bluebell_microservices/proto/user"
 	"bluebell_microservices/user-service/internal/logic"
@@ -17,28 +18,19 @@ type UserController struct {
 	userLogic *logic.UserLogic
 }
 
-func NewUserController() *UserController {
+func NewUserController(userLogic *logic.UserLogic) *UserController {
 	return &UserController{
-		userLogic: logic.NewUserLogic(),
+		userLogic: userLogic,
 	}
 }
 
 func (c *UserController) SignUp(ctx context.Context, req *pb.SignUpRequest) (*pb.SignUpResponse, error) {
 
 	// req 直接包含了所有参数，不需要从 context 中获取
-	if req.Password != req.ConfirmPassword {
-		return &pb.SignUpResponse{
-			Code: 2,
-			Msg:  "两次密码不一致",
-		}, nil
-	}
-
 	// 实现注册逻辑
 	if err := c.userLogic.SignUp(ctx, req); err != nil {
-		return &pb.SignUpResponse{
-			Code: 1,
-			Msg:  "注册失败",
-		}, nil
+		logger.Ctx(ctx).Warn("SignUp failed", zap.String("username", req.Username), zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 
 	return &pb.SignUpResponse{
@@ -54,10 +46,8 @@ func (c *UserController) Login(ctx context.Context, req *pb.LoginRequest) (*pb.L
 	// 调用逻辑层
 	user, err := c.userLogic.Login(ctx, req)
 	if err != nil {
-		return &pb.LoginResponse{
-			Code: 1,
-			Msg:  "登陆失败",
-		}, nil
+		logger.Ctx(ctx).Warn("Login failed", zap.String("username", req.Username), zap.Error(err))
+		return nil, errcode.ToStatus(err)
 	}
 
 	// 构造 gRPC 响应
@@ -68,19 +58,49 @@ func (c *UserController) Login(ctx context.Context, req *pb.LoginRequest) (*pb.L
 		Username:     user.Username,
 		AccessToken:  user.AccessToken,
 		RefreshToken: user.RefreshToken,
+		Role:         user.Role,
 	}, nil
 }
 
 func (c *UserController) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
 
-	traceID, _ := ctx.Value("trace_id").(string)
-	logger.Info("RefreshToken request received", zap.String("trace_id", traceID))
+	logger.Ctx(ctx).Info("RefreshToken request received")
 
 	resp, err := c.userLogic.RefreshToken(ctx, req)
 	if err != nil {
-		logger.Error("RefreshToken failed in controller", zap.String("trace_id", traceID), zap.Error(err))
-		return nil, err // 返回 gRPC 错误
+		logger.Ctx(ctx).Error("RefreshToken failed in controller", zap.Error(err))
+		return nil, errcode.ToStatus(err) // 返回 gRPC 错误
 	}
 
 	return resp, nil
 }
+
+func (c *UserController) SetUserRole(ctx context.Context, req *pb.SetUserRoleRequest) (*pb.SetUserRoleResponse, error) {
+	if err := c.userLogic.SetUserRole(ctx, req); err != nil {
+		logger.Ctx(ctx).Warn("SetUserRole failed", zap.Uint64("user_id", req.UserId), zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+
+	return &pb.SetUserRoleResponse{
+		Code: 0,
+		Msg:  "success",
+	}, nil
+}
+
+func (c *UserController) GetUsersByIDs(ctx context.Context, req *pb.GetUsersByIDsRequest) (*pb.GetUsersByIDsResponse, error) {
+	users, err := c.userLogic.GetUsersByIDs(ctx, req.UserIds)
+	if err != nil {
+		logger.Ctx(ctx).Warn("GetUsersByIDs failed", zap.Int("count", len(req.UserIds)), zap.Error(err))
+		return nil, errcode.ToStatus(err)
+	}
+
+	profiles := make([]*pb.UserProfile, 0, len(users))
+	for _, u := range users {
+		profiles = append(profiles, &pb.UserProfile{UserId: u.UserID, Username: u.Username, Role: u.Role})
+	}
+	return &pb.GetUsersByIDsResponse{
+		Code:  0,
+		Msg:   "success",
+		Users: profiles,
+	}, nil
+}
@@ -0,0 +1,103 @@
+// Package memory 存储接口的内存实现，用于单元测试及本地联调，不持久化
+package memory
+
+import (
+	"database/sql"
+	"sync"
+
+	"bluebell_microservices/common/pkg/rbac"
+	"bluebell_microservices/user-service/internal/dao/mysql"
+	"bluebell_microservices/user-service/internal/model"
+)
+
+// UserStore 用户的内存存储，返回的错误与 mysql.UserDAO 一致
+type UserStore struct {
+	mu    sync.RWMutex
+	users map[string]*model.User // username -> user
+}
+
+// NewUserStore 创建空的用户存储
+func NewUserStore() *UserStore {
+	return &UserStore{users: make(map[string]*model.User)}
+}
+
+// CheckUserExist 检查指定用户名的用户是否存在
+func (s *UserStore) CheckUserExist(username string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.users[username]; !ok {
+		return mysql.ErrUserNotExist
+	}
+	return nil
+}
+
+// Create 保存用户，密码明文保存
+func (s *UserStore) Create(user *model.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := *user
+	if u.Role == "" {
+		u.Role = rbac.RoleUser // 与 user 表 role 列默认值一致
+	}
+	s.users[user.Username] = &u
+	return nil
+}
+
+// Select 按用户名查询并校验密码
+func (s *UserStore) Select(user *model.User) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.users[user.Username]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if u.Password != user.Password {
+		return mysql.ErrInvalidPassword
+	}
+	user.UserID, user.Role = u.UserID, u.Role
+	return nil
+}
+
+// UpdateRole 修改用户角色
+func (s *UserStore) UpdateRole(userID uint64, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.UserID == userID {
+			u.Role = role
+			return nil
+		}
+	}
+	return mysql.ErrUserNotExist
+}
+
+// Get 按用户名返回用户副本，供测试断言
+func (s *UserStore) Get(username string) (*model.User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.users[username]
+	if !ok {
+		return nil, false
+	}
+	c := *u
+	return &c, true
+}
+
+// GetUsersByIDs 批量查询用户，不存在的用户被忽略
+func (s *UserStore) GetUsersByIDs(userIDs []uint64) ([]*model.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	want := make(map[uint64]bool, len(userIDs))
+	for _, id := range userIDs {
+		want[id] = true
+	}
+	var users []*model.User
+	for _, u := range s.users {
+		if want[u.UserID] {
+			c := *u
+			c.Password = ""
+			users = append(users, &c)
+		}
+	}
+	return users, nil
+}
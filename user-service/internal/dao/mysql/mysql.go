@@ -2,7 +2,9 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -60,3 +62,11 @@ func Close() {
 func DB() *sql.DB {
 	return db
 }
+
+// Ping 检查数据库连接是否可用，用于健康检查
+func Ping(ctx context.Context) error {
+	if db == nil {
+		return errors.New("mysql not initialized")
+	}
+	return db.PingContext(ctx)
+}
@@ -6,12 +6,19 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"errors"
+	"strings"
 
 	"bluebell_microservices/user-service/internal/model"
 )
 
 const secret = "huchao.vip"
 
+// 用户查询相关错误
+var (
+	ErrInvalidPassword = errors.New("密码错误")
+	ErrUserNotExist    = errors.New("用户不存在")
+)
+
 type UserDAO struct {
 	db *sql.DB
 }
@@ -38,7 +45,7 @@ func (d *UserDAO) CheckUserExist(username string) error {
 		return err
 	}
 	if count == 0 {
-		return errors.New("用户不存在")
+		return ErrUserNotExist
 	}
 	return nil // 用户存在时返回 nil
 }
@@ -56,15 +63,76 @@ func (d *UserDAO) Create(user *model.User) error {
 
 func (d *UserDAO) Select(user *model.User) error {
 	originPassword := user.Password
-	sqlStr := "select user_id, username, password from user where username = ?"
-	err := d.db.QueryRow(sqlStr, user.Username).Scan(&user.UserID, &user.Username, &user.Password)
+	sqlStr := "select user_id, username, password, role from user where username = ?"
+	err := d.db.QueryRow(sqlStr, user.Username).Scan(&user.UserID, &user.Username, &user.Password, &user.Role)
 	if err != nil {
 		return err
 	}
 
 	password := encryptPassword([]byte(originPassword))
 	if user.Password != password {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// UpdateRole 修改用户角色
+func (d *UserDAO) UpdateRole(userID uint64, role string) error {
+	sqlStr := `update user set role = ? where user_id = ?`
+	res, err := d.db.Exec(sqlStr, role, userID)
+	if err != nil {
 		return err
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// 角色未变化时也不会有行被修改，再确认一次用户是否存在
+		var count int
+		if err := d.db.QueryRow(`select count(user_id) from user where user_id = ?`, userID).Scan(&count); err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrUserNotExist
+		}
+	}
 	return nil
 }
+
+// UpdateRoleByUsername 按用户名修改用户角色
+func (d *UserDAO) UpdateRoleByUsername(username, role string) error {
+	var userID uint64
+	err := d.db.QueryRow(`select user_id from user where username = ?`, username).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return ErrUserNotExist
+	}
+	if err != nil {
+		return err
+	}
+	return d.UpdateRole(userID, role)
+}
+
+// GetUsersByIDs 批量查询用户，不查询密码
+func (d *UserDAO) GetUsersByIDs(userIDs []uint64) ([]*model.User, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(userIDs)), ",")
+	args := make([]interface{}, len(userIDs))
+	for i, id := range userIDs {
+		args[i] = id
+	}
+	sqlStr := "select user_id, username, role from user where user_id in (" + placeholders + ")"
+	rows, err := d.db.Query(sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	for rows.Next() {
+		u := new(model.User)
+		if err := rows.Scan(&u.UserID, &u.Username, &u.Role); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,17 @@
+package logic
+
+import "bluebell_microservices/user-service/internal/model"
+
+// UserStore 用户存储，mysql.UserDAO 为线上实现，memory.UserStore 为测试用的内存实现
+type UserStore interface {
+	// CheckUserExist 用户名不存在时返回 mysql.ErrUserNotExist
+	CheckUserExist(username string) error
+	// Create 保存用户，密码由存储层加密后写入 user.Password
+	Create(user *model.User) error
+	// Select 按用户名查询并校验 user.Password，密码错误时返回 mysql.ErrInvalidPassword
+	Select(user *model.User) error
+	// UpdateRole 修改用户角色，用户不存在时返回 mysql.ErrUserNotExist
+	UpdateRole(userID uint64, role string) error
+	// GetUsersByIDs 批量查询用户，不存在的用户被忽略，返回顺序不确定
+	GetUsersByIDs(userIDs []uint64) ([]*model.User, error)
+}
@@ -3,9 +3,12 @@ package logic
 
 import (
 	"context"
+	"fmt"
 
+	"bluebell_microservices/common/pkg/errcode"
 	"bluebell_microservices/common/pkg/jwt"
 	"bluebell_microservices/common/pkg/logger"
+	"bluebell_microservices/common/pkg/rbac"
 	"bluebell_microservices/common/pkg/snowflake" // 导入公共包
 	pb "bluebell_microservices/proto/user"
 	"bluebell_microservices/user-service/internal/dao/mysql"
@@ -16,33 +19,54 @@ import (
 	"go.uber.org/zap"
 )
 
+// 用户相关错误
+var (
+	ErrUserExist          = errcode.AlreadyExists("USER_EXISTS", "用户已存在")
+	ErrUserNotExist       = errcode.NotFound("USER_NOT_FOUND", "用户不存在")
+	ErrUserBanned         = errcode.PermissionDenied("USER_BANNED", "用户已被封禁")
+	ErrInvalidRole        = errcode.InvalidArgument("INVALID_ROLE", "无效的角色")
+	ErrInvalidCredentials = errcode.Unauthenticated("INVALID_CREDENTIALS", "用户名或密码错误")
+	ErrPasswordMismatch   = errcode.InvalidArgument("PASSWORD_MISMATCH", "两次密码不一致")
+	ErrInvalidToken       = errcode.Unauthenticated("INVALID_TOKEN", "无效的token")
+	ErrTooManyUserIDs     = errcode.InvalidArgument("TOO_MANY_USER_IDS", fmt.Sprintf("一次最多查询 %d 个用户", MaxBatchUsers))
+)
+
 type UserLogic struct {
-	userDao *mysql.UserDAO
+	userDao UserStore
 }
 
-func NewUserLogic() *UserLogic {
+// NewUserLogic 创建 UserLogic，线上传入 mysql.NewUserDAO()
+func NewUserLogic(userDao UserStore) *UserLogic {
 	return &UserLogic{
-		userDao: mysql.NewUserDAO(),
+		userDao: userDao,
 	}
 }
 
 func (l *UserLogic) SignUp(ctx context.Context, req *pb.SignUpRequest) error {
 
-	logger.Info("SignUp attempt", zap.String("username", req.Username))
+	logger.Ctx(ctx).Info("SignUp attempt", zap.String("username", req.Username))
+
+	if req.Password != req.ConfirmPassword {
+		return ErrPasswordMismatch
+	}
 
 	// 1、判断用户是否存在
 	err := l.userDao.CheckUserExist(req.Username)
 	if err == nil {
 		// 用户已存在，返回错误
-		logger.Warn("User already exists", zap.String("username", req.Username))
-		return errors.New("用户已存在")
+		logger.Ctx(ctx).Warn("User already exists", zap.String("username", req.Username))
+		return ErrUserExist
+	}
+	if !errors.Is(err, mysql.ErrUserNotExist) {
+		logger.Ctx(ctx).Error("Failed to check user exist", zap.String("username", req.Username), zap.Error(err))
+		return err
 	}
 
 	// 用户不存在，继续注册流程
 	// 2、生成UID
 	userId, err := snowflake.GetID()
 	if err != nil {
-		logger.Error("Failed to generate user ID", zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to generate user ID", zap.Error(err))
 		return err
 	}
 
@@ -57,22 +81,26 @@ func (l *UserLogic) SignUp(ctx context.Context, req *pb.SignUpRequest) error {
 	// 4、保存用户信息
 	err = l.userDao.Create(user)
 	if err != nil {
-		logger.Error("Failed to create user", zap.String("username", req.Username), zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to create user", zap.String("username", req.Username), zap.Error(err))
 		return err
 	}
 
-	logger.Info("SignUp successful", zap.String("username", req.Username), zap.Uint64("user_id", userId))
+	logger.Ctx(ctx).Info("SignUp successful", zap.String("username", req.Username), zap.Uint64("user_id", userId))
 	return nil
 }
 
 func (l *UserLogic) Login(ctx context.Context, req *pb.LoginRequest) (user *model.User, error error) {
 
-	logger.Info("Login attempt", zap.String("username", req.Username))
+	logger.Ctx(ctx).Info("Login attempt", zap.String("username", req.Username))
 
 	// 检查用户是否存在
 	err := l.userDao.CheckUserExist(req.Username)
+	if errors.Is(err, mysql.ErrUserNotExist) {
+		logger.Ctx(ctx).Warn("User does not exist", zap.String("username", req.Username))
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
-		logger.Warn("User does not exist", zap.String("username", req.Username), zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to check user exist", zap.String("username", req.Username), zap.Error(err))
 		return nil, err
 	}
 
@@ -83,45 +111,58 @@ func (l *UserLogic) Login(ctx context.Context, req *pb.LoginRequest) (user *mode
 	}
 
 	err = l.userDao.Select(user)
+	if errors.Is(err, mysql.ErrInvalidPassword) {
+		logger.Ctx(ctx).Warn("Invalid password", zap.String("username", req.Username))
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
-		logger.Error("Failed to select user", zap.String("username", req.Username), zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to select user", zap.String("username", req.Username), zap.Error(err))
 		return nil, err
 	}
 
+	// 被封禁的用户不允许登录
+	user.Role = rbac.Normalize(user.Role)
+	if user.Role == rbac.RoleBanned {
+		logger.Ctx(ctx).Warn("Banned user tried to login", zap.String("username", req.Username))
+		return nil, ErrUserBanned
+	}
+
 	// 生成JWT
-	accessToken, refreshToken, err := jwt.GenToken(user.UserID, user.Username)
+	accessToken, refreshToken, err := jwt.GenToken(user.UserID, user.Username, user.Role)
 	if err != nil {
-		logger.Error("Failed to generate token", zap.String("username", req.Username), zap.Error(err))
+		logger.Ctx(ctx).Error("Failed to generate token", zap.String("username", req.Username), zap.Error(err))
 		return nil, err
 	}
 
 	user.AccessToken = accessToken
 	user.RefreshToken = refreshToken
 
-	logger.Info("Login successful", zap.String("username", user.Username))
+	logger.Ctx(ctx).Info("Login successful", zap.String("username", user.Username))
 
 	return user, nil
 
 }
 
 func (l *UserLogic) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
-	traceID, _ := ctx.Value("trace_id").(string)
-	logger.Info("RefreshToken attempt",
-		zap.String("trace_id", traceID),
-		zap.String("access_token", req.AccessToken),
-		zap.String("refresh_token", req.RefreshToken))
-
-	// 调用 jwt 包的刷新逻辑
-	newAccessToken, newRefreshToken, err := jwt.RefreshToken(req.AccessToken, req.RefreshToken)
+	logger.Ctx(ctx).Info("RefreshToken attempt") // 令牌属于凭证，不写入日志
+
+	// 调用 jwt 包的刷新逻辑，新 token 使用当前角色，被封禁或已删除的用户不能刷新
+	var roleErr error
+	newAccessToken, newRefreshToken, err := jwt.RefreshToken(req.AccessToken, req.RefreshToken, func(userID uint64) (string, error) {
+		role, err := l.currentRole(userID)
+		roleErr = err
+		return role, err
+	})
+	if roleErr != nil {
+		logger.Ctx(ctx).Warn("Refused to refresh token", zap.Error(roleErr))
+		return nil, roleErr
+	}
 	if err != nil {
-		logger.Warn("Failed to refresh token", zap.Error(err))
-		return &pb.RefreshTokenResponse{
-			Code: 1,
-			Msg:  "刷新 token 失败: " + err.Error(),
-		}, nil
+		logger.Ctx(ctx).Warn("Failed to refresh token", zap.Error(err))
+		return nil, ErrInvalidToken.Wrap(err)
 	}
 
-	logger.Info("Token refreshed successfully")
+	logger.Ctx(ctx).Info("Token refreshed successfully")
 	return &pb.RefreshTokenResponse{
 		Code:         0,
 		Msg:          "刷新成功",
@@ -129,3 +170,71 @@ func (l *UserLogic) RefreshToken(ctx context.Context, req *pb.RefreshTokenReques
 		RefreshToken: newRefreshToken,
 	}, nil
 }
+
+// currentRole 查询用户当前角色，用户不存在时返回 ErrUserNotExist，被封禁时返回 ErrUserBanned
+func (l *UserLogic) currentRole(userID uint64) (string, error) {
+	users, err := l.userDao.GetUsersByIDs([]uint64{userID})
+	if err != nil {
+		return "", err
+	}
+	if len(users) == 0 {
+		return "", ErrUserNotExist
+	}
+	role := rbac.Normalize(users[0].Role)
+	if role == rbac.RoleBanned {
+		return "", ErrUserBanned
+	}
+	return role, nil
+}
+
+// SetUserRole 修改用户角色；BFF 按用户当前角色鉴权，新角色在角色缓存过期后生效，无需重新登录
+func (l *UserLogic) SetUserRole(ctx context.Context, req *pb.SetUserRoleRequest) error {
+	logger.Ctx(ctx).Info("SetUserRole attempt",
+		zap.Uint64("user_id", req.UserId),
+		zap.String("role", req.Role),
+		zap.Uint64("operator_id", req.OperatorId))
+
+	if !rbac.Valid(req.Role) {
+		return ErrInvalidRole
+	}
+
+	err := l.userDao.UpdateRole(req.UserId, req.Role)
+	if errors.Is(err, mysql.ErrUserNotExist) {
+		return ErrUserNotExist
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to update user role", zap.Uint64("user_id", req.UserId), zap.Error(err))
+		return err
+	}
+
+	logger.Ctx(ctx).Info("SetUserRole successful", zap.Uint64("user_id", req.UserId), zap.String("role", req.Role))
+	return nil
+}
+
+// MaxBatchUsers GetUsersByIDs 一次最多查询的用户数
+const MaxBatchUsers = 100
+
+// GetUsersByIDs 批量查询用户公开信息，重复的 ID 只查询一次，不存在的用户被忽略
+func (l *UserLogic) GetUsersByIDs(ctx context.Context, userIDs []uint64) ([]*model.User, error) {
+	seen := make(map[uint64]bool, len(userIDs))
+	ids := make([]uint64, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > MaxBatchUsers {
+		return nil, ErrTooManyUserIDs
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	users, err := l.userDao.GetUsersByIDs(ids)
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to get users by ids", zap.Int("count", len(ids)), zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,223 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"bluebell_microservices/common/pkg/jwt"
+	"bluebell_microservices/common/pkg/rbac"
+	"bluebell_microservices/common/pkg/snowflake"
+	pb "bluebell_microservices/proto/user"
+	"bluebell_microservices/user-service/internal/dao/memory"
+	"bluebell_microservices/user-service/internal/model"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+)
+
+func TestMain(m *testing.M) {
+	snowflake.Init(1)
+	jwt.Init("test-secret")
+	os.Exit(m.Run())
+}
+
+// newTestUserLogic 创建使用内存存储的 UserLogic，并预置用户 alice（密码 123456）
+func newTestUserLogic(t *testing.T) (*UserLogic, *memory.UserStore) {
+	t.Helper()
+	store := memory.NewUserStore()
+	if err := store.Create(&model.User{UserID: 1, Username: "alice", Password: "123456"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(&model.User{UserID: 2, Username: "mallory", Password: "123456", Role: rbac.RoleBanned}); err != nil {
+		t.Fatal(err)
+	}
+	return NewUserLogic(store), store
+}
+
+func TestUserLogic_SignUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *pb.SignUpRequest
+		wantErr error
+	}{
+		{
+			name: "ok",
+			req:  &pb.SignUpRequest{Username: "bob", Password: "pwd", ConfirmPassword: "pwd"},
+		},
+		{
+			name:    "password mismatch",
+			req:     &pb.SignUpRequest{Username: "bob", Password: "pwd", ConfirmPassword: "other"},
+			wantErr: ErrPasswordMismatch,
+		},
+		{
+			name:    "user exists",
+			req:     &pb.SignUpRequest{Username: "alice", Password: "pwd", ConfirmPassword: "pwd"},
+			wantErr: ErrUserExist,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, store := newTestUserLogic(t)
+			err := l.SignUp(context.Background(), tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SignUp() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			u, ok := store.Get(tt.req.Username)
+			if !ok {
+				t.Fatalf("user %q not saved", tt.req.Username)
+			}
+			if u.UserID == 0 {
+				t.Errorf("user id not generated")
+			}
+		})
+	}
+}
+
+func TestUserLogic_Login(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{name: "ok", username: "alice", password: "123456"},
+		{name: "unknown user", username: "nobody", password: "123456", wantErr: ErrInvalidCredentials},
+		{name: "wrong password", username: "alice", password: "bad", wantErr: ErrInvalidCredentials},
+		{name: "banned user", username: "mallory", password: "123456", wantErr: ErrUserBanned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestUserLogic(t)
+			user, err := l.Login(context.Background(), &pb.LoginRequest{Username: tt.username, Password: tt.password})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if user.UserID != 1 || user.Role != rbac.RoleUser {
+				t.Errorf("Login() user = %+v", user)
+			}
+			claims, err := jwt.ParseToken(user.AccessToken)
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			if claims.UserID != 1 {
+				t.Errorf("token user id = %d, want 1", claims.UserID)
+			}
+		})
+	}
+}
+
+func TestUserLogic_SetUserRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint64
+		role    string
+		wantErr error
+	}{
+		{name: "ok", userID: 1, role: rbac.RoleModerator},
+		{name: "invalid role", userID: 1, role: "root", wantErr: ErrInvalidRole},
+		{name: "unknown user", userID: 99, role: rbac.RoleAdmin, wantErr: ErrUserNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, store := newTestUserLogic(t)
+			err := l.SetUserRole(context.Background(), &pb.SetUserRoleRequest{UserId: tt.userID, Role: tt.role})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetUserRole() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if u, _ := store.Get("alice"); u.Role != tt.role {
+				t.Errorf("role = %q, want %q", u.Role, tt.role)
+			}
+		})
+	}
+}
+
+func TestUserLogic_RefreshToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   uint64
+		wantRole string
+		wantErr  error
+	}{
+		{name: "current role", userID: 1, wantRole: rbac.RoleUser},
+		{name: "banned", userID: 2, wantErr: ErrUserBanned},
+		{name: "unknown user", userID: 99, wantErr: ErrUserNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestUserLogic(t)
+			// 已过期的 Access Token 中是签发时的管理员角色
+			expired, err := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, jwt.MyClaims{
+				UserID: tt.userID,
+				Role:   rbac.RoleAdmin,
+				StandardClaims: jwtgo.StandardClaims{
+					ExpiresAt: time.Now().Add(-time.Minute).Unix(),
+					Issuer:    "bluebell-plus",
+				},
+			}).SignedString([]byte("test-secret"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, refresh, err := jwt.GenToken(tt.userID, "", rbac.RoleAdmin)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := l.RefreshToken(context.Background(), &pb.RefreshTokenRequest{AccessToken: expired, RefreshToken: refresh})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RefreshToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			claims, err := jwt.ParseToken(resp.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Role != tt.wantRole {
+				t.Errorf("refreshed role = %q, want %q", claims.Role, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestUserLogic_GetUsersByIDs(t *testing.T) {
+	tooMany := make([]uint64, MaxBatchUsers+1)
+	for i := range tooMany {
+		tooMany[i] = uint64(i + 1)
+	}
+	tests := []struct {
+		name    string
+		ids     []uint64
+		want    int
+		wantErr error
+	}{
+		{name: "ok", ids: []uint64{1, 2}, want: 2},
+		{name: "duplicates and zero", ids: []uint64{1, 1, 0}, want: 1},
+		{name: "unknown user ignored", ids: []uint64{1, 99}, want: 1},
+		{name: "empty", ids: nil, want: 0},
+		{name: "too many", ids: tooMany, wantErr: ErrTooManyUserIDs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestUserLogic(t)
+			users, err := l.GetUsersByIDs(context.Background(), tt.ids)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetUsersByIDs() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(users) != tt.want {
+				t.Errorf("GetUsersByIDs() returned %d users, want %d", len(users), tt.want)
+			}
+		})
+	}
+}
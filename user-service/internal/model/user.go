@@ -8,6 +8,7 @@ type User struct {
 	Password     string `db:"password"` // 密码
 	Email        string `db:"email"`    // 邮箱
 	Gender       string `db:"gender"`   // 性别
+	Role         string `db:"role"`     // 角色
 	AccessToken  string
 	RefreshToken string
 }
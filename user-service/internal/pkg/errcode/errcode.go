@@ -1,9 +0,0 @@
-// user-service/internal/pkg/errcode/errcode.go
-package errcode
-
-const (
-	ErrorUserExit      = "用户已存在"
-	ErrorUserNotExit   = "用户不存在"
-	ErrorPasswordWrong = "密码错误"
-	ErrorGenIDFailed   = "生成ID失败"
-)
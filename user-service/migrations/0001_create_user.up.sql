@@ -0,0 +1,14 @@
+-- 使用 IF NOT EXISTS，已由旧版 init.sql 建表的数据库可以直接标记为该版本
+CREATE TABLE IF NOT EXISTS `user` (
+    `id` bigint NOT NULL AUTO_INCREMENT,
+    `user_id` bigint NOT NULL,
+    `username` varchar(64) NOT NULL,
+    `password` varchar(64) NOT NULL,
+    `email` varchar(64) DEFAULT NULL,
+    `gender` tinyint NOT NULL DEFAULT 0,
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_username` (`username`),
+    UNIQUE KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
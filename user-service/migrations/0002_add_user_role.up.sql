@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN `role` varchar(16) NOT NULL DEFAULT 'user' COMMENT '角色：user、moderator、admin、banned' AFTER `gender`;